@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/config"
+	"backend/internal/migrations"
+	"backend/internal/recommend"
+	"backend/internal/storage"
+	"backend/internal/uploadgc"
+)
+
+// runCommand executes a one-shot maintenance command (e.g.
+// `./app migrate-accounts --dry-run`) instead of starting the HTTP server.
+func runCommand(db *mongo.Database, args []string) error {
+	switch args[0] {
+	case "migrate-accounts":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+		_ = fs.Parse(args[1:])
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		report, err := migrations.MigrateCustomersToUsers(ctx, db, *dryRun)
+		printReport(report)
+		return err
+	case "migrate-uploads":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "report what would be copied without writing")
+		_ = fs.Parse(args[1:])
+
+		target, err := storage.New(uploadStorageConfig())
+		if err != nil {
+			return err
+		}
+		source := storage.NewLocal(config.AppEnv.StorageRoot, "/public")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+
+		report, err := migrations.MigrateUploads(ctx, db, source, target, *dryRun)
+		printReport(report)
+		return err
+	case "backfill-sold-counts":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+		_ = fs.Parse(args[1:])
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		report, err := migrations.BackfillSoldCounts(ctx, db, *dryRun)
+		printReport(report)
+		return err
+	case "backfill-category-slugs":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+		_ = fs.Parse(args[1:])
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		report, err := migrations.BackfillCategorySlugs(ctx, db, *dryRun)
+		printReport(report)
+		return err
+	case "link-product-categories":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+		createMissing := fs.Bool("create-missing", false, "create a category for every name that matches none")
+		_ = fs.Parse(args[1:])
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		report, err := migrations.LinkProductCategories(ctx, db, *createMissing, *dryRun)
+		printReport(report)
+		return err
+	case "compute-recommendations":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "report what would be stored without writing")
+		halfLife := fs.Duration("half-life", config.AppEnv.RelatedHalfLife, "age at which an order counts half")
+		_ = fs.Parse(args[1:])
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		report, err := recommend.Compute(ctx, db, recommend.Options{HalfLife: *halfLife, DryRun: *dryRun})
+		printReport(report)
+		return err
+	case "gc-uploads":
+		fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "list orphaned files without deleting them")
+		grace := fs.Duration("grace", config.AppEnv.UploadGCGrace, "keep orphans modified within this period")
+		_ = fs.Parse(args[1:])
+
+		uploads, err := storage.New(uploadStorageConfig())
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		report, err := uploadgc.Collect(ctx, db, uploads, uploadgc.Options{GracePeriod: *grace, DryRun: *dryRun})
+		printReport(report)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func printReport(report interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(report)
+}
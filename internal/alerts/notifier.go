@@ -0,0 +1,102 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LowStockEvent is raised when a sale takes a product's stock from above its
+// reorder level to at or below it.
+type LowStockEvent struct {
+	ProductID    string    `json:"productId"`
+	Name         string    `json:"name"`
+	Barcode      string    `json:"barcode,omitempty"`
+	Stock        float64   `json:"stock"`
+	ReorderLevel float64   `json:"reorderLevel"`
+	OrderID      string    `json:"orderId,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+// Notifier delivers stock alerts to whoever restocks the shelves.
+type Notifier interface {
+	NotifyLowStock(ctx context.Context, event LowStockEvent) error
+}
+
+// LogNotifier only writes alerts to the application log. It is the default.
+type LogNotifier struct{}
+
+func (LogNotifier) NotifyLowStock(_ context.Context, event LowStockEvent) error {
+	log.Printf("[ALERT] [WARN] low stock product=%s name=%q stock=%v reorderLevel=%v", event.ProductID, event.Name, event.Stock, event.ReorderLevel)
+	return nil
+}
+
+// WebhookNotifier posts each alert as JSON to a URL, e.g. a chat webhook.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n WebhookNotifier) NotifyLowStock(ctx context.Context, event LowStockEvent) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":  "low_stock",
+		"event": event,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Multi sends each alert to every notifier and returns the first error.
+type Multi []Notifier
+
+func (m Multi) NotifyLowStock(ctx context.Context, event LowStockEvent) error {
+	var first error
+	for _, notifier := range m {
+		if err := notifier.NotifyLowStock(ctx, event); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// NewNotifier returns the notifier configured by ALERT_NOTIFIER. Alerts are
+// always logged; other providers are added on top of the log.
+func NewNotifier(provider, webhookURL string) (Notifier, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "webhook":
+		if strings.TrimSpace(webhookURL) == "" {
+			return nil, fmt.Errorf("ALERT_WEBHOOK_URL is required for the webhook notifier")
+		}
+		return Multi{LogNotifier{}, WebhookNotifier{URL: webhookURL}}, nil
+	default:
+		return nil, fmt.Errorf("unknown alert notifier %q", provider)
+	}
+}
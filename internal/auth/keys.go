@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is a single entry of the key set. Public-only keys are kept for
+// verification of tokens issued before a rotation and are never used to sign.
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+func (k *signingKey) canSign() bool {
+	return k.Private != nil
+}
+
+// LoadKeysDir reads every *.pem file in dir. The file name without extension
+// becomes the key id (kid), so keys are rotated by dropping a new file next to
+// the old ones and switching JWT_ACTIVE_KID.
+func LoadKeysDir(dir string) ([]*signingKey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*signingKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".pem") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		key, err := parsePEMKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func parsePEMKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKeyFromPrivate(kid, parsed)
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKeyFromPrivate(kid, parsed)
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKeyFromPublic(kid, parsed)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+func newSigningKeyFromPrivate(kid string, key interface{}) (*signingKey, error) {
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, Private: typed, Public: &typed.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodEdDSA, Private: typed, Public: typed.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+func newSigningKeyFromPublic(kid string, key interface{}) (*signingKey, error) {
+	switch typed := key.(type) {
+	case *rsa.PublicKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, Public: typed}, nil
+	case ed25519.PublicKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodEdDSA, Public: typed}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// legacyKeyID identifies the shared-secret key used when no asymmetric keys
+// are configured (local development).
+const legacyKeyID = "hs256"
+
+var (
+	ErrTokenInvalid = errors.New("invalid token")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// Options configures a TokenService.
+//
+// KeysDir holds RS256/EdDSA keys as <kid>.pem files; ActiveKID selects the key
+// used for signing (defaults to the last private key by name). LegacySecret is
+// the old HS256 JWT_SECRET: when asymmetric keys are configured it is only used
+// to accept tokens issued before the switch.
+//
+// Tokens without a kid are accepted only when LegacyCutoff is set: they must
+// be issued before it and are refused altogether once LegacyGrace (at most
+// one refresh token TTL) has passed after it.
+type Options struct {
+	KeysDir      string
+	ActiveKID    string
+	Issuer       string
+	Audience     string
+	LegacySecret string
+	LegacyCutoff time.Time
+	LegacyGrace  time.Duration
+}
+
+// TokenService signs and verifies access tokens for every service sharing the
+// same key set.
+type TokenService struct {
+	issuer       string
+	audience     string
+	active       *signingKey
+	keys         map[string]*signingKey
+	legacySecret []byte
+	legacyCutoff time.Time
+	legacyGrace  time.Duration
+	parser       *jwt.Parser
+	legacyParser *jwt.Parser
+	now          func() time.Time
+	// legacySeen holds the subjects already logged as using a legacy token,
+	// so each one is warned about once rather than on every request.
+	legacySeen sync.Map
+}
+
+func NewTokenService(opts Options) (*TokenService, error) {
+	var keys []*signingKey
+	if strings.TrimSpace(opts.KeysDir) != "" {
+		loaded, err := LoadKeysDir(opts.KeysDir)
+		if err != nil {
+			return nil, fmt.Errorf("load jwt keys: %w", err)
+		}
+		keys = loaded
+	}
+	return newTokenService(keys, opts)
+}
+
+func newTokenService(keys []*signingKey, opts Options) (*TokenService, error) {
+	s := &TokenService{
+		issuer:       opts.Issuer,
+		audience:     opts.Audience,
+		keys:         make(map[string]*signingKey, len(keys)),
+		legacySecret: []byte(opts.LegacySecret),
+		legacyCutoff: opts.LegacyCutoff,
+		legacyGrace:  opts.LegacyGrace,
+		now:          time.Now,
+	}
+
+	methods := map[string]struct{}{}
+	for _, key := range keys {
+		s.keys[key.ID] = key
+		methods[key.Method.Alg()] = struct{}{}
+		if !key.canSign() {
+			continue
+		}
+		if opts.ActiveKID == "" || opts.ActiveKID == key.ID {
+			s.active = key
+		}
+	}
+
+	if opts.ActiveKID != "" && (s.active == nil || s.active.ID != opts.ActiveKID) {
+		return nil, fmt.Errorf("active jwt key %q not found or has no private key", opts.ActiveKID)
+	}
+
+	if s.active == nil {
+		if len(s.legacySecret) == 0 {
+			return nil, errors.New("no jwt signing key configured")
+		}
+		s.active = &signingKey{ID: legacyKeyID, Method: jwt.SigningMethodHS256}
+		s.keys[legacyKeyID] = s.active
+		methods[jwt.SigningMethodHS256.Alg()] = struct{}{}
+	}
+
+	validMethods := make([]string, 0, len(methods))
+	for alg := range methods {
+		validMethods = append(validMethods, alg)
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if s.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(s.issuer))
+	}
+	if s.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(s.audience))
+	}
+	s.parser = jwt.NewParser(parserOpts...)
+	s.legacyParser = jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+
+	return s, nil
+}
+
+// ActiveKeyID returns the kid that new tokens are signed with.
+func (s *TokenService) ActiveKeyID() string {
+	return s.active.ID
+}
+
+// Sign adds the standard iss/aud/iat claims and signs with the active key.
+func (s *TokenService) Sign(claims jwt.MapClaims) (string, error) {
+	if s.issuer != "" {
+		claims["iss"] = s.issuer
+	}
+	if s.audience != "" {
+		claims["aud"] = s.audience
+	}
+	if _, ok := claims["iat"]; !ok {
+		claims["iat"] = s.now().Unix()
+	}
+
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+
+	if s.active.ID == legacyKeyID {
+		return token.SignedString(s.legacySecret)
+	}
+	return token.SignedString(s.active.Private)
+}
+
+// Parse verifies the signature, pinned algorithm, expiry, issuer and audience
+// of raw and returns its claims. Tokens without a kid are only accepted when a
+// legacy secret and cutoff are configured, to keep pre-rotation sessions
+// alive; see Options.
+func (s *TokenService) Parse(raw string) (jwt.MapClaims, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(raw, jwt.MapClaims{})
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+
+	kid, _ := unverified.Header["kid"].(string)
+	if kid == "" {
+		return s.parseLegacy(raw)
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := s.parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %s", t.Method.Alg(), key.ID)
+		}
+		if key.ID == legacyKeyID {
+			return s.legacySecret, nil
+		}
+		return key.Public, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+
+	return claims, nil
+}
+
+func (s *TokenService) parseLegacy(raw string) (jwt.MapClaims, error) {
+	if len(s.legacySecret) == 0 || s.legacyCutoff.IsZero() {
+		return nil, ErrUnknownKey
+	}
+	deadline := s.legacyCutoff.Add(s.legacyGrace)
+	if s.now().After(deadline) {
+		return nil, fmt.Errorf("%w: legacy tokens are no longer accepted", ErrTokenInvalid)
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := s.legacyParser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.legacySecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+	if err := s.checkLegacyClaims(claims, deadline); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		sub, _ = claims["userId"].(string)
+	}
+	if _, seen := s.legacySeen.LoadOrStore(sub, struct{}{}); !seen {
+		log.Printf("[AUTH] [WARN] accepted legacy HS256 token without kid: sub=%s", sub)
+	}
+	return claims, nil
+}
+
+// checkLegacyClaims applies the cutoff and, where the old token carries
+// them, the issuer and audience checks. Tokens from before the key service
+// have no iat; they must expire by the deadline instead.
+func (s *TokenService) checkLegacyClaims(claims jwt.MapClaims, deadline time.Time) error {
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil {
+		return err
+	}
+	if issuedAt != nil {
+		if !issuedAt.Before(s.legacyCutoff) {
+			return errors.New("issued after the legacy cutoff")
+		}
+	} else {
+		expiresAt, err := claims.GetExpirationTime()
+		if err != nil || expiresAt == nil || expiresAt.After(deadline) {
+			return errors.New("expires after the legacy grace period")
+		}
+	}
+
+	if _, ok := claims["iss"]; ok && s.issuer != "" {
+		if iss, _ := claims.GetIssuer(); iss != s.issuer {
+			return errors.New("unexpected issuer")
+		}
+	}
+	if _, ok := claims["aud"]; ok && s.audience != "" {
+		audience, _ := claims.GetAudience()
+		found := false
+		for _, aud := range audience {
+			found = found || aud == s.audience
+		}
+		if !found {
+			return errors.New("unexpected audience")
+		}
+	}
+	return nil
+}
+
+// JWK is the public part of a signing key in RFC 7517 form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKSet is served from /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists every asymmetric verification key, including retired ones that
+// may still have unexpired tokens in circulation. Shared secrets are never
+// published.
+func (s *TokenService) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, id := range s.sortedKeyIDs() {
+		key := s.keys[id]
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
+
+func (s *TokenService) sortedKeyIDs() []string {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
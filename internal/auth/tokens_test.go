@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"userId": "64b7f0c2a1b2c3d4e5f60718",
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestTokenServiceRotationKeepsOldTokensValid(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "2025-01", rsaKey)
+	writeKey(t, dir, "2026-01", edKey)
+
+	opts := Options{KeysDir: dir, ActiveKID: "2025-01", Issuer: "iss", Audience: "aud"}
+	oldService, err := NewTokenService(opts)
+	if err != nil {
+		t.Fatalf("NewTokenService: %v", err)
+	}
+	oldToken, err := oldService.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	opts.ActiveKID = ""
+	rotated, err := NewTokenService(opts)
+	if err != nil {
+		t.Fatalf("NewTokenService: %v", err)
+	}
+	if rotated.ActiveKeyID() != "2026-01" {
+		t.Fatalf("expected latest key to be active, got %s", rotated.ActiveKeyID())
+	}
+
+	if _, err := rotated.Parse(oldToken); err != nil {
+		t.Fatalf("token signed by previous key rejected: %v", err)
+	}
+
+	newToken, err := rotated.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	claims, err := rotated.Parse(newToken)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims["userId"] != "64b7f0c2a1b2c3d4e5f60718" {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+
+	if got := len(rotated.JWKS().Keys); got != 2 {
+		t.Fatalf("expected 2 published keys, got %d", got)
+	}
+}
+
+func TestTokenServiceRejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "k1", rsaKey)
+
+	service, err := NewTokenService(Options{KeysDir: dir, Issuer: "iss", Audience: "aud"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := testClaims()
+	claims["iss"] = "iss"
+	claims["aud"] = "aud"
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "k1"
+	raw, err := forged.SignedString(pubDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Parse(raw); err == nil {
+		t.Fatal("expected HS256 token using the RSA public key to be rejected")
+	}
+}
+
+func TestTokenServiceValidatesAudience(t *testing.T) {
+	service, err := newTokenService(nil, Options{Issuer: "iss", Audience: "aud", LegacySecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newTokenService(nil, Options{Issuer: "iss", Audience: "other", LegacySecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := other.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Parse(raw); err == nil {
+		t.Fatal("expected token for another audience to be rejected")
+	}
+}
+
+func TestTokenServiceSignUsesItsClock(t *testing.T) {
+	service, err := newTokenService(nil, Options{Issuer: "iss", Audience: "aud", LegacySecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := time.Now().Add(-time.Hour).Truncate(time.Second)
+	service.now = func() time.Time { return issued }
+
+	raw, err := service.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, claims); err != nil {
+		t.Fatal(err)
+	}
+	if iat, err := claims.GetIssuedAt(); err != nil || iat == nil || !iat.Equal(issued) {
+		t.Fatalf("expected iat %v, got %v (%v)", issued, iat, err)
+	}
+}
+
+func TestTokenServiceAcceptsLegacyTokensWithoutKid(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "k1", edKey)
+
+	service, err := NewTokenService(Options{
+		KeysDir: dir, Issuer: "iss", Audience: "aud",
+		LegacySecret: "secret", LegacyCutoff: time.Now(), LegacyGrace: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Parse(legacy); err != nil {
+		t.Fatalf("legacy token rejected: %v", err)
+	}
+
+	withoutSecret, err := NewTokenService(Options{KeysDir: dir, Issuer: "iss", Audience: "aud"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := withoutSecret.Parse(legacy); err == nil {
+		t.Fatal("expected legacy token to be rejected once JWT_SECRET is removed")
+	}
+	if got := len(service.JWKS().Keys); got != 1 {
+		t.Fatalf("expected only the asymmetric key to be published, got %d", got)
+	}
+}
+
+func TestTokenServiceLegacyTokensNeedCutoff(t *testing.T) {
+	cutoff := time.Now()
+	sign := func(extra jwt.MapClaims) string {
+		claims := testClaims()
+		for k, v := range extra {
+			claims[k] = v
+		}
+		raw, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	noCutoff, err := newTokenService(nil, Options{Issuer: "iss", Audience: "aud", LegacySecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noCutoff.Parse(sign(nil)); err == nil {
+		t.Fatal("expected legacy token to be rejected without a cutoff")
+	}
+
+	service, err := newTokenService(nil, Options{
+		Issuer: "iss", Audience: "aud",
+		LegacySecret: "secret", LegacyCutoff: cutoff, LegacyGrace: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Parse(sign(jwt.MapClaims{"iat": cutoff.Add(-time.Minute).Unix(), "aud": "aud"})); err != nil {
+		t.Fatalf("token issued before the cutoff rejected: %v", err)
+	}
+	if _, err := service.Parse(sign(jwt.MapClaims{"iat": cutoff.Add(time.Second).Unix()})); err == nil {
+		t.Fatal("expected token issued after the cutoff to be rejected")
+	}
+	if _, err := service.Parse(sign(jwt.MapClaims{"exp": cutoff.Add(2 * time.Hour).Unix()})); err == nil {
+		t.Fatal("expected token without iat outliving the grace period to be rejected")
+	}
+	if _, err := service.Parse(sign(jwt.MapClaims{"aud": "other"})); err == nil {
+		t.Fatal("expected legacy token for another audience to be rejected")
+	}
+
+	if _, seen := service.legacySeen.Load("64b7f0c2a1b2c3d4e5f60718"); !seen {
+		t.Fatal("expected the legacy subject to be remembered after its first warning")
+	}
+
+	service.now = func() time.Time { return cutoff.Add(61 * time.Minute) }
+	if _, err := service.Parse(sign(nil)); err == nil {
+		t.Fatal("expected legacy tokens to be rejected after the grace period")
+	}
+}
@@ -13,11 +13,35 @@ import (
 var AppEnv Config
 
 type Config struct {
+	Environment     string
 	MongoURI        string
 	DBName          string
 	JWTSecret       string
+	JWTKeysDir      string
+	JWTActiveKID    string
+	JWTIssuer       string
+	JWTAudience     string
+	JWTLegacyCutoff time.Time
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	SMSProvider     string
+	AlertNotifier   string
+	AlertWebhookURL string
+	StorageBackend  string
+	StorageRoot     string
+	S3Endpoint      string
+	S3Region        string
+	S3Bucket        string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3PublicURL     string
+	S3PathStyle     bool
+	UploadGCEnabled bool
+	UploadGCEvery   time.Duration
+	UploadGCGrace   time.Duration
+	SearchRefresh   time.Duration
+	RelatedEvery    time.Duration
+	RelatedHalfLife time.Duration
 }
 
 func Load() {
@@ -25,14 +49,47 @@ func Load() {
 		log.Println(".env not loaded:", err)
 	}
 	AppEnv = Config{
+		Environment:     strings.ToLower(getEnvOrDefault("APP_ENV", "production")),
 		MongoURI:        getEnvOrDefault("MONGO_URI", ""),
 		DBName:          getEnvOrDefault("DB_NAME", "heremarket"),
 		JWTSecret:       getEnvOrDefault("JWT_SECRET", ""),
+		JWTKeysDir:      getEnvOrDefault("JWT_KEYS_DIR", ""),
+		JWTActiveKID:    getEnvOrDefault("JWT_ACTIVE_KID", ""),
+		JWTIssuer:       getEnvOrDefault("JWT_ISSUER", "https://api.herevemarket.com"),
+		JWTAudience:     getEnvOrDefault("JWT_AUDIENCE", "herevemarket"),
+		JWTLegacyCutoff: getTimeEnv("JWT_LEGACY_CUTOFF"),
 		AccessTokenTTL:  getDurationEnv("ACCESS_TOKEN_TTL", 20, time.Minute),
 		RefreshTokenTTL: getDurationEnv("REFRESH_TOKEN_TTL", 7, 24*time.Hour),
+		SMSProvider:     getEnvOrDefault("SMS_PROVIDER", ""),
+		AlertNotifier:   getEnvOrDefault("ALERT_NOTIFIER", "log"),
+		AlertWebhookURL: getEnvOrDefault("ALERT_WEBHOOK_URL", ""),
+		StorageBackend:  getEnvOrDefault("STORAGE_BACKEND", "local"),
+		StorageRoot:     getEnvOrDefault("STORAGE_LOCAL_ROOT", "/app/public"),
+		S3Endpoint:      getEnvOrDefault("S3_ENDPOINT", ""),
+		S3Region:        getEnvOrDefault("S3_REGION", "us-east-1"),
+		S3Bucket:        getEnvOrDefault("S3_BUCKET", ""),
+		S3AccessKey:     getEnvOrDefault("S3_ACCESS_KEY", ""),
+		S3SecretKey:     getEnvOrDefault("S3_SECRET_KEY", ""),
+		S3PublicURL:     getEnvOrDefault("S3_PUBLIC_URL", ""),
+		S3PathStyle:     getEnvOrDefault("S3_PATH_STYLE", "true") == "true",
+		UploadGCEnabled: getEnvOrDefault("UPLOAD_GC_ENABLED", "true") == "true",
+		UploadGCEvery:   getDurationEnv("UPLOAD_GC_INTERVAL_HOURS", 24, time.Hour),
+		UploadGCGrace:   getDurationEnv("UPLOAD_GC_GRACE_HOURS", 24, time.Hour),
+		SearchRefresh:   getDurationEnv("SEARCH_REFRESH_SECONDS", 300, time.Second),
+		RelatedEvery:    getDurationEnv("RELATED_INTERVAL_HOURS", 24, time.Hour),
+		RelatedHalfLife: getDurationEnv("RELATED_HALF_LIFE_DAYS", 30, 24*time.Hour),
 	}
 }
 
+// IsDevelopment reports whether APP_ENV names a local development setup.
+func (c Config) IsDevelopment() bool {
+	switch c.Environment {
+	case "dev", "development", "local":
+		return true
+	}
+	return false
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
 		return value
@@ -48,3 +105,16 @@ func getDurationEnv(key string, defaultValue int, unit time.Duration) time.Durat
 	}
 	return time.Duration(defaultValue) * unit
 }
+
+func getTimeEnv(key string) time.Time {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("%s must be an RFC 3339 time, ignoring: %v", key, err)
+		return time.Time{}
+	}
+	return parsed
+}
@@ -36,6 +36,29 @@ func EnsureProductIndexes(db *mongo.Database) error {
 			}),
 	}
 
+	parentIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "parentId", Value: 1}},
+		Options: options.Index().
+			SetName("product_parent").
+			SetPartialFilterExpression(bson.M{"parentId": bson.M{"$exists": true}}),
+	}
+
+	categoryIDsIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "categoryIds", Value: 1}},
+		Options: options.Index().SetName("product_categoryIds"),
+	}
+
+	// Storefront sorts; keyset pages walk these instead of sorting in memory.
+	newestIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("product_newest"),
+	}
+
+	popularIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "soldCount", Value: -1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("product_popular"),
+	}
+
 	searchTextIndex := mongo.IndexModel{
 		Keys: bson.D{
 			{Key: "name", Value: "text"},
@@ -52,6 +75,34 @@ func EnsureProductIndexes(db *mongo.Database) error {
 	}
 	log.Println("EnsureProductIndexes: barcode_index created")
 
+	log.Println("EnsureProductIndexes: creating product_parent")
+	if _, err := indexes.CreateOne(ctx, parentIndex); err != nil {
+		log.Println("EnsureProductIndexes: parent index error:", err)
+		return err
+	}
+	log.Println("EnsureProductIndexes: product_parent created")
+
+	log.Println("EnsureProductIndexes: creating product_categoryIds")
+	if _, err := indexes.CreateOne(ctx, categoryIDsIndex); err != nil {
+		log.Println("EnsureProductIndexes: categoryIds index error:", err)
+		return err
+	}
+	log.Println("EnsureProductIndexes: product_categoryIds created")
+
+	log.Println("EnsureProductIndexes: creating product_newest")
+	if _, err := indexes.CreateOne(ctx, newestIndex); err != nil {
+		log.Println("EnsureProductIndexes: newest index error:", err)
+		return err
+	}
+	log.Println("EnsureProductIndexes: product_newest created")
+
+	log.Println("EnsureProductIndexes: creating product_popular")
+	if _, err := indexes.CreateOne(ctx, popularIndex); err != nil {
+		log.Println("EnsureProductIndexes: popular index error:", err)
+		return err
+	}
+	log.Println("EnsureProductIndexes: product_popular created")
+
 	log.Println("EnsureProductIndexes: creating product_search_text")
 	if _, err := indexes.CreateOne(ctx, searchTextIndex); err != nil {
 		log.Println("EnsureProductIndexes: search text index error:", err)
@@ -67,20 +118,95 @@ func EnsureUserIndexes(db *mongo.Database) error {
 
 	indexes := db.Collection("users").Indexes()
 
+	// Phone-only accounts have no email, so uniqueness must skip missing values.
+	for _, indexName := range []string{"email_unique"} {
+		_, err := indexes.DropOne(ctx, indexName)
+		if err != nil {
+			log.Printf("EnsureUserIndexes: warning dropping %s index: %v", indexName, err)
+		} else {
+			log.Printf("EnsureUserIndexes: dropped %s index", indexName)
+		}
+	}
+
 	emailIndex := mongo.IndexModel{
 		Keys: bson.D{{Key: "email", Value: 1}},
 		Options: options.Index().
-			SetName("email_unique").
-			SetUnique(true),
+			SetName("email_unique_sparse").
+			SetUnique(true).
+			SetSparse(true),
 	}
 
-	log.Println("EnsureUserIndexes: creating email_unique index")
+	phoneIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "phone", Value: 1}},
+		Options: options.Index().SetName("phone_index"),
+	}
+
+	log.Println("EnsureUserIndexes: creating email_unique_sparse index")
 	_, err := indexes.CreateOne(ctx, emailIndex)
 	if err != nil {
 		log.Println("EnsureUserIndexes: email index error:", err)
 		return err
 	}
-	log.Println("EnsureUserIndexes: email_unique index created")
+	log.Println("EnsureUserIndexes: email_unique_sparse index created")
+
+	log.Println("EnsureUserIndexes: creating phone_index index")
+	if _, err := indexes.CreateOne(ctx, phoneIndex); err != nil {
+		log.Println("EnsureUserIndexes: phone index error:", err)
+		return err
+	}
+	log.Println("EnsureUserIndexes: phone_index index created")
+
+	// One account per number for phone login; unverified profile numbers
+	// may repeat.
+	verifiedPhoneIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "phone", Value: 1}},
+		Options: options.Index().
+			SetName("phone_verified_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"phoneVerified": true}),
+	}
+
+	log.Println("EnsureUserIndexes: creating phone_verified_unique index")
+	if _, err := indexes.CreateOne(ctx, verifiedPhoneIndex); err != nil {
+		log.Println("EnsureUserIndexes: verified phone index error:", err)
+		return err
+	}
+	log.Println("EnsureUserIndexes: phone_verified_unique index created")
+	return nil
+}
+
+func EnsureOTPIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("otp_codes").Indexes()
+
+	phoneCreatedAtIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "phone", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("phone_createdAt_index"),
+	}
+
+	// Codes expire after minutes; keeping them a day is enough for throttling.
+	createdAtTTLIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "createdAt", Value: 1}},
+		Options: options.Index().
+			SetName("createdAt_ttl_index").
+			SetExpireAfterSeconds(24 * 60 * 60),
+	}
+
+	log.Println("EnsureOTPIndexes: creating phone_createdAt_index index")
+	if _, err := indexes.CreateOne(ctx, phoneCreatedAtIndex); err != nil {
+		log.Println("EnsureOTPIndexes: phone/createdAt index error:", err)
+		return err
+	}
+	log.Println("EnsureOTPIndexes: phone_createdAt_index index created")
+
+	log.Println("EnsureOTPIndexes: creating createdAt_ttl_index index")
+	if _, err := indexes.CreateOne(ctx, createdAtTTLIndex); err != nil {
+		log.Println("EnsureOTPIndexes: createdAt ttl index error:", err)
+		return err
+	}
+	log.Println("EnsureOTPIndexes: createdAt_ttl_index index created")
 	return nil
 }
 
@@ -105,6 +231,17 @@ func EnsureOrderIndexes(db *mongo.Database) error {
 		Options: options.Index().SetName("createdAt_desc_index"),
 	}
 
+	// cursor pagination: newest first with _id as the tie breaker
+	createdAtIDIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("createdAt_id_desc_index"),
+	}
+
+	userCreatedAtIDIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("userId_createdAt_id_index"),
+	}
+
 	log.Println("EnsureOrderIndexes: creating userId_index index")
 	_, err := indexes.CreateOne(ctx, userIDIndex)
 	if err != nil {
@@ -125,5 +262,132 @@ func EnsureOrderIndexes(db *mongo.Database) error {
 		return err
 	}
 	log.Println("EnsureOrderIndexes: createdAt_desc_index index created")
+
+	log.Println("EnsureOrderIndexes: creating createdAt_id_desc_index index")
+	if _, err := indexes.CreateOne(ctx, createdAtIDIndex); err != nil {
+		log.Println("EnsureOrderIndexes: createdAt/_id index error:", err)
+		return err
+	}
+	log.Println("EnsureOrderIndexes: createdAt_id_desc_index index created")
+
+	log.Println("EnsureOrderIndexes: creating userId_createdAt_id_index index")
+	if _, err := indexes.CreateOne(ctx, userCreatedAtIDIndex); err != nil {
+		log.Println("EnsureOrderIndexes: userId/createdAt/_id index error:", err)
+		return err
+	}
+	log.Println("EnsureOrderIndexes: userId_createdAt_id_index index created")
+	return nil
+}
+
+func EnsureStockMovementIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("stock_movements").Indexes()
+
+	productCreatedAtIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "productId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("productId_createdAt_index"),
+	}
+
+	orderIDIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "orderId", Value: 1}},
+		Options: options.Index().SetName("orderId_index").SetSparse(true),
+	}
+
+	log.Println("EnsureStockMovementIndexes: creating productId_createdAt_index index")
+	if _, err := indexes.CreateOne(ctx, productCreatedAtIndex); err != nil {
+		log.Println("EnsureStockMovementIndexes: productId/createdAt index error:", err)
+		return err
+	}
+	log.Println("EnsureStockMovementIndexes: productId_createdAt_index index created")
+
+	log.Println("EnsureStockMovementIndexes: creating orderId_index index")
+	if _, err := indexes.CreateOne(ctx, orderIDIndex); err != nil {
+		log.Println("EnsureStockMovementIndexes: orderId index error:", err)
+		return err
+	}
+	log.Println("EnsureStockMovementIndexes: orderId_index index created")
+	return nil
+}
+
+func EnsurePurchaseOrderIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	supplierNameIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName("name_unique_index").SetUnique(true),
+	}
+
+	log.Println("EnsurePurchaseOrderIndexes: creating suppliers name_unique_index index")
+	if _, err := db.Collection("suppliers").Indexes().CreateOne(ctx, supplierNameIndex); err != nil {
+		log.Println("EnsurePurchaseOrderIndexes: supplier name index error:", err)
+		return err
+	}
+	log.Println("EnsurePurchaseOrderIndexes: suppliers name_unique_index index created")
+
+	statusCreatedAtIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("status_createdAt_index"),
+	}
+
+	supplierCreatedAtIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "supplierId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("supplierId_createdAt_index"),
+	}
+
+	indexes := db.Collection("purchase_orders").Indexes()
+
+	log.Println("EnsurePurchaseOrderIndexes: creating status_createdAt_index index")
+	if _, err := indexes.CreateOne(ctx, statusCreatedAtIndex); err != nil {
+		log.Println("EnsurePurchaseOrderIndexes: status/createdAt index error:", err)
+		return err
+	}
+	log.Println("EnsurePurchaseOrderIndexes: status_createdAt_index index created")
+
+	log.Println("EnsurePurchaseOrderIndexes: creating supplierId_createdAt_index index")
+	if _, err := indexes.CreateOne(ctx, supplierCreatedAtIndex); err != nil {
+		log.Println("EnsurePurchaseOrderIndexes: supplierId/createdAt index error:", err)
+		return err
+	}
+	log.Println("EnsurePurchaseOrderIndexes: supplierId_createdAt_index index created")
+	return nil
+}
+
+func EnsureCategoryIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := db.Collection("categories").Indexes()
+
+	// partial: categories created before slugs existed have none until
+	// backfill-category-slugs runs
+	slugIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().
+			SetName("slug_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"slug": bson.M{"$type": "string"}}),
+	}
+
+	parentOrderIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "parentId", Value: 1}, {Key: "order", Value: 1}},
+		Options: options.Index().SetName("parentId_order_index"),
+	}
+
+	log.Println("EnsureCategoryIndexes: creating slug_unique index")
+	if _, err := indexes.CreateOne(ctx, slugIndex); err != nil {
+		log.Println("EnsureCategoryIndexes: slug index error:", err)
+		return err
+	}
+	log.Println("EnsureCategoryIndexes: slug_unique index created")
+
+	log.Println("EnsureCategoryIndexes: creating parentId_order_index index")
+	if _, err := indexes.CreateOne(ctx, parentOrderIndex); err != nil {
+		log.Println("EnsureCategoryIndexes: parentId/order index error:", err)
+		return err
+	}
+	log.Println("EnsureCategoryIndexes: parentId_order_index index created")
 	return nil
 }
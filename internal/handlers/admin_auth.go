@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 
+	"backend/internal/auth"
 	"backend/internal/models"
 )
 
@@ -19,7 +20,7 @@ type AdminLoginRequest struct {
 	Password string `json:"password"`
 }
 
-func AdminLogin(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
+func AdminLogin(db *mongo.Database, tokenService *auth.TokenService, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AdminLoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -33,17 +34,15 @@ func AdminLogin(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time
 			return
 		}
 
-		// 🔴 ESKİ: admins collection
-		// ✅ YENİ: customers + role=admin
-		var admin models.Customer
+		var admin models.User
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		err := db.Collection("customers").FindOne(
+		err := db.Collection("users").FindOne(
 			ctx,
 			bson.M{
 				"email": email,
-				"role":  "admin",
+				"role":  models.RoleAdmin,
 			},
 		).Decode(&admin)
 
@@ -52,7 +51,6 @@ func AdminLogin(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time
 			return
 		}
 
-		// 🔑 Şifre kontrolü (Customer’daki HASH ile)
 		if err := bcrypt.CompareHashAndPassword(
 			[]byte(admin.PasswordHash),
 			[]byte(req.Password),
@@ -61,7 +59,12 @@ func AdminLogin(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time
 			return
 		}
 
-		tokens, err := issueTokens(c, db, admin.ID, admin.Email, "admin", jwtSecret, accessTTL, refreshTTL)
+		if !admin.IsActive {
+			c.JSON(http.StatusForbidden, gin.H{"error": "user is inactive"})
+			return
+		}
+
+		tokens, err := issueTokens(c, db, admin.ID, admin.Email, models.RoleAdmin, tokenService, accessTTL, refreshTTL)
 		if err != nil {
 			return
 		}
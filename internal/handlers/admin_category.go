@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -13,18 +15,52 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/storage"
 )
 
 type CategoryCreateRequest struct {
 	Name     string `json:"name" binding:"required"`
+	Slug     string `json:"slug"`
+	ParentID string `json:"parentId"`
+	Order    int    `json:"order"`
 	IsActive *bool  `json:"isActive"`
 }
 
 type CategoryUpdateRequest struct {
-	Name     *string `json:"name"`
+	Name *string `json:"name"`
+	Slug *string `json:"slug"`
+	// ParentID "" moves the category to the top level.
+	ParentID *string `json:"parentId"`
+	Order    *int    `json:"order"`
 	IsActive *bool   `json:"isActive"`
 }
 
+// resolveCategoryParent parses a parentId for category id (zero when
+// creating); "" means top level.
+func resolveCategoryParent(ctx context.Context, db *mongo.Database, raw string, id primitive.ObjectID) (*primitive.ObjectID, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	parentID, err := primitive.ObjectIDFromHex(raw)
+	if err != nil {
+		return nil, errInvalidParentID
+	}
+	categories, err := loadCategories(ctx, db, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCategoryParent(categories, id, parentID); err != nil {
+		return nil, err
+	}
+	return &parentID, nil
+}
+
+func isCategoryParentError(err error) bool {
+	return errors.Is(err, errCategoryCycle) || errors.Is(err, errParentNotFound) || errors.Is(err, errInvalidParentID)
+}
+
 /*
 GET /admin/categories
 - Tüm kategoriler
@@ -65,6 +101,8 @@ func GetAllCategories(db *mongo.Database) gin.HandlerFunc {
 /*
 POST /admin/categories
 - Aynı isimli kategori eklenemez
+- parentId verilirse alt kategori olur; order kardeşler arasındaki sıradır
+- slug verilmezse isimden üretilir ("Süt Ürünleri" → "sut-urunleri"), çakışırsa -2, -3 eklenir
 */
 func CreateCategory(db *mongo.Database) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -99,8 +137,27 @@ func CreateCategory(db *mongo.Database) gin.HandlerFunc {
 			isActive = *req.IsActive
 		}
 
+		parentID, err := resolveCategoryParent(context.Background(), db, req.ParentID, primitive.NilObjectID)
+		if err != nil {
+			if isCategoryParentError(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		slug, err := uniqueCategorySlug(context.Background(), db, categorySlug(req.Slug, name), primitive.NilObjectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
 		category := models.Category{
 			Name:      name,
+			Slug:      slug,
+			ParentID:  parentID,
+			Order:     req.Order,
 			IsActive:  isActive,
 			CreatedAt: time.Now(),
 		}
@@ -120,8 +177,11 @@ func CreateCategory(db *mongo.Database) gin.HandlerFunc {
 
 /*
 PUT /admin/categories/:id
+- İsim değişince slug değişmez; yeni slug için "slug" gönderilir
+- Yeni isim bu kategorideki tüm ürünlere de yazılır
+- "parentId": "" kategoriyi en üste taşır; kendi altına taşınamaz
 */
-func UpdateCategory(db *mongo.Database) gin.HandlerFunc {
+func UpdateCategory(db *mongo.Database, productSearch *search.Index) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := primitive.ObjectIDFromHex(c.Param("id"))
 		if err != nil {
@@ -143,6 +203,19 @@ func UpdateCategory(db *mongo.Database) gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
 				return
 			}
+			// products store the name, so it has to stay unique
+			count, err := db.Collection("categories").CountDocuments(
+				context.Background(),
+				bson.M{"name": name, "_id": bson.M{"$ne": id}},
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+				return
+			}
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "category already exists"})
+				return
+			}
 			update["name"] = name
 		}
 
@@ -150,22 +223,79 @@ func UpdateCategory(db *mongo.Database) gin.HandlerFunc {
 			update["isActive"] = *req.IsActive
 		}
 
-		if len(update) == 0 {
+		if req.Order != nil {
+			update["order"] = *req.Order
+		}
+
+		unset := bson.M{}
+		if req.ParentID != nil {
+			parentID, err := resolveCategoryParent(context.Background(), db, *req.ParentID, id)
+			if err != nil {
+				if isCategoryParentError(err) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+				return
+			}
+			if parentID == nil {
+				unset["parentId"] = ""
+			} else {
+				update["parentId"] = *parentID
+			}
+		}
+
+		if req.Slug != nil {
+			base := search.Slug(*req.Slug)
+			if base == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid slug"})
+				return
+			}
+			slug, err := uniqueCategorySlug(context.Background(), db, base, id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+				return
+			}
+			update["slug"] = slug
+		}
+
+		if len(update) == 0 && len(unset) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
 			return
 		}
 
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		session, err := db.Client().StartSession()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		defer session.EndSession(ctx)
+
 		var updated models.Category
-		err = db.Collection("categories").
-			FindOneAndUpdate(
-				context.Background(),
+		var renamedProducts int64
+		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			var previous models.Category
+			err := db.Collection("categories").FindOneAndUpdate(
+				sessCtx,
 				bson.M{"_id": id},
-				bson.M{"$set": update},
-				options.FindOneAndUpdate().SetReturnDocument(options.After),
-			).
-			Decode(&updated)
+				categoryUpdate(update, unset),
+			).Decode(&previous)
+			if err != nil {
+				return nil, err
+			}
 
-		if err == mongo.ErrNoDocuments {
+			if name, ok := update["name"].(string); ok && name != previous.Name {
+				if renamedProducts, err = renameCategoryInProducts(sessCtx, db, id, previous.Name, name); err != nil {
+					return nil, err
+				}
+			}
+			return nil, db.Collection("categories").FindOne(sessCtx, bson.M{"_id": id}).Decode(&updated)
+		})
+
+		if errors.Is(err, mongo.ErrNoDocuments) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
 			return
 		}
@@ -174,36 +304,263 @@ func UpdateCategory(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		if renamedProducts > 0 {
+			log.Printf("[CATEGORY] renamed %s in %d products", id.Hex(), renamedProducts)
+			productSearch.Invalidate()
+		}
 		c.JSON(http.StatusOK, updated)
 	}
 }
 
+func categoryUpdate(set, unset bson.M) bson.M {
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update
+}
+
 /*
-DELETE /admin/categories/:id
-- Soft delete
+DELETE /admin/categories/:id?mode=block|reassign|cascade
+- block (varsayılan): ürünü ya da alt kategorisi olan kategori silinmez, 409 ile kullanım sayıları döner
+- reassign&target=<id>: ürünler ve alt kategoriler hedef kategoriye taşınır
+- cascade: alt kategoriler de silinir; bu kategoriler ürünlerden çıkarılır, başka kategorisi kalmayan ürünler silinir
+- Kategori kaydı ve görseli kalıcı olarak silinir; gizlemek için isActive=false kullanılır
 */
-func DeleteCategory(db *mongo.Database) gin.HandlerFunc {
+func DeleteCategory(db *mongo.Database, uploads storage.Storage, productSearch *search.Index) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		const route = "DELETE /admin/api/categories/:id"
+
 		id, err := primitive.ObjectIDFromHex(c.Param("id"))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
 			return
 		}
 
-		result, err := db.Collection("categories").UpdateOne(
-			context.Background(),
+		mode := strings.ToLower(strings.TrimSpace(c.DefaultQuery("mode", categoryDeleteBlock)))
+		if mode != categoryDeleteBlock && mode != categoryDeleteReassign && mode != categoryDeleteCascade {
+			respondWithError(c, http.StatusBadRequest, route, "mode must be block, reassign or cascade")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Minute)
+		defer cancel()
+
+		all, err := loadCategories(ctx, db, bson.M{})
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		subtree := categoryDescendants(all, []primitive.ObjectID{id})
+		if len(subtree) == 0 {
+			respondWithError(c, http.StatusNotFound, route, "category not found")
+			return
+		}
+		category := subtree[0]
+
+		var target models.Category
+		if mode == categoryDeleteReassign {
+			targetID, err := primitive.ObjectIDFromHex(strings.TrimSpace(c.Query("target")))
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, "target category required")
+				return
+			}
+			// the target must not be deleted with the category
+			if err := checkCategoryParent(all, id, targetID); err != nil {
+				if errors.Is(err, errParentNotFound) {
+					respondWithError(c, http.StatusBadRequest, route, "target category not found")
+					return
+				}
+				respondWithError(c, http.StatusBadRequest, route, "target cannot be the category or one of its subcategories")
+				return
+			}
+			for _, candidate := range all {
+				if candidate.ID == targetID {
+					target = candidate
+				}
+			}
+		}
+
+		report := categoryDeleteReport{Mode: mode, DeletedCategories: []string{}}
+		deleted := []models.Category{category}
+		if mode == categoryDeleteCascade {
+			deleted = subtree
+		}
+
+		session, err := db.Client().StartSession()
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer session.EndSession(ctx)
+
+		var orphans []models.Product
+		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			switch mode {
+			case categoryDeleteBlock:
+				products, err := db.Collection("products").CountDocuments(sessCtx,
+					bson.M{"categoryIds": id, "isDeleted": bson.M{"$ne": true}})
+				if err != nil {
+					return nil, err
+				}
+				if products > 0 || len(subtree) > 1 {
+					report.Products = products
+					report.Subcategories = countChildren(all, id)
+					return nil, errCategoryInUse
+				}
+			case categoryDeleteReassign:
+				moved, err := reassignCategory(sessCtx, db, category, target)
+				if err != nil {
+					return nil, err
+				}
+				report.ProductsReassigned = moved
+			case categoryDeleteCascade:
+				updated, removed, err := cascadeCategories(sessCtx, db, subtree, time.Now())
+				if err != nil {
+					return nil, err
+				}
+				report.ProductsUpdated = updated
+				report.ProductsDeleted = len(removed)
+				orphans = removed
+			}
+
+			ids := make([]primitive.ObjectID, len(deleted))
+			for i, category := range deleted {
+				ids[i] = category.ID
+			}
+			_, err := db.Collection("categories").DeleteMany(sessCtx, bson.M{"_id": bson.M{"$in": ids}})
+			return nil, err
+		})
+		if errors.Is(err, errCategoryInUse) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":         "category in use; delete with mode=reassign or mode=cascade",
+				"products":      report.Products,
+				"subcategories": report.Subcategories,
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("[%s] delete failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		cleanUpDeletedProducts(ctx, db, uploads, orphans)
+		for _, category := range deleted {
+			report.DeletedCategories = append(report.DeletedCategories, category.ID.Hex())
+			if category.Image != nil {
+				deleteProductImageFiles(ctx, uploads, []models.ProductImage{*category.Image})
+			}
+		}
+		productSearch.Invalidate()
+
+		log.Printf("[CATEGORY] deleted %s mode=%s categories=%d", id.Hex(), mode, len(deleted))
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func countChildren(categories []models.Category, id primitive.ObjectID) int {
+	n := 0
+	for _, category := range categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			n++
+		}
+	}
+	return n
+}
+
+/*
+PUT /admin/categories/:id/image
+- multipart "image"; ürün görselleri gibi küçük/orta/büyük boyutlarda saklanır
+- Önceki görselin dosyaları silinir
+*/
+func SetCategoryImage(db *mongo.Database, uploads storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "PUT /admin/api/categories/:id/image"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		file, err := c.FormFile("image")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "image required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		var category models.Category
+		if err := db.Collection("categories").FindOne(ctx, bson.M{"_id": id}).Decode(&category); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				respondWithError(c, http.StatusNotFound, route, "category not found")
+				return
+			}
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		image, err := saveImageRenditions(ctx, uploads, "categories", file)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		if _, err := db.Collection("categories").UpdateOne(ctx,
 			bson.M{"_id": id},
-			bson.M{"$set": bson.M{"isActive": false}},
-		)
+			bson.M{"$set": bson.M{"image": image}},
+		); err != nil {
+			deleteProductImageFiles(ctx, uploads, []models.ProductImage{image})
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if category.Image != nil {
+			deleteProductImageFiles(ctx, uploads, []models.ProductImage{*category.Image})
+		}
+
+		category.Image = &image
+		c.JSON(http.StatusOK, category)
+	}
+}
+
+/*
+DELETE /admin/categories/:id/image
+*/
+func DeleteCategoryImage(db *mongo.Database, uploads storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /admin/api/categories/:id/image"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
 			return
 		}
 
-		if result.MatchedCount == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var previous models.Category
+		err = db.Collection("categories").FindOneAndUpdate(ctx,
+			bson.M{"_id": id},
+			bson.M{"$unset": bson.M{"image": ""}},
+		).Decode(&previous)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "category not found")
 			return
 		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if previous.Image != nil {
+			deleteProductImageFiles(ctx, uploads, []models.ProductImage{*previous.Image})
+		}
 
 		c.Status(http.StatusNoContent)
 	}
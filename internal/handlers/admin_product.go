@@ -19,6 +19,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/storage"
 )
 
 /* =======================
@@ -29,50 +31,62 @@ type ProductUpdateRequest struct {
 	// Pointer alanlar sayesinde:
 	// - nil => field istemciden hiç gelmedi (dokunma)
 	// - 0 / false => istemci açıkça bu değeri gönderdi (güncelle)
-	Name        *string   `json:"name" form:"name"`
-	Price       *float64  `json:"price" form:"price"`
-	SaleEnabled *bool     `json:"saleEnabled" form:"saleEnabled"`
-	SalePrice   *float64  `json:"salePrice" form:"salePrice"`
-	CategoryIDs *[]string `json:"category_id" form:"category_id"`
-	Description *string   `json:"description" form:"description"`
-	Barcode     *string   `json:"barcode" form:"barcode"`
-	Brand       *string   `json:"brand" form:"brand"`
-	Stock       *int      `json:"stock" form:"stock"`
-	InStock     *bool     `json:"inStock" form:"inStock"`
-	IsActive    *bool     `json:"isActive" form:"isActive"`
-	IsCampaign  *bool     `json:"isCampaign" form:"isCampaign"`
+	Name         *string   `json:"name" form:"name"`
+	Price        *float64  `json:"price" form:"price"`
+	SaleEnabled  *bool     `json:"saleEnabled" form:"saleEnabled"`
+	SalePrice    *float64  `json:"salePrice" form:"salePrice"`
+	CostPrice    *float64  `json:"costPrice" form:"costPrice"`
+	CategoryIDs  *[]string `json:"category_id" form:"category_id"`
+	Description  *string   `json:"description" form:"description"`
+	Barcode      *string   `json:"barcode" form:"barcode"`
+	Brand        *string   `json:"brand" form:"brand"`
+	Stock        *float64  `json:"stock" form:"stock"`
+	ReorderLevel *float64  `json:"reorderLevel" form:"reorderLevel"`
+	Unit         *string   `json:"unit" form:"unit"`
+	VariantLabel *string   `json:"variantLabel" form:"variantLabel"`
+	InStock      *bool     `json:"inStock" form:"inStock"`
+	IsActive     *bool     `json:"isActive" form:"isActive"`
+	IsCampaign   *bool     `json:"isCampaign" form:"isCampaign"`
 }
 
 /* =======================
    HELPERS
 ======================= */
 
-func normalizeCategories(values []string) []string {
-	seen := map[string]struct{}{}
-	out := make([]string, 0)
+// categoryRefs are a product's categories in order: IDs are the reference,
+// Names the copy stored in "category".
+type categoryRefs struct {
+	IDs   []primitive.ObjectID
+	Names []string
+}
 
-	for _, v := range values {
-		name := strings.TrimSpace(v)
-		if name == "" {
-			continue
-		}
-		if _, ok := seen[name]; ok {
+func refsOf(categories []models.Category) categoryRefs {
+	refs := categoryRefs{IDs: make([]primitive.ObjectID, 0, len(categories)), Names: make([]string, 0, len(categories))}
+	seen := map[primitive.ObjectID]bool{}
+	for _, category := range categories {
+		if seen[category.ID] {
 			continue
 		}
-		seen[name] = struct{}{}
-		out = append(out, name)
+		seen[category.ID] = true
+		refs.IDs = append(refs.IDs, category.ID)
+		refs.Names = append(refs.Names, category.Name)
 	}
-	return out
+	return refs
 }
 
-func resolveCategoryNamesByIDs(ctx context.Context, db *mongo.Database, ids []string) ([]string, error) {
+// setOn writes both fields into an update's $set.
+func (r categoryRefs) setOn(set bson.M) {
+	set["categoryIds"] = r.IDs
+	set["category"] = models.StringList(r.Names)
+}
+
+func resolveCategoriesByIDs(ctx context.Context, db *mongo.Database, ids []string) (categoryRefs, error) {
 	if len(ids) == 0 {
-		return nil, fmt.Errorf("category_id required")
+		return categoryRefs{}, fmt.Errorf("category_id required")
 	}
 
 	seen := map[primitive.ObjectID]struct{}{}
 	ordered := make([]primitive.ObjectID, 0, len(ids))
-	unique := make([]primitive.ObjectID, 0, len(ids))
 
 	for _, raw := range ids {
 		value := strings.TrimSpace(raw)
@@ -81,45 +95,44 @@ func resolveCategoryNamesByIDs(ctx context.Context, db *mongo.Database, ids []st
 		}
 		objectID, err := primitive.ObjectIDFromHex(value)
 		if err != nil {
-			return nil, fmt.Errorf("invalid category_id: %s", value)
+			return categoryRefs{}, fmt.Errorf("invalid category_id: %s", value)
 		}
 		if _, ok := seen[objectID]; ok {
 			continue
 		}
 		seen[objectID] = struct{}{}
 		ordered = append(ordered, objectID)
-		unique = append(unique, objectID)
 	}
 
-	if len(unique) == 0 {
-		return nil, fmt.Errorf("category_id required")
+	if len(ordered) == 0 {
+		return categoryRefs{}, fmt.Errorf("category_id required")
 	}
 
-	cursor, err := db.Collection("categories").Find(ctx, bson.M{"_id": bson.M{"$in": unique}})
+	cursor, err := db.Collection("categories").Find(ctx, bson.M{"_id": bson.M{"$in": ordered}})
 	if err != nil {
-		return nil, err
+		return categoryRefs{}, err
 	}
 
 	var categories []models.Category
 	if err := cursor.All(ctx, &categories); err != nil {
-		return nil, err
+		return categoryRefs{}, err
 	}
 
-	nameByID := make(map[primitive.ObjectID]string, len(categories))
+	byID := make(map[primitive.ObjectID]models.Category, len(categories))
 	for _, category := range categories {
-		nameByID[category.ID] = category.Name
+		byID[category.ID] = category
 	}
 
-	names := make([]string, 0, len(ordered))
+	resolved := make([]models.Category, 0, len(ordered))
 	for _, objectID := range ordered {
-		name, ok := nameByID[objectID]
+		category, ok := byID[objectID]
 		if !ok {
-			return nil, fmt.Errorf("category not found: %s", objectID.Hex())
+			return categoryRefs{}, fmt.Errorf("category not found: %s", objectID.Hex())
 		}
-		names = append(names, name)
+		resolved = append(resolved, category)
 	}
 
-	return names, nil
+	return refsOf(resolved), nil
 }
 
 func sanitizeLogValue(value string, max int) string {
@@ -156,29 +169,46 @@ func GetAllProducts(db *mongo.Database) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-
-		filter := bson.M{
-			"isDeleted": bson.M{"$ne": true},
+		cursorMode, after, err := parseCursorParam(c.Request.URL.Query(), "")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		if category := strings.TrimSpace(c.Query("category")); category != "" {
-			filter["category"] = bson.M{"$in": []string{category}}
-		}
+		filter := buildAdminProductsFilter(c)
 
-		if search := strings.TrimSpace(c.Query("search")); search != "" {
-			filter["$or"] = []bson.M{
-				{"name": bson.M{"$regex": search, "$options": "i"}},
-				{"brand": bson.M{"$regex": search, "$options": "i"}},
-				{"description": bson.M{"$regex": search, "$options": "i"}},
-				{"barcode": bson.M{"$regex": search, "$options": "i"}},
+		ctx := context.Background()
+
+		if cursorMode {
+			filter, err = withCursor(filter, newestFirst, after)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
 			}
-		}
 
-		if isActive := strings.TrimSpace(c.Query("isActive")); isActive != "" {
-			filter["isActive"] = strings.EqualFold(isActive, "true")
-		}
+			cursor, err := db.Collection("products").Find(ctx, filter,
+				options.Find().SetSort(newestFirst).SetLimit(limit+1))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+				return
+			}
+			defer cursor.Close(ctx)
 
-		ctx := context.Background()
+			products, err := decodeProducts(ctx, cursor)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+				return
+			}
+			products, next := trimCursorPage(products, limit, "", func(p models.Product) (bson.A, primitive.ObjectID) {
+				return bson.A{p.CreatedAt}, p.ID
+			})
+
+			c.JSON(http.StatusOK, gin.H{
+				"data":       products,
+				"pagination": cursorPagination(limit, next),
+			})
+			return
+		}
 
 		total, err := db.Collection("products").CountDocuments(ctx, filter)
 		if err != nil {
@@ -193,7 +223,7 @@ func GetAllProducts(db *mongo.Database) gin.HandlerFunc {
 		opts := options.Find().
 			SetSkip((page - 1) * limit).
 			SetLimit(limit).
-			SetSort(bson.D{{Key: "createdAt", Value: -1}})
+			SetSort(newestFirst)
 
 		cursor, err := db.Collection("products").Find(ctx, filter, opts)
 		if err != nil {
@@ -220,6 +250,37 @@ func GetAllProducts(db *mongo.Database) gin.HandlerFunc {
 	}
 }
 
+// buildAdminProductsFilter reads the admin product list filters (category
+// ID or name, search, isActive) shared by the list and the export.
+func buildAdminProductsFilter(c *gin.Context) bson.M {
+	filter := bson.M{
+		"isDeleted": bson.M{"$ne": true},
+	}
+
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		if id, err := primitive.ObjectIDFromHex(category); err == nil {
+			filter["categoryIds"] = id
+		} else {
+			filter["category"] = bson.M{"$in": []string{category}}
+		}
+	}
+
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		filter["$or"] = []bson.M{
+			{"name": bson.M{"$regex": search, "$options": "i"}},
+			{"brand": bson.M{"$regex": search, "$options": "i"}},
+			{"description": bson.M{"$regex": search, "$options": "i"}},
+			{"barcode": bson.M{"$regex": search, "$options": "i"}},
+		}
+	}
+
+	if isActive := strings.TrimSpace(c.Query("isActive")); isActive != "" {
+		filter["isActive"] = strings.EqualFold(isActive, "true")
+	}
+
+	return filter
+}
+
 func GetProductByID(db *mongo.Database) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -251,7 +312,13 @@ func GetProductByID(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, product)
+		products := []models.Product{product}
+		if err := attachVariants(context.Background(), db, products, false); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, products[0])
 	}
 }
 
@@ -259,7 +326,7 @@ func GetProductByID(db *mongo.Database) gin.HandlerFunc {
    CREATE
 ======================= */
 
-func CreateProduct(db *mongo.Database) gin.HandlerFunc {
+func CreateProduct(db *mongo.Database, uploads storage.Storage, productSearch *search.Index) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		log.Println("CreateProduct: request received")
@@ -271,7 +338,7 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		input, err := parseMultipartProductRequest(c)
+		input, err := parseMultipartProductRequest(c, uploads)
 		if err != nil {
 			log.Println("CreateProduct multipart error:", err)
 			respondMultipartError(c, err)
@@ -308,12 +375,11 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		categoryNames, err := resolveCategoryNamesByIDs(context.Background(), db, input.CategoryIDs)
+		categories, err := resolveCategoriesByIDs(context.Background(), db, input.CategoryIDs)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		categories := normalizeCategories(categoryNames)
 
 		if !input.StockSet {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "stock required"})
@@ -325,10 +391,35 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		if !input.ImageSet || strings.TrimSpace(input.ImagePath) == "" {
+		unit := models.UnitPiece
+		if input.UnitSet {
+			unit = input.Unit
+		}
+		if err := validateQuantity(unit, input.Stock); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "stock: " + err.Error()})
+			return
+		}
+
+		if input.ReorderLevel < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reorderLevel must be zero or greater"})
+			return
+		}
+
+		if input.CostPrice < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "costPrice must be zero or greater"})
+			return
+		}
+
+		if !input.ImageSet {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "image required"})
 			return
 		}
+		images, _ := mergeUploadedImages(nil, input.CoverImage, input.Images)
+		if len(images) > maxProductImages {
+			deleteProductImageFiles(context.Background(), uploads, images)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a product can have at most %d images", maxProductImages)})
+			return
+		}
 
 		isActive := true
 		if input.IsActiveSet {
@@ -346,26 +437,48 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 		description := strings.TrimSpace(input.Description)
 
 		product := models.Product{
-			Name:        name,
-			Price:       input.Price,
-			SaleEnabled: saleEnabled,
-			SalePrice:   salePrice,
-			IsOnSale:    isProductOnSale(input.Price, saleEnabled, salePrice),
-			Category:    models.StringList(categories),
-			Description: description,
-			Barcode:     barcode,
-			Brand:       brand,
-			ImagePath:   input.ImagePath,
-			Stock:       input.Stock,
-			InStock:     input.Stock > 0,
-			IsActive:    isActive,
-			IsCampaign:  isCampaign,
-			IsDeleted:   false,
-			CreatedAt:   now,
-		}
-
+			Name:         name,
+			Price:        input.Price,
+			SaleEnabled:  saleEnabled,
+			SalePrice:    salePrice,
+			CostPrice:    roundMoney(input.CostPrice),
+			IsOnSale:     isProductOnSale(input.Price, saleEnabled, salePrice),
+			Category:     models.StringList(categories.Names),
+			CategoryIDs:  categories.IDs,
+			Description:  description,
+			Barcode:      barcode,
+			Brand:        brand,
+			ImagePath:    coverImagePath(images),
+			Images:       images,
+			Unit:         unit,
+			Stock:        input.Stock,
+			InStock:      input.Stock > 0,
+			ReorderLevel: input.ReorderLevel,
+			VariantLabel: input.VariantLabel,
+			IsActive:     isActive,
+			IsCampaign:   isCampaign,
+			IsDeleted:    false,
+			CreatedAt:    now,
+		}
+
+		product.ID = primitive.NewObjectID()
 		log.Printf("CreateProduct inserting product: %+v", product)
-		res, err := db.Collection("products").InsertOne(context.Background(), product)
+		err = runInTransaction(context.Background(), db, func(sessCtx mongo.SessionContext) error {
+			if _, err := db.Collection("products").InsertOne(sessCtx, product); err != nil {
+				return err
+			}
+			if product.Stock == 0 {
+				return nil
+			}
+			return recordStockMovement(sessCtx, db, &models.StockMovement{
+				ProductID: product.ID,
+				Type:      models.StockMovementAdjustment,
+				Quantity:  product.Stock,
+				After:     product.Stock,
+				Reason:    "ilk stok",
+				Actor:     stockActorFromContext(c),
+			})
+		})
 		if err != nil {
 			log.Println("CreateProduct insert error:", err)
 			log.Println("CreateProduct RETURN 500:", err)
@@ -373,8 +486,8 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		product.ID = res.InsertedID.(primitive.ObjectID)
-		log.Println("CreateProduct insert success:", res.InsertedID)
+		log.Println("CreateProduct insert success:", product.ID.Hex())
+		productSearch.Invalidate()
 		c.JSON(http.StatusCreated, product)
 	}
 }
@@ -383,7 +496,7 @@ func CreateProduct(db *mongo.Database) gin.HandlerFunc {
    UPDATE
 ======================= */
 
-func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
+func UpdateProduct(db *mongo.Database, uploads storage.Storage, productSearch *search.Index) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := primitive.ObjectIDFromHex(c.Param("id"))
 		if err != nil {
@@ -405,7 +518,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		if strings.HasPrefix(c.GetHeader("Content-Type"), "multipart/form-data") {
-			input, err := parseMultipartProductRequest(c)
+			input, err := parseMultipartProductRequest(c, uploads)
 			if err != nil {
 				log.Println("UpdateProduct multipart error:", err)
 				respondMultipartError(c, err)
@@ -414,7 +527,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 
 			log.Printf("UpdateProduct image received: %t", input.ImageSet)
 			log.Printf(
-				"UpdateProduct form values: brand=%q barcode=%q stock=%d description=%q",
+				"UpdateProduct form values: brand=%q barcode=%q stock=%v description=%q",
 				sanitizeLogValue(input.Brand, 80),
 				sanitizeLogValue(input.Barcode, 80),
 				input.Stock,
@@ -441,7 +554,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				return
 			}
 
-			existingImagePath := strings.TrimSpace(existing.ImagePath)
+			var removedImages []models.ProductImage
 
 			updateSet := bson.M{}
 			updateUnset := bson.M{}
@@ -472,12 +585,12 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				saleInput.SalePrice = &input.SalePrice
 			}
 			if input.CategoryIDSet {
-				categoryNames, err := resolveCategoryNamesByIDs(context.Background(), db, input.CategoryIDs)
+				categories, err := resolveCategoriesByIDs(context.Background(), db, input.CategoryIDs)
 				if err != nil {
 					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 					return
 				}
-				updateSet["category"] = models.StringList(normalizeCategories(categoryNames))
+				categories.setOn(updateSet)
 			}
 			if input.DescriptionSet {
 				updateSet["description"] = strings.TrimSpace(input.Description)
@@ -493,10 +606,40 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			if input.BrandSet {
 				updateSet["brand"] = strings.TrimSpace(input.Brand)
 			}
-			if input.ImageSet && strings.TrimSpace(input.ImagePath) != "" {
-				updateSet["imagePath"] = input.ImagePath
+			if input.UnitSet {
+				if !input.StockSet {
+					if err := validateQuantity(input.Unit, existing.Stock); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"error": "stock: " + err.Error()})
+						return
+					}
+				}
+				updateSet["unit"] = input.Unit
+			}
+			if input.VariantLabelSet {
+				if input.VariantLabel == "" {
+					updateUnset["variantLabel"] = ""
+				} else {
+					updateSet["variantLabel"] = input.VariantLabel
+				}
+			}
+			if input.ImageSet {
+				gallery, removed := mergeUploadedImages(productGallery(existing), input.CoverImage, input.Images)
+				if len(gallery) > maxProductImages {
+					uploaded := input.Images
+					if input.CoverImage != nil {
+						uploaded = append(uploaded, *input.CoverImage)
+					}
+					deleteProductImageFiles(context.Background(), uploads, uploaded)
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a product can have at most %d images", maxProductImages)})
+					return
+				}
+				removedImages = removed
+				updateSet["images"] = gallery
+				updateSet["imagePath"] = coverImagePath(gallery)
 				updateUnset["image"] = ""
 			} else if removeImage {
+				removedImages = productGallery(existing)
+				updateUnset["images"] = ""
 				updateUnset["imagePath"] = ""
 				updateUnset["image"] = ""
 			}
@@ -505,11 +648,33 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 					c.JSON(http.StatusBadRequest, gin.H{"error": "stock must be zero or greater"})
 					return
 				}
+				unit := existing.Unit
+				if input.UnitSet {
+					unit = input.Unit
+				}
+				if err := validateQuantity(unit, input.Stock); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "stock: " + err.Error()})
+					return
+				}
 				updateSet["stock"] = input.Stock
 				updateSet["inStock"] = input.Stock > 0
 			} else if input.InStockSet {
 				updateSet["inStock"] = input.InStock
 			}
+			if input.ReorderLevelSet {
+				if input.ReorderLevel < 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "reorderLevel must be zero or greater"})
+					return
+				}
+				updateSet["reorderLevel"] = input.ReorderLevel
+			}
+			if input.CostPriceSet {
+				if input.CostPrice < 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "costPrice must be zero or greater"})
+					return
+				}
+				updateSet["costPrice"] = roundMoney(input.CostPrice)
+			}
 			if input.IsActiveSet {
 				updateSet["isActive"] = input.IsActive
 			}
@@ -549,14 +714,15 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			}
 			log.Printf("UpdateProduct update document: %+v", update)
 
-			result, err := db.Collection("products").UpdateOne(
-				context.Background(),
-				bson.M{
-					"_id":       id,
-					"isDeleted": bson.M{"$ne": true},
-				},
-				update,
-			)
+			var newStock *float64
+			if input.StockSet {
+				newStock = &input.Stock
+			}
+			result, err := updateProductRecordingStock(context.Background(), db, id, update, newStock, models.StockMovement{
+				Type:   models.StockMovementAdjustment,
+				Reason: "ürün güncelleme",
+				Actor:  stockActorFromContext(c),
+			})
 
 			if err != nil {
 				log.Println("UpdateProduct update error:", err)
@@ -573,15 +739,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				return
 			}
 
-			if input.ImageSet && existingImagePath != "" && existingImagePath != input.ImagePath {
-				if err := safeDeleteUpload(existingImagePath); err != nil {
-					log.Printf("UpdateProduct old image delete failed: %v", err)
-				}
-			} else if removeImage && existingImagePath != "" {
-				if err := safeDeleteUpload(existingImagePath); err != nil {
-					log.Printf("UpdateProduct removeImage delete failed: %v", err)
-				}
-			}
+			deleteProductImageFiles(context.Background(), uploads, removedImages)
 
 			var updated models.Product
 			err = db.Collection("products").FindOne(
@@ -606,6 +764,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 
 			updated.InStock = updated.Stock > 0
 			updated.IsOnSale = isProductOnSale(updated.Price, updated.SaleEnabled, updated.SalePrice)
+			productSearch.Invalidate()
 			c.JSON(http.StatusOK, updated)
 			return
 		}
@@ -666,8 +825,10 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 		}
 		log.Printf("UpdateProduct parsed request: %+v", req)
 
-		var existingImagePath string
-		if removeImage {
+		var existingImages []models.ProductImage
+		existingUnit := models.UnitPiece
+		existingStock := 0.0
+		if removeImage || req.Stock != nil || req.Unit != nil {
 			var existing models.Product
 			err := db.Collection("products").FindOne(
 				context.Background(),
@@ -684,7 +845,11 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 				return
 			}
-			existingImagePath = strings.TrimSpace(existing.ImagePath)
+			existingImages = productGallery(existing)
+			if existing.Unit != "" {
+				existingUnit = existing.Unit
+			}
+			existingStock = existing.Stock
 		}
 
 		updateSet := bson.M{}
@@ -712,13 +877,13 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			saleInput.SalePrice = req.SalePrice
 		}
 		if req.CategoryIDs != nil {
-			categoryNames, err := resolveCategoryNamesByIDs(context.Background(), db, *req.CategoryIDs)
+			categories, err := resolveCategoriesByIDs(context.Background(), db, *req.CategoryIDs)
 			if err != nil {
 				log.Println("UpdateProduct RETURN 400:", err)
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
-			updateSet["category"] = models.StringList(normalizeCategories(categoryNames))
+			categories.setOn(updateSet)
 		}
 		if req.Description != nil {
 			updateSet["description"] = strings.TrimSpace(*req.Description)
@@ -734,17 +899,60 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 		if req.Brand != nil {
 			updateSet["brand"] = strings.TrimSpace(*req.Brand)
 		}
+		unit := existingUnit
+		if req.Unit != nil {
+			parsed, err := parseProductUnit(*req.Unit)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			unit = parsed
+			if req.Stock == nil {
+				if err := validateQuantity(unit, existingStock); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "stock: " + err.Error()})
+					return
+				}
+			}
+			updateSet["unit"] = unit
+		}
+		if req.VariantLabel != nil {
+			if label := strings.TrimSpace(*req.VariantLabel); label == "" {
+				updateUnset["variantLabel"] = ""
+			} else {
+				updateSet["variantLabel"] = label
+			}
+		}
 		if req.Stock != nil {
 			if *req.Stock < 0 {
 				log.Println("UpdateProduct RETURN 400:", "stock must be zero or greater")
 				c.JSON(http.StatusBadRequest, gin.H{"error": "stock must be zero or greater"})
 				return
 			}
+			if err := validateQuantity(unit, *req.Stock); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "stock: " + err.Error()})
+				return
+			}
 			updateSet["stock"] = *req.Stock
 			updateSet["inStock"] = *req.Stock > 0
 		} else if req.InStock != nil {
 			updateSet["inStock"] = *req.InStock
 		}
+		if req.ReorderLevel != nil {
+			if *req.ReorderLevel < 0 {
+				log.Println("UpdateProduct RETURN 400:", "reorderLevel must be zero or greater")
+				c.JSON(http.StatusBadRequest, gin.H{"error": "reorderLevel must be zero or greater"})
+				return
+			}
+			updateSet["reorderLevel"] = *req.ReorderLevel
+		}
+		if req.CostPrice != nil {
+			if *req.CostPrice < 0 {
+				log.Println("UpdateProduct RETURN 400:", "costPrice must be zero or greater")
+				c.JSON(http.StatusBadRequest, gin.H{"error": "costPrice must be zero or greater"})
+				return
+			}
+			updateSet["costPrice"] = roundMoney(*req.CostPrice)
+		}
 		if req.IsActive != nil {
 			updateSet["isActive"] = *req.IsActive
 		}
@@ -752,6 +960,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			updateSet["isCampaign"] = *req.IsCampaign
 		}
 		if removeImage {
+			updateUnset["images"] = ""
 			updateUnset["imagePath"] = ""
 			updateUnset["image"] = ""
 		}
@@ -805,14 +1014,11 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 		}
 		log.Printf("UpdateProduct update document: %+v", update)
 
-		result, err := db.Collection("products").UpdateOne(
-			context.Background(),
-			bson.M{
-				"_id":       id,
-				"isDeleted": bson.M{"$ne": true},
-			},
-			update,
-		)
+		result, err := updateProductRecordingStock(context.Background(), db, id, update, req.Stock, models.StockMovement{
+			Type:   models.StockMovementAdjustment,
+			Reason: "ürün güncelleme",
+			Actor:  stockActorFromContext(c),
+		})
 
 		if err != nil {
 			log.Println("UpdateProduct update error:", err)
@@ -829,10 +1035,8 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		if removeImage && existingImagePath != "" {
-			if err := safeDeleteUpload(existingImagePath); err != nil {
-				log.Printf("UpdateProduct removeImage delete failed: %v", err)
-			}
+		if removeImage {
+			deleteProductImageFiles(context.Background(), uploads, existingImages)
 		}
 
 		var updated models.Product
@@ -858,6 +1062,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
 
 		updated.InStock = updated.Stock > 0
 		updated.IsOnSale = isProductOnSale(updated.Price, updated.SaleEnabled, updated.SalePrice)
+		productSearch.Invalidate()
 		c.JSON(http.StatusOK, updated)
 	}
 }
@@ -866,7 +1071,7 @@ func UpdateProduct(db *mongo.Database) gin.HandlerFunc {
    DELETE (SOFT)
 ======================= */
 
-func DeleteProduct(db *mongo.Database) gin.HandlerFunc {
+func DeleteProduct(db *mongo.Database, uploads storage.Storage, productSearch *search.Index) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := primitive.ObjectIDFromHex(c.Param("id"))
 		if err != nil {
@@ -916,10 +1121,18 @@ func DeleteProduct(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		if err := safeDeleteUpload(existing.ImagePath); err != nil {
-			log.Printf("DeleteProduct image delete failed: %v", err)
+		if err := detachVariants(context.Background(), db, id); err != nil {
+			log.Printf("DeleteProduct detach variants failed: %v", err)
 		}
+		if existing.ParentID != nil {
+			if err := ungroupIfEmpty(context.Background(), db, *existing.ParentID); err != nil {
+				log.Printf("DeleteProduct ungroup failed: %v", err)
+			}
+		}
+
+		deleteProductImageFiles(context.Background(), uploads, productGallery(existing))
 
+		productSearch.Invalidate()
 		c.JSON(http.StatusOK, gin.H{"message": "product deleted"})
 	}
 }
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultReportTimezone = "Europe/Istanbul"
+	defaultReportDays     = 30
+	maxReportRange        = 366 * 24 * time.Hour
+)
+
+var validReportGranularities = map[string]struct{}{
+	"day":   {},
+	"week":  {},
+	"month": {},
+}
+
+// reportRange is the [From, To) window of a report in the requested timezone.
+type reportRange struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+type salesReportRow struct {
+	Period        string  `json:"period" bson:"_id"`
+	Revenue       float64 `json:"revenue" bson:"revenue"`
+	OrderCount    int64   `json:"orderCount" bson:"orderCount"`
+	AverageBasket float64 `json:"averageBasket" bson:"averageBasket"`
+	Cost          float64 `json:"cost" bson:"cost"`
+	Margin        float64 `json:"margin" bson:"margin"`
+}
+
+type topProductRow struct {
+	ProductID primitive.ObjectID `json:"productId" bson:"_id"`
+	Name      string             `json:"name" bson:"name"`
+	Quantity  float64            `json:"quantity" bson:"quantity"`
+	Revenue   float64            `json:"revenue" bson:"revenue"`
+	Cost      float64            `json:"cost" bson:"cost"`
+	Margin    float64            `json:"margin" bson:"margin"`
+}
+
+type breakdownRow struct {
+	Key        string  `json:"key" bson:"_id"`
+	OrderCount int64   `json:"orderCount" bson:"orderCount"`
+	Revenue    float64 `json:"revenue" bson:"revenue"`
+}
+
+/*
+GET /admin/api/reports/sales
+- ?from=2025-01-01&to=2025-01-31 (gün dahil), ?tz=Europe/Istanbul
+- ?granularity=day|week|month, ?format=csv
+- İptal edilen siparişler ciroya dahil edilmez
+*/
+func AdminSalesReport(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/reports/sales"
+
+		rng, err := parseReportRange(c.Query("from"), c.Query("to"), c.Query("tz"), time.Now())
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		granularity := strings.ToLower(strings.TrimSpace(c.DefaultQuery("granularity", "day")))
+		if _, ok := validReportGranularities[granularity]; !ok {
+			respondWithError(c, http.StatusBadRequest, route, "granularity must be day, week or month")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+		defer cancel()
+
+		periodFormat := "%Y-%m-%d"
+		if granularity == "month" {
+			periodFormat = "%Y-%m"
+		}
+
+		truncate := bson.M{
+			"date":     "$createdAt",
+			"unit":     granularity,
+			"timezone": rng.Location.String(),
+		}
+		if granularity == "week" {
+			truncate["startOfWeek"] = "monday"
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: reportMatch(rng, true)}},
+			{{Key: "$group", Value: bson.M{
+				"_id": bson.M{"$dateToString": bson.M{
+					"date":     bson.M{"$dateTrunc": truncate},
+					"format":   periodFormat,
+					"timezone": rng.Location.String(),
+				}},
+				"revenue":    bson.M{"$sum": "$totalPrice"},
+				"orderCount": bson.M{"$sum": 1},
+				"cost": bson.M{"$sum": bson.M{"$sum": bson.M{"$map": bson.M{
+					"input": "$items",
+					"as":    "item",
+					"in":    itemCostExpr("$$item"),
+				}}}},
+			}}},
+			{{Key: "$addFields", Value: bson.M{
+				"averageBasket": bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$revenue", "$orderCount"}}, 2}},
+				"cost":          bson.M{"$round": bson.A{"$cost", 2}},
+				"margin":        bson.M{"$round": bson.A{bson.M{"$subtract": bson.A{"$revenue", "$cost"}}, 2}},
+			}}},
+			{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		}
+
+		rows := []salesReportRow{}
+		if err := aggregateInto(ctx, db, pipeline, &rows); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		totals := salesReportRow{Period: "total"}
+		for _, row := range rows {
+			totals.Revenue += row.Revenue
+			totals.OrderCount += row.OrderCount
+			totals.Cost += row.Cost
+		}
+		totals.Cost = roundMoney(totals.Cost)
+		totals.Margin = roundMoney(totals.Revenue - totals.Cost)
+		if totals.OrderCount > 0 {
+			totals.AverageBasket = roundMoney(totals.Revenue / float64(totals.OrderCount))
+		}
+
+		if wantsCSV(c) {
+			records := make([][]string, 0, len(rows)+1)
+			for _, row := range append(rows, totals) {
+				records = append(records, []string{
+					row.Period,
+					formatMoney(row.Revenue),
+					strconv.FormatInt(row.OrderCount, 10),
+					formatMoney(row.AverageBasket),
+					formatMoney(row.Cost),
+					formatMoney(row.Margin),
+				})
+			}
+			writeCSV(c, "sales-report.csv", []string{"period", "revenue", "orderCount", "averageBasket", "cost", "margin"}, records)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"range":       rng.response(),
+			"granularity": granularity,
+			"data":        rows,
+			"totals":      totals,
+		})
+	}
+}
+
+/*
+GET /admin/api/reports/top-products
+- ?sortBy=quantity|revenue|margin, ?limit=10 (max 100), ?format=csv
+*/
+func AdminTopProductsReport(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/reports/top-products"
+
+		rng, err := parseReportRange(c.Query("from"), c.Query("to"), c.Query("tz"), time.Now())
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		sortBy := strings.TrimSpace(c.DefaultQuery("sortBy", "quantity"))
+		if sortBy != "quantity" && sortBy != "revenue" && sortBy != "margin" {
+			respondWithError(c, http.StatusBadRequest, route, "sortBy must be quantity, revenue or margin")
+			return
+		}
+
+		_, limit, err := parsePaginationParams("", c.DefaultQuery("limit", "10"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+		defer cancel()
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: reportMatch(rng, true)}},
+			{{Key: "$unwind", Value: "$items"}},
+			{{Key: "$group", Value: bson.M{
+				"_id":      "$items.productId",
+				"name":     bson.M{"$last": "$items.name"},
+				"quantity": bson.M{"$sum": "$items.quantity"},
+				"revenue":  bson.M{"$sum": bson.M{"$multiply": bson.A{"$items.price", "$items.quantity"}}},
+				"cost":     bson.M{"$sum": itemCostExpr("$items")},
+			}}},
+			{{Key: "$addFields", Value: bson.M{
+				"margin": bson.M{"$subtract": bson.A{"$revenue", "$cost"}},
+			}}},
+			{{Key: "$sort", Value: bson.D{{Key: sortBy, Value: -1}, {Key: "_id", Value: 1}}}},
+			{{Key: "$limit", Value: limit}},
+		}
+
+		rows := []topProductRow{}
+		if err := aggregateInto(ctx, db, pipeline, &rows); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		if wantsCSV(c) {
+			records := make([][]string, 0, len(rows))
+			for _, row := range rows {
+				records = append(records, []string{
+					row.ProductID.Hex(),
+					row.Name,
+					strconv.FormatFloat(roundQuantity(row.Quantity), 'f', -1, 64),
+					formatMoney(row.Revenue),
+					formatMoney(row.Cost),
+					formatMoney(row.Margin),
+				})
+			}
+			writeCSV(c, "top-products.csv", []string{"productId", "name", "quantity", "revenue", "cost", "margin"}, records)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"range":  rng.response(),
+			"sortBy": sortBy,
+			"data":   rows,
+		})
+	}
+}
+
+/*
+GET /admin/api/reports/breakdown
+- Ödeme yöntemi ve sipariş durumuna göre adet/ciro
+- ?format=csv → dimension,key,orderCount,revenue
+*/
+func AdminBreakdownReport(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/reports/breakdown"
+
+		rng, err := parseReportRange(c.Query("from"), c.Query("to"), c.Query("tz"), time.Now())
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+		defer cancel()
+
+		group := func(field string) bson.A {
+			return bson.A{
+				bson.M{"$group": bson.M{
+					"_id":        "$" + field,
+					"orderCount": bson.M{"$sum": 1},
+					"revenue":    bson.M{"$sum": "$totalPrice"},
+				}},
+				bson.M{"$sort": bson.M{"orderCount": -1}},
+			}
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: reportMatch(rng, false)}},
+			{{Key: "$facet", Value: bson.M{
+				"paymentMethod": group("paymentMethod"),
+				"status":        group("status"),
+			}}},
+		}
+
+		var result []struct {
+			PaymentMethod []breakdownRow `bson:"paymentMethod"`
+			Status        []breakdownRow `bson:"status"`
+		}
+		if err := aggregateInto(ctx, db, pipeline, &result); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		byPaymentMethod := []breakdownRow{}
+		byStatus := []breakdownRow{}
+		if len(result) > 0 {
+			byPaymentMethod = append(byPaymentMethod, result[0].PaymentMethod...)
+			byStatus = append(byStatus, result[0].Status...)
+		}
+
+		if wantsCSV(c) {
+			records := make([][]string, 0, len(byPaymentMethod)+len(byStatus))
+			for _, dimension := range []struct {
+				name string
+				rows []breakdownRow
+			}{{"paymentMethod", byPaymentMethod}, {"status", byStatus}} {
+				for _, row := range dimension.rows {
+					records = append(records, []string{
+						dimension.name,
+						row.Key,
+						strconv.FormatInt(row.OrderCount, 10),
+						formatMoney(row.Revenue),
+					})
+				}
+			}
+			writeCSV(c, "order-breakdown.csv", []string{"dimension", "key", "orderCount", "revenue"}, records)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"range":           rng.response(),
+			"byPaymentMethod": byPaymentMethod,
+			"byStatus":        byStatus,
+		})
+	}
+}
+
+// parseReportRange reads inclusive YYYY-MM-DD bounds in the given timezone.
+// Without bounds the last 30 days (including today) are reported.
+func parseReportRange(fromStr, toStr, tzStr string, now time.Time) (reportRange, error) {
+	tzName := strings.TrimSpace(tzStr)
+	if tzName == "" {
+		tzName = defaultReportTimezone
+	}
+	loc, err := time.LoadLocation(tzName)
+	if err != nil {
+		return reportRange{}, errors.New("invalid tz")
+	}
+
+	today := now.In(loc)
+	startOfToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+
+	to := startOfToday.AddDate(0, 0, 1)
+	if value := strings.TrimSpace(toStr); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return reportRange{}, errors.New("invalid to date (expected YYYY-MM-DD)")
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	from := to.AddDate(0, 0, -defaultReportDays)
+	if value := strings.TrimSpace(fromStr); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return reportRange{}, errors.New("invalid from date (expected YYYY-MM-DD)")
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return reportRange{}, errors.New("from must be before to")
+	}
+	if to.Sub(from) > maxReportRange {
+		return reportRange{}, errors.New("date range too large (max 366 days)")
+	}
+
+	return reportRange{From: from, To: to, Location: loc}, nil
+}
+
+func (r reportRange) response() gin.H {
+	return gin.H{
+		"from":     r.From.Format("2006-01-02"),
+		"to":       r.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		"timezone": r.Location.String(),
+	}
+}
+
+// itemCostExpr is the cost of an order line. Lines sold before cost prices
+// were tracked have no costPrice and count as zero cost.
+func itemCostExpr(item string) bson.M {
+	return bson.M{"$multiply": bson.A{
+		bson.M{"$ifNull": bson.A{item + ".costPrice", 0}},
+		item + ".quantity",
+	}}
+}
+
+func reportMatch(rng reportRange, excludeCancelled bool) bson.M {
+	match := bson.M{"createdAt": bson.M{"$gte": rng.From, "$lt": rng.To}}
+	if excludeCancelled {
+		match["status"] = bson.M{"$ne": "cancelled"}
+	}
+	return match
+}
+
+func aggregateInto(ctx context.Context, db *mongo.Database, pipeline mongo.Pipeline, out interface{}) error {
+	cursor, err := db.Collection("orders").Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, out)
+}
+
+func wantsCSV(c *gin.Context) bool {
+	return strings.EqualFold(strings.TrimSpace(c.Query("format")), "csv")
+}
+
+// writeCSV sends a UTF-8 CSV with a BOM so Excel shows Turkish characters.
+func writeCSV(c *gin.Context, filename string, header []string, records [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	_, _ = c.Writer.Write([]byte("\xEF\xBB\xBF"))
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write(header)
+	_ = writer.WriteAll(records)
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func formatMoney(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReportRangeUsesIstanbulDayBoundaries(t *testing.T) {
+	now := time.Date(2025, 3, 10, 22, 30, 0, 0, time.UTC) // 01:30 on 11 March in Istanbul
+
+	rng, err := parseReportRange("2025-03-01", "2025-03-10", "", now)
+	if err != nil {
+		t.Fatalf("parseReportRange returned error: %v", err)
+	}
+	if rng.Location.String() != "Europe/Istanbul" {
+		t.Fatalf("expected default timezone, got %s", rng.Location)
+	}
+	if got := rng.From.UTC(); !got.Equal(time.Date(2025, 2, 28, 21, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected from %s", got)
+	}
+	if got := rng.To.UTC(); !got.Equal(time.Date(2025, 3, 10, 21, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected exclusive end at next local midnight, got %s", got)
+	}
+
+	defaults, err := parseReportRange("", "", "", now)
+	if err != nil {
+		t.Fatalf("parseReportRange returned error: %v", err)
+	}
+	if got := defaults.response()["to"]; got != "2025-03-11" {
+		t.Fatalf("expected default range to end today in Istanbul, got %v", got)
+	}
+	if days := defaults.To.Sub(defaults.From).Hours() / 24; days != defaultReportDays {
+		t.Fatalf("expected %d day default range, got %v", defaultReportDays, days)
+	}
+}
+
+func TestParseReportRangeRejectsInvalidInput(t *testing.T) {
+	now := time.Now()
+	cases := []struct{ from, to, tz string }{
+		{"2025-03-10", "2025-03-01", ""},
+		{"2025/03/01", "", ""},
+		{"", "", "Mars/Olympus"},
+		{"2023-01-01", "2025-01-01", ""},
+	}
+	for _, tc := range cases {
+		if _, err := parseReportRange(tc.from, tc.to, tc.tz, now); err == nil {
+			t.Fatalf("expected error for from=%q to=%q tz=%q", tc.from, tc.to, tc.tz)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+type supplierRequest struct {
+	Name        *string `json:"name"`
+	ContactName *string `json:"contactName"`
+	Phone       *string `json:"phone"`
+	Email       *string `json:"email"`
+	Address     *string `json:"address"`
+	TaxNumber   *string `json:"taxNumber"`
+	Note        *string `json:"note"`
+	IsActive    *bool   `json:"isActive"`
+}
+
+// fields returns the trimmed values that were sent, keyed by bson field.
+func (r supplierRequest) fields() bson.M {
+	set := bson.M{}
+	for key, value := range map[string]*string{
+		"name":        r.Name,
+		"contactName": r.ContactName,
+		"phone":       r.Phone,
+		"email":       r.Email,
+		"address":     r.Address,
+		"taxNumber":   r.TaxNumber,
+		"note":        r.Note,
+	} {
+		if value != nil {
+			set[key] = strings.TrimSpace(*value)
+		}
+	}
+	if r.IsActive != nil {
+		set["isActive"] = *r.IsActive
+	}
+	return set
+}
+
+/*
+GET /admin/api/suppliers
+- Tüm tedarikçiler, isme göre
+- ?isActive=true/false, ?search= (isim, yetkili, telefon)
+*/
+func GetSuppliers(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/suppliers"
+
+		filter := bson.M{}
+		if v := strings.TrimSpace(c.Query("isActive")); v != "" {
+			filter["isActive"] = v == "true"
+		}
+		if search := strings.TrimSpace(c.Query("search")); search != "" {
+			pattern := bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}
+			filter["$or"] = []bson.M{
+				{"name": pattern},
+				{"contactName": pattern},
+				{"phone": pattern},
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		cursor, err := db.Collection("suppliers").Find(ctx, filter,
+			options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer cursor.Close(ctx)
+
+		suppliers := make([]models.Supplier, 0)
+		if err := cursor.All(ctx, &suppliers); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "decode error")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": suppliers})
+	}
+}
+
+/*
+GET /admin/api/suppliers/:id
+*/
+func GetSupplierByID(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/suppliers/:id"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var supplier models.Supplier
+		if err := db.Collection("suppliers").FindOne(ctx, bson.M{"_id": id}).Decode(&supplier); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				respondWithError(c, http.StatusNotFound, route, "supplier not found")
+				return
+			}
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, supplier)
+	}
+}
+
+/*
+POST /admin/api/suppliers
+- name zorunlu, aynı isimli tedarikçi eklenemez
+*/
+func CreateSupplier(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/suppliers"
+
+		var req supplierRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+
+		fields := req.fields()
+		name, _ := fields["name"].(string)
+		if name == "" {
+			respondWithError(c, http.StatusBadRequest, route, "name required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		count, err := db.Collection("suppliers").CountDocuments(ctx, bson.M{"name": name})
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if count > 0 {
+			respondWithError(c, http.StatusConflict, route, "supplier already exists")
+			return
+		}
+
+		supplier := models.Supplier{
+			ID:        primitive.NewObjectID(),
+			Name:      name,
+			IsActive:  true,
+			CreatedAt: time.Now(),
+		}
+		if req.IsActive != nil {
+			supplier.IsActive = *req.IsActive
+		}
+		supplier.ContactName, _ = fields["contactName"].(string)
+		supplier.Phone, _ = fields["phone"].(string)
+		supplier.Email, _ = fields["email"].(string)
+		supplier.Address, _ = fields["address"].(string)
+		supplier.TaxNumber, _ = fields["taxNumber"].(string)
+		supplier.Note, _ = fields["note"].(string)
+
+		if _, err := db.Collection("suppliers").InsertOne(ctx, supplier); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				respondWithError(c, http.StatusConflict, route, "supplier already exists")
+				return
+			}
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusCreated, supplier)
+	}
+}
+
+/*
+PUT /admin/api/suppliers/:id
+- Yalnızca gönderilen alanlar güncellenir
+*/
+func UpdateSupplier(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "PUT /admin/api/suppliers/:id"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		var req supplierRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+
+		update := req.fields()
+		if len(update) == 0 {
+			respondWithError(c, http.StatusBadRequest, route, "no fields to update")
+			return
+		}
+		if name, ok := update["name"].(string); ok && name == "" {
+			respondWithError(c, http.StatusBadRequest, route, "name cannot be empty")
+			return
+		}
+		update["updatedAt"] = time.Now()
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var updated models.Supplier
+		err = db.Collection("suppliers").FindOneAndUpdate(ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": update},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "supplier not found")
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			respondWithError(c, http.StatusConflict, route, "supplier already exists")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		// Purchase orders keep a copy of the supplier name for listings.
+		if name, ok := update["name"].(string); ok {
+			_, _ = db.Collection("purchase_orders").UpdateMany(ctx,
+				bson.M{"supplierId": id},
+				bson.M{"$set": bson.M{"supplierName": name}},
+			)
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+DELETE /admin/api/suppliers/:id
+- Soft delete (isActive=false); geçmiş satın alma siparişleri korunur
+*/
+func DeleteSupplier(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /admin/api/suppliers/:id"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("suppliers").UpdateOne(ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now()}},
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if result.MatchedCount == 0 {
+			respondWithError(c, http.StatusNotFound, route, "supplier not found")
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -16,7 +15,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,32 +22,23 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 
+	"backend/internal/auth"
 	"backend/internal/models"
 )
 
 const refreshCookieName = "refresh_token"
 
+// RegisterRequest accepts either a single name or the firstName/lastName pair
+// sent by older clients; both end up in User.Name.
 type RegisterRequest struct {
-	FirstName string `json:"firstName" binding:"required"`
-	LastName  string `json:"lastName" binding:"required"`
+	Name      string `json:"name"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
 	Email     string `json:"email" binding:"required"`
 	Password  string `json:"password" binding:"required"`
 	Phone     string `json:"phone"`
 }
 
-type RegisterUserRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Name     string `json:"name" binding:"required"`
-}
-
-type LoginResponseUser struct {
-	ID        string `json:"id"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Email     string `json:"email"`
-}
-
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -65,7 +54,7 @@ type AuthTokens struct {
 	ExpiresIn    int64  `json:"expiresIn"`
 }
 
-func Register(db *mongo.Database, jwtSecret string, accessTTL time.Duration) gin.HandlerFunc {
+func Register(db *mongo.Database, tokens *auth.TokenService, accessTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		body, err := io.ReadAll(c.Request.Body)
 		if err != nil {
@@ -73,53 +62,102 @@ func Register(db *mongo.Database, jwtSecret string, accessTTL time.Duration) gin
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
 			return
 		}
-		log.Printf("[AUTH] [DEBUG] register content-type: %s", c.GetHeader("Content-Type"))
-		log.Printf("[AUTH] [DEBUG] register raw body: %s", string(body))
 
 		if len(bytes.TrimSpace(body)) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "request body is required"})
 			return
 		}
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
 
-		var payload map[string]interface{}
-		if err := json.Unmarshal(body, &payload); err != nil {
-			log.Println("[AUTH] [ERROR] register parse failed:", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON payload", "details": err.Error()})
+		var req RegisterRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
 			return
 		}
 
-		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		email := strings.ToLower(strings.TrimSpace(req.Email))
+		name := accountName(req.Name, req.FirstName, req.LastName)
+		password := strings.TrimSpace(req.Password)
+		if email == "" || name == "" || password == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "email, password and name are required"})
+			return
+		}
 
-		hasCustomerFields := false
-		if _, ok := payload["firstName"]; ok {
-			hasCustomerFields = true
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		count, err := db.Collection("users").CountDocuments(ctx, bson.M{"email": email})
+		if err != nil {
+			log.Println("[AUTH] [ERROR] user register db error:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if count > 0 {
+			log.Println("[AUTH] [ERROR] user register email exists:", email)
+			c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+			return
 		}
-		if _, ok := payload["lastName"]; ok {
-			hasCustomerFields = true
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Println("[AUTH] [ERROR] user register password hash failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash failed"})
+			return
 		}
-		if _, ok := payload["phone"]; ok {
-			hasCustomerFields = true
+
+		now := time.Now()
+		user := models.User{
+			Email:        email,
+			PasswordHash: string(hash),
+			Name:         name,
+			Phone:        normalizePhoneOrRaw(req.Phone),
+			Role:         models.RoleUser,
+			IsActive:     true,
+			Addresses:    []models.Address{},
+			CreatedAt:    now,
+			UpdatedAt:    now,
 		}
 
-		if hasCustomerFields {
-			var customerReq RegisterRequest
-			if err := c.ShouldBindBodyWith(&customerReq, binding.JSON); err != nil {
-				respondValidationError(c, err)
+		res, err := db.Collection("users").InsertOne(ctx, user)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
 				return
 			}
-			registerCustomer(c, db, customerReq)
+			log.Println("[AUTH] [ERROR] user register insert failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 			return
 		}
 
-		var userReq RegisterUserRequest
-		if err := c.ShouldBindBodyWith(&userReq, binding.JSON); err != nil {
-			respondValidationError(c, err)
+		id, _ := res.InsertedID.(primitive.ObjectID)
+		accessToken, err := issueUserToken(id, email, tokens, accessTTL)
+		if err != nil {
+			log.Println("[AUTH] [ERROR] user register token generation failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
 			return
 		}
-		registerUser(c, db, userReq, jwtSecret, accessTTL)
+
+		log.Println("[AUTH] [INFO] user registered:", email)
+		c.JSON(http.StatusCreated, gin.H{
+			"message":     "User registered successfully",
+			"accessToken": accessToken,
+			"user": gin.H{
+				"id":    id.Hex(),
+				"name":  name,
+				"email": email,
+			},
+		})
 	}
 }
 
+// accountName prefers an explicit name and falls back to "firstName lastName".
+func accountName(name, firstName, lastName string) string {
+	if trimmed := strings.TrimSpace(name); trimmed != "" {
+		return trimmed
+	}
+	return strings.TrimSpace(strings.TrimSpace(firstName) + " " + strings.TrimSpace(lastName))
+}
+
 func respondValidationError(c *gin.Context, err error) {
 	var validationErrors validator.ValidationErrors
 	if errors.As(err, &validationErrors) {
@@ -150,7 +188,7 @@ func lowerCamel(field string) string {
 	return strings.ToLower(field[:1]) + field[1:]
 }
 
-func Login(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
+func Login(db *mongo.Database, tokenService *auth.TokenService, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req LoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -168,82 +206,52 @@ func Login(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Dura
 		defer cancel()
 
 		var user models.User
-		if err := db.Collection("users").FindOne(ctx, bson.M{"email": email}).Decode(&user); err == nil {
-			if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-				log.Println("[AUTH] [ERROR] login invalid credentials for user")
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-				return
-			}
-
-			tokens, err := issueTokens(c, db, user.ID, user.Email, "user", jwtSecret, accessTTL, refreshTTL)
-			if err != nil {
-				log.Println("[AUTH] [ERROR] login token generation failed:", err)
+		if err := db.Collection("users").FindOne(ctx, bson.M{"email": email}).Decode(&user); err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Println("[AUTH] [ERROR] login user lookup failed:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 				return
 			}
-
-			setRefreshCookie(c, tokens.RefreshToken, refreshTTL)
-
-			log.Println("[AUTH] [INFO] user login succeeded:", user.Email)
-			c.JSON(http.StatusOK, gin.H{
-				"accessToken":  tokens.AccessToken,
-				"refreshToken": tokens.RefreshToken,
-				"expiresIn":    tokens.ExpiresIn,
-				"user": gin.H{
-					"id":    user.ID.Hex(),
-					"name":  user.Name,
-					"email": user.Email,
-				},
-			})
-			return
-		} else if err != mongo.ErrNoDocuments {
-			log.Println("[AUTH] [ERROR] login user lookup failed:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			log.Println("[AUTH] [ERROR] login invalid credentials for user")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 			return
 		}
 
-		var customer models.Customer
-		if err := db.Collection("customers").FindOne(ctx, bson.M{"email": email}).Decode(&customer); err != nil {
-			log.Println("[AUTH] [ERROR] login invalid credentials for customer")
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+			log.Println("[AUTH] [ERROR] login invalid credentials for user")
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 			return
 		}
 
-		if !customer.IsActive {
-			log.Println("[AUTH] [ERROR] customer inactive:", email)
+		if !user.IsActive {
+			log.Println("[AUTH] [ERROR] user inactive:", email)
 			c.JSON(http.StatusForbidden, gin.H{"error": "user is inactive"})
 			return
 		}
 
-		if err := bcrypt.CompareHashAndPassword([]byte(customer.PasswordHash), []byte(req.Password)); err != nil {
-			log.Println("[AUTH] [ERROR] login invalid credentials for customer")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-			return
-		}
-
-		tokens, err := issueTokens(c, db, customer.ID, customer.Email, customer.Role, jwtSecret, accessTTL, refreshTTL)
+		tokens, err := issueTokens(c, db, user.ID, user.Email, user.AccountRole(), tokenService, accessTTL, refreshTTL)
 		if err != nil {
-			log.Println("[AUTH] [ERROR] customer login token generation failed:", err)
+			log.Println("[AUTH] [ERROR] login token generation failed:", err)
 			return
 		}
 
 		setRefreshCookie(c, tokens.RefreshToken, refreshTTL)
 
-		log.Println("[AUTH] [INFO] customer login succeeded:", customer.Email)
+		log.Println("[AUTH] [INFO] user login succeeded:", user.Email)
 		c.JSON(http.StatusOK, gin.H{
 			"accessToken":  tokens.AccessToken,
 			"refreshToken": tokens.RefreshToken,
 			"expiresIn":    tokens.ExpiresIn,
-			"user": LoginResponseUser{
-				ID:        customer.ID.Hex(),
-				FirstName: customer.FirstName,
-				LastName:  customer.LastName,
-				Email:     customer.Email,
+			"user": gin.H{
+				"id":    user.ID.Hex(),
+				"name":  user.Name,
+				"email": user.Email,
 			},
 		})
 	}
 }
 
-func Refresh(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
+func Refresh(db *mongo.Database, tokens *auth.TokenService, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		plain := readRefreshToken(c)
 		if plain == "" {
@@ -271,40 +279,18 @@ func Refresh(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Du
 		}
 
 		userID := token.UserID
-		userEmail := ""
-		userRole := "user"
-		responseUser := gin.H{"id": userID.Hex()}
-
-		var customer models.Customer
-		if err := db.Collection("customers").FindOne(ctx, bson.M{"_id": userID}).Decode(&customer); err == nil {
-			if !customer.IsActive {
-				c.JSON(http.StatusForbidden, gin.H{"error": "user is inactive"})
-				return
-			}
-			userEmail = customer.Email
-			userRole = customer.Role
-			responseUser = gin.H{
-				"id":        customer.ID.Hex(),
-				"firstName": customer.FirstName,
-				"lastName":  customer.LastName,
-				"email":     customer.Email,
-			}
-		} else {
-			var user models.User
-			if err := db.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
-				return
-			}
-			userEmail = user.Email
-			userRole = "user"
-			responseUser = gin.H{
-				"id":    user.ID.Hex(),
-				"name":  user.Name,
-				"email": user.Email,
-			}
+
+		var user models.User
+		if err := db.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			return
+		}
+		if !user.IsActive {
+			c.JSON(http.StatusForbidden, gin.H{"error": "user is inactive"})
+			return
 		}
 
-		newTokens, err := issueTokens(c, db, userID, userEmail, userRole, jwtSecret, accessTTL, refreshTTL)
+		newTokens, err := issueTokens(c, db, userID, user.Email, user.AccountRole(), tokens, accessTTL, refreshTTL)
 		if err != nil {
 			return
 		}
@@ -323,7 +309,11 @@ func Refresh(db *mongo.Database, jwtSecret string, accessTTL, refreshTTL time.Du
 			"token":        newTokens.AccessToken,
 			"refreshToken": newTokens.RefreshToken,
 			"expiresIn":    newTokens.ExpiresIn,
-			"user":         responseUser,
+			"user": gin.H{
+				"id":    user.ID.Hex(),
+				"name":  user.Name,
+				"email": user.Email,
+			},
 		})
 	}
 }
@@ -395,134 +385,15 @@ func clearRefreshCookie(c *gin.Context) {
 	c.SetCookie(refreshCookieName, "", -1, "/", ".herevemarket.com", true, true)
 }
 
-func registerCustomer(c *gin.Context, db *mongo.Database, req RegisterRequest) {
-	email := strings.ToLower(strings.TrimSpace(req.Email))
-	if email == "" || strings.TrimSpace(req.Password) == "" || strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "firstName, lastName, email and password are required"})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	count, err := db.Collection("customers").CountDocuments(ctx, bson.M{"email": email})
-	if err != nil {
-		log.Println("[AUTH] [ERROR] customer register db error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
-		return
-	}
-	if count > 0 {
-		log.Println("[AUTH] [ERROR] customer register email exists:", email)
-		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
-		return
-	}
-
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Println("[AUTH] [ERROR] customer register password hash failed:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash failed"})
-		return
-	}
-
-	now := time.Now()
-	customer := models.Customer{
-		FirstName:    strings.TrimSpace(req.FirstName),
-		LastName:     strings.TrimSpace(req.LastName),
-		Email:        email,
-		Phone:        strings.TrimSpace(req.Phone),
-		PasswordHash: string(hash),
-		IsActive:     true,
-		Role:         "user",
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-
-	if _, err := db.Collection("customers").InsertOne(ctx, customer); err != nil {
-		log.Println("[AUTH] [ERROR] customer register insert failed:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
-		return
-	}
-
-	log.Println("[AUTH] [INFO] customer registered:", email)
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
-}
-
-func registerUser(c *gin.Context, db *mongo.Database, req RegisterUserRequest, jwtSecret string, accessTTL time.Duration) {
-	email := strings.ToLower(strings.TrimSpace(req.Email))
-	name := strings.TrimSpace(req.Name)
-	password := strings.TrimSpace(req.Password)
-	if email == "" || name == "" || password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email, password and name are required"})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	count, err := db.Collection("users").CountDocuments(ctx, bson.M{"email": email})
-	if err != nil {
-		log.Println("[AUTH] [ERROR] user register db error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
-		return
-	}
-	if count > 0 {
-		log.Println("[AUTH] [ERROR] user register email exists:", email)
-		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
-		return
-	}
-
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Println("[AUTH] [ERROR] user register password hash failed:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash failed"})
-		return
-	}
-
-	now := time.Now()
-	user := models.User{
-		Email:        email,
-		PasswordHash: string(hash),
-		Name:         name,
-		Addresses:    []models.Address{},
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-
-	res, err := db.Collection("users").InsertOne(ctx, user)
-	if err != nil {
-		log.Println("[AUTH] [ERROR] user register insert failed:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
-		return
-	}
-
-	id, _ := res.InsertedID.(primitive.ObjectID)
-	accessToken, err := issueUserToken(id, email, jwtSecret, accessTTL)
-	if err != nil {
-		log.Println("[AUTH] [ERROR] user register token generation failed:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
-		return
-	}
-
-	log.Println("[AUTH] [INFO] user registered:", email)
-	c.JSON(http.StatusCreated, gin.H{
-		"accessToken": accessToken,
-		"user": gin.H{
-			"id":    id.Hex(),
-			"name":  name,
-			"email": email,
-		},
-	})
-}
-
-func issueUserToken(userID primitive.ObjectID, email, secret string, accessTTL time.Duration) (string, error) {
+func issueUserToken(userID primitive.ObjectID, email string, tokens *auth.TokenService, accessTTL time.Duration) (string, error) {
 	claims := jwt.MapClaims{
+		"sub":    userID.Hex(),
 		"userId": userID.Hex(),
 		"email":  email,
 		"exp":    time.Now().Add(accessTTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return tokens.Sign(claims)
 }
 
 type issuedTokens struct {
@@ -532,7 +403,7 @@ type issuedTokens struct {
 	ExpiresIn      int64
 }
 
-func issueTokens(c *gin.Context, db *mongo.Database, userID primitive.ObjectID, email, role, secret string, accessTTL, refreshTTL time.Duration) (*issuedTokens, error) {
+func issueTokens(c *gin.Context, db *mongo.Database, userID primitive.ObjectID, email, role string, tokens *auth.TokenService, accessTTL, refreshTTL time.Duration) (*issuedTokens, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":    userID.Hex(),
@@ -540,10 +411,10 @@ func issueTokens(c *gin.Context, db *mongo.Database, userID primitive.ObjectID,
 		"role":   role,
 		"email":  email,
 		"exp":    now.Add(accessTTL).Unix(),
+		"iat":    now.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString([]byte(secret))
+	accessToken, err := tokens.Sign(claims)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
 		return nil, err
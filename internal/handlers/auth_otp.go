@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/sms"
+)
+
+const (
+	otpTTL            = 3 * time.Minute
+	otpResendInterval = time.Minute
+	otpMaxPerHour     = 5
+	otpMaxAttempts    = 5
+)
+
+var errInvalidPhone = errors.New("invalid phone number")
+
+type otpRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type otpVerifyRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+/*
+POST /auth/otp/request
+- Telefona 6 haneli kod gönderir
+- Aynı numaraya dakikada 1, saatte en fazla 5 istek
+*/
+func RequestOTP(db *mongo.Database, sender sms.Sender) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req otpRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		phone, err := normalizeTurkishPhone(req.Phone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+		codes := db.Collection("otp_codes")
+
+		var last models.OTPCode
+		err = codes.FindOne(ctx,
+			bson.M{"phone": phone},
+			options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+		).Decode(&last)
+		if err != nil && err != mongo.ErrNoDocuments {
+			log.Println("[OTP] [ERROR] last code lookup failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if err == nil {
+			if wait := otpResendInterval - now.Sub(last.CreatedAt); wait > 0 {
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":      "too many requests",
+					"retryAfter": int64(wait.Seconds()) + 1,
+				})
+				return
+			}
+		}
+
+		recent, err := codes.CountDocuments(ctx, bson.M{
+			"phone":     phone,
+			"createdAt": bson.M{"$gte": now.Add(-time.Hour)},
+		})
+		if err != nil {
+			log.Println("[OTP] [ERROR] rate count failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if recent >= otpMaxPerHour {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			return
+		}
+
+		code, err := generateOTPCode()
+		if err != nil {
+			log.Println("[OTP] [ERROR] code generation failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "code generation failed"})
+			return
+		}
+
+		// Earlier codes become invalid as soon as a new one is issued.
+		if _, err := codes.UpdateMany(ctx,
+			bson.M{"phone": phone, "consumed": false},
+			bson.M{"$set": bson.M{"consumed": true}},
+		); err != nil {
+			log.Println("[OTP] [ERROR] invalidate previous codes failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		record := models.OTPCode{
+			Phone:     phone,
+			CodeHash:  hashOTPCode(phone, code),
+			ExpiresAt: now.Add(otpTTL),
+			CreatedAt: now,
+		}
+		if _, err := codes.InsertOne(ctx, record); err != nil {
+			log.Println("[OTP] [ERROR] insert code failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		message := fmt.Sprintf("Herevemarket doğrulama kodunuz: %s. Kod %d dakika geçerlidir.", code, int(otpTTL.Minutes()))
+		if err := sender.Send(ctx, phone, message); err != nil {
+			log.Println("[OTP] [ERROR] sms send failed:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "sms could not be sent"})
+			return
+		}
+
+		log.Println("[OTP] [INFO] code sent:", maskPhone(phone))
+		c.JSON(http.StatusOK, gin.H{
+			"message":   "code sent",
+			"expiresIn": int64(otpTTL.Seconds()),
+		})
+	}
+}
+
+/*
+POST /auth/otp/verify
+- Kod doğruysa giriş yapar, numara ilk kez kullanılıyorsa hesap açar
+*/
+func VerifyOTP(db *mongo.Database, tokenService *auth.TokenService, accessTTL, refreshTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req otpVerifyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		phone, err := normalizeTurkishPhone(req.Phone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		code := strings.TrimSpace(req.Code)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+		codes := db.Collection("otp_codes")
+
+		var record models.OTPCode
+		err = codes.FindOne(ctx,
+			bson.M{
+				"phone":     phone,
+				"consumed":  false,
+				"expiresAt": bson.M{"$gt": now},
+			},
+			options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+		).Decode(&record)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired code"})
+			return
+		}
+		if err != nil {
+			log.Println("[OTP] [ERROR] code lookup failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		// Claim the attempt before comparing so parallel guesses cannot all
+		// pass the limit check.
+		err = codes.FindOneAndUpdate(ctx,
+			bson.M{"_id": record.ID, "consumed": false, "attempts": bson.M{"$lt": otpMaxAttempts}},
+			bson.M{"$inc": bson.M{"attempts": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&record)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many attempts"})
+			return
+		}
+		if err != nil {
+			log.Println("[OTP] [ERROR] claim attempt failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(hashOTPCode(phone, code)), []byte(record.CodeHash)) != 1 {
+			if record.Attempts >= otpMaxAttempts {
+				_, _ = codes.UpdateByID(ctx, record.ID, bson.M{"$set": bson.M{"consumed": true}})
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired code"})
+			return
+		}
+
+		// The consumed flag in the filter makes a code usable exactly once even
+		// if two verify calls race.
+		res, err := codes.UpdateOne(ctx,
+			bson.M{"_id": record.ID, "consumed": false},
+			bson.M{"$set": bson.M{"consumed": true}},
+		)
+		if err != nil {
+			log.Println("[OTP] [ERROR] consume code failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if res.ModifiedCount == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired code"})
+			return
+		}
+
+		user, created, err := findOrCreatePhoneUser(ctx, db, phone)
+		if err != nil {
+			log.Println("[OTP] [ERROR] user lookup failed:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		if !user.IsActive {
+			c.JSON(http.StatusForbidden, gin.H{"error": "user is inactive"})
+			return
+		}
+
+		tokens, err := issueTokens(c, db, user.ID, user.Email, user.AccountRole(), tokenService, accessTTL, refreshTTL)
+		if err != nil {
+			log.Println("[OTP] [ERROR] token generation failed:", err)
+			return
+		}
+
+		setRefreshCookie(c, tokens.RefreshToken, refreshTTL)
+
+		log.Println("[OTP] [INFO] phone login succeeded:", maskPhone(phone))
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		c.JSON(status, gin.H{
+			"accessToken":  tokens.AccessToken,
+			"refreshToken": tokens.RefreshToken,
+			"expiresIn":    tokens.ExpiresIn,
+			"created":      created,
+			"user": gin.H{
+				"id":    user.ID.Hex(),
+				"name":  user.Name,
+				"email": user.Email,
+				"phone": user.Phone,
+			},
+		})
+	}
+}
+
+// findOrCreatePhoneUser logs in the account that verified this number
+// through phone login, creating it on first use. Accounts that merely list
+// the number in their profile are not linked: the number may be mistyped or
+// recycled. The unique phone_verified_unique index makes the upsert safe
+// against two concurrent first logins.
+func findOrCreatePhoneUser(ctx context.Context, db *mongo.Database, phone string) (models.User, bool, error) {
+	users := db.Collection("users")
+	filter := bson.M{"phone": phone, "phoneVerified": true}
+
+	now := time.Now()
+	res, err := users.UpdateOne(ctx, filter,
+		bson.M{"$setOnInsert": bson.M{
+			"name":         "",
+			"passwordHash": "",
+			"role":         models.RoleUser,
+			"isActive":     true,
+			"addresses":    []models.Address{},
+			"createdAt":    now,
+			"updatedAt":    now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	created := err == nil && res.UpsertedCount > 0
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return models.User{}, false, err
+	}
+
+	var user models.User
+	if err := users.FindOne(ctx, filter).Decode(&user); err != nil {
+		return models.User{}, false, err
+	}
+	return user, created, nil
+}
+
+// normalizeTurkishPhone converts the usual ways of writing a Turkish mobile
+// number (0555 123 45 67, 555-123-4567, +90 555..., 0090 555...) to E.164.
+func normalizeTurkishPhone(raw string) (string, error) {
+	digits := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		switch {
+		case ch >= '0' && ch <= '9':
+			digits = append(digits, ch)
+		case ch == '+' && len(digits) == 0:
+		case ch == ' ' || ch == '-' || ch == '(' || ch == ')' || ch == '.':
+		default:
+			return "", errInvalidPhone
+		}
+	}
+
+	national := string(digits)
+	switch {
+	case strings.HasPrefix(national, "0090") && len(national) == 14:
+		national = national[4:]
+	case strings.HasPrefix(national, "90") && len(national) == 12:
+		national = national[2:]
+	case strings.HasPrefix(national, "0") && len(national) == 11:
+		national = national[1:]
+	}
+
+	if len(national) != 10 || national[0] != '5' {
+		return "", errInvalidPhone
+	}
+	return "+90" + national, nil
+}
+
+// normalizePhoneOrRaw stores Turkish mobile numbers in E.164 so they match
+// phone logins, and keeps anything else (e.g. foreign numbers) as typed.
+func normalizePhoneOrRaw(raw string) string {
+	if phone, err := normalizeTurkishPhone(raw); err == nil {
+		return phone
+	}
+	return strings.TrimSpace(raw)
+}
+
+// phoneLookupVariants lists the formats older profiles may have stored a
+// number in, so phone login finds accounts created before normalization.
+func phoneLookupVariants(e164 string) []string {
+	national := strings.TrimPrefix(e164, "+90")
+	return []string{e164, "0" + national, national, "90" + national}
+}
+
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
+func generateOTPCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashOTPCode(phone, code string) string {
+	return hashToken(phone + ":" + code)
+}
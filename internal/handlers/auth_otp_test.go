@@ -0,0 +1,42 @@
+package handlers
+
+import "testing"
+
+func TestNormalizeTurkishPhone(t *testing.T) {
+	valid := map[string]string{
+		"05551234567":        "+905551234567",
+		"5551234567":         "+905551234567",
+		"+90 555 123 45 67":  "+905551234567",
+		"905551234567":       "+905551234567",
+		"0090 555 123 45 67": "+905551234567",
+		"(0555) 123-45-67":   "+905551234567",
+	}
+	for input, expected := range valid {
+		got, err := normalizeTurkishPhone(input)
+		if err != nil {
+			t.Fatalf("normalizeTurkishPhone(%q) returned error: %v", input, err)
+		}
+		if got != expected {
+			t.Fatalf("normalizeTurkishPhone(%q) = %q, want %q", input, got, expected)
+		}
+	}
+
+	invalid := []string{"", "02121234567", "555123456", "+1 555 123 4567", "0555abc4567", "55+51234567"}
+	for _, input := range invalid {
+		if got, err := normalizeTurkishPhone(input); err == nil {
+			t.Fatalf("expected %q to be rejected, got %q", input, got)
+		}
+	}
+}
+
+func TestGenerateOTPCodeIsSixDigits(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		code, err := generateOTPCode()
+		if err != nil {
+			t.Fatalf("generateOTPCode returned error: %v", err)
+		}
+		if len(code) != 6 {
+			t.Fatalf("expected 6 digit code, got %q", code)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// Category delete modes for DELETE /admin/api/categories/:id?mode=.
+const (
+	categoryDeleteBlock    = "block"
+	categoryDeleteReassign = "reassign"
+	categoryDeleteCascade  = "cascade"
+)
+
+var errCategoryInUse = errors.New("category in use")
+
+// categoryDeleteReport is the response of a category delete.
+type categoryDeleteReport struct {
+	Mode               string   `json:"mode"`
+	DeletedCategories  []string `json:"deletedCategories"`
+	ProductsReassigned int64    `json:"productsReassigned,omitempty"`
+	ProductsUpdated    int64    `json:"productsUpdated,omitempty"`
+	ProductsDeleted    int      `json:"productsDeleted,omitempty"`
+	// set when block refuses the delete
+	Products      int64 `json:"products,omitempty"`
+	Subcategories int   `json:"subcategories,omitempty"`
+}
+
+// renameCategoryInProducts rewrites the stored name of a renamed category in
+// every product that references it.
+func renameCategoryInProducts(ctx context.Context, db *mongo.Database, id primitive.ObjectID, oldName, newName string) (int64, error) {
+	res, err := db.Collection("products").UpdateMany(ctx,
+		bson.M{"categoryIds": id, "category": bson.M{"$elemMatch": bson.M{"$eq": oldName}}},
+		bson.M{"$set": bson.M{"category.$[name]": newName}},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"name": oldName}}}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// reassignCategory moves the products and subcategories of category to
+// target. Products already in target just lose category.
+func reassignCategory(ctx context.Context, db *mongo.Database, category, target models.Category) (int64, error) {
+	products := db.Collection("products")
+
+	// replace in place so the product keeps its category order
+	res, err := products.UpdateMany(ctx,
+		bson.M{
+			"categoryIds": bson.M{"$eq": category.ID, "$ne": target.ID},
+			"category":    bson.M{"$elemMatch": bson.M{"$eq": category.Name}},
+		},
+		bson.M{"$set": bson.M{"categoryIds.$[id]": target.ID, "category.$[name]": target.Name}},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+			bson.M{"id": category.ID},
+			bson.M{"name": category.Name},
+		}}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := products.UpdateMany(ctx,
+		bson.M{"categoryIds": category.ID},
+		bson.M{"$pull": bson.M{"categoryIds": category.ID, "category": category.Name}},
+	); err != nil {
+		return 0, err
+	}
+
+	if _, err := db.Collection("categories").UpdateMany(ctx,
+		bson.M{"parentId": category.ID},
+		bson.M{"$set": bson.M{"parentId": target.ID}},
+	); err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// cascadeCategories removes the given categories from every product and
+// soft-deletes the products left without a category. It returns the
+// deleted products so the caller can clean up after them like
+// DeleteProduct does.
+func cascadeCategories(ctx context.Context, db *mongo.Database, subtree []models.Category, now time.Time) (int64, []models.Product, error) {
+	ids := make([]primitive.ObjectID, len(subtree))
+	names := make([]string, len(subtree))
+	for i, category := range subtree {
+		ids[i], names[i] = category.ID, category.Name
+	}
+	products := db.Collection("products")
+
+	orphanFilter := bson.M{
+		"categoryIds": bson.M{"$in": ids, "$not": bson.M{"$elemMatch": bson.M{"$nin": ids}}},
+		"isDeleted":   bson.M{"$ne": true},
+	}
+	cursor, err := products.Find(ctx, orphanFilter)
+	if err != nil {
+		return 0, nil, err
+	}
+	orphans, err := decodeProducts(ctx, cursor)
+	cursor.Close(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(orphans) > 0 {
+		orphanIDs := make([]primitive.ObjectID, len(orphans))
+		for i, product := range orphans {
+			orphanIDs[i] = product.ID
+		}
+		if _, err := products.UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": orphanIDs}},
+			bson.M{"$set": bson.M{"isDeleted": true, "deletedAt": now, "isActive": false}},
+		); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	res, err := products.UpdateMany(ctx,
+		bson.M{"categoryIds": bson.M{"$in": ids}},
+		bson.M{"$pull": bson.M{"categoryIds": bson.M{"$in": ids}, "category": bson.M{"$in": names}}},
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.ModifiedCount, orphans, nil
+}
+
+// cleanUpDeletedProducts does what DeleteProduct does after the soft delete.
+func cleanUpDeletedProducts(ctx context.Context, db *mongo.Database, uploads storage.Storage, products []models.Product) {
+	for _, product := range products {
+		if err := detachVariants(ctx, db, product.ID); err != nil {
+			log.Printf("[CATEGORY] detach variants of %s failed: %v", product.ID.Hex(), err)
+		}
+		if product.ParentID != nil {
+			if err := ungroupIfEmpty(ctx, db, *product.ParentID); err != nil {
+				log.Printf("[CATEGORY] ungroup %s failed: %v", product.ParentID.Hex(), err)
+			}
+		}
+		deleteProductImageFiles(ctx, uploads, productGallery(product))
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+// categoryNode is a category with its children, for GET /categories/tree.
+type categoryNode struct {
+	models.Category
+	Children []*categoryNode `json:"children"`
+}
+
+var (
+	errCategoryCycle   = errors.New("category cannot be moved under itself or its subcategories")
+	errParentNotFound  = errors.New("parent category not found")
+	errInvalidParentID = errors.New("invalid parentId")
+	categorySortOrder  = bson.D{{Key: "order", Value: 1}, {Key: "name", Value: 1}}
+)
+
+func loadCategories(ctx context.Context, db *mongo.Database, filter bson.M) ([]models.Category, error) {
+	cursor, err := db.Collection("categories").Find(ctx, filter,
+		options.Find().
+			SetSort(categorySortOrder).
+			SetCollation(&options.Collation{Locale: "tr"}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []models.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// buildCategoryTree nests categories under their parents, keeping the input
+// order among siblings. Categories whose parent is not in the list (e.g. an
+// inactive parent) are dropped together with their subtree.
+func buildCategoryTree(categories []models.Category) []*categoryNode {
+	nodes := make(map[primitive.ObjectID]*categoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &categoryNode{Category: category, Children: []*categoryNode{}}
+	}
+
+	roots := []*categoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots
+}
+
+// categoryDescendants returns the given categories and everything below
+// them.
+func categoryDescendants(categories []models.Category, roots []primitive.ObjectID) []models.Category {
+	children := map[primitive.ObjectID][]models.Category{}
+	byID := make(map[primitive.ObjectID]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var out []models.Category
+	seen := map[primitive.ObjectID]bool{}
+	queue := append([]primitive.ObjectID(nil), roots...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		category, ok := byID[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, category)
+		for _, child := range children[id] {
+			queue = append(queue, child.ID)
+		}
+	}
+	return out
+}
+
+// checkCategoryParent verifies that parentID exists and is not id itself or
+// one of its descendants. id is zero for a new category.
+func checkCategoryParent(categories []models.Category, id, parentID primitive.ObjectID) error {
+	parentOf := make(map[primitive.ObjectID]*primitive.ObjectID, len(categories))
+	for _, category := range categories {
+		parentOf[category.ID] = category.ParentID
+	}
+	if _, ok := parentOf[parentID]; !ok {
+		return errParentNotFound
+	}
+	for current := &parentID; current != nil; current = parentOf[*current] {
+		if !id.IsZero() && *current == id {
+			return errCategoryCycle
+		}
+	}
+	return nil
+}
+
+// expandCategoryFilter resolves ?category= values, given as IDs, names or
+// slugs, to the IDs and names of those categories and all their
+// subcategories. The names match products not yet linked by categoryIds.
+// Unknown values resolve to nothing, so they match no product.
+func expandCategoryFilter(ctx context.Context, db *mongo.Database, values []string) ([]primitive.ObjectID, []string, error) {
+	if len(values) == 0 {
+		return nil, nil, nil
+	}
+	categories, err := loadCategories(ctx, db, bson.M{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var roots []primitive.ObjectID
+	for _, value := range values {
+		for _, category := range categories {
+			if category.ID.Hex() == value || category.Name == value || (category.Slug != "" && category.Slug == value) {
+				roots = append(roots, category.ID)
+			}
+		}
+	}
+
+	ids, names := []primitive.ObjectID{}, []string{}
+	for _, category := range categoryDescendants(categories, roots) {
+		ids = append(ids, category.ID)
+		names = append(names, category.Name)
+	}
+	return ids, names, nil
+}
+
+// uniqueCategorySlug returns base, or base-2, base-3... if another category
+// already uses it.
+func uniqueCategorySlug(ctx context.Context, db *mongo.Database, base string, exclude primitive.ObjectID) (string, error) {
+	if base == "" {
+		base = "kategori"
+	}
+	filter := bson.M{"slug": bson.M{"$regex": "^" + regexp.QuoteMeta(base) + `(-\d+)?$`}}
+	if !exclude.IsZero() {
+		filter["_id"] = bson.M{"$ne": exclude}
+	}
+	cursor, err := db.Collection("categories").Find(ctx, filter, options.Find().SetProjection(bson.M{"slug": 1}))
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close(ctx)
+
+	var taken []struct {
+		Slug string `bson:"slug"`
+	}
+	if err := cursor.All(ctx, &taken); err != nil {
+		return "", err
+	}
+	used := make(map[string]bool, len(taken))
+	for _, row := range taken {
+		used[row.Slug] = true
+	}
+	return search.UniqueSlug(base, func(slug string) bool { return used[slug] }), nil
+}
+
+// categorySlug is the slug for a requested slug or, if empty, for name.
+func categorySlug(requested, name string) string {
+	if slug := search.Slug(requested); slug != "" {
+		return slug
+	}
+	return search.Slug(name)
+}
+
+/*
+GET /categories/tree
+- Aktif kategoriler iç içe (children) döner
+- Kardeşler "order", sonra isim sırasıyla
+- Pasif bir kategorinin alt kategorileri de listelenmez
+*/
+func GetCategoryTree(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /categories/tree"
+		defer handlePanic(c, route)
+
+		if err := ensureDBConnection(c.Request.Context(), db); err != nil {
+			respondWithError(c, http.StatusServiceUnavailable, route, "database unavailable")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		categories, err := loadCategories(ctx, db, bson.M{"isActive": true})
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		tree := buildCategoryTree(categories)
+		log.Printf("[%s] returning %d categories", route, len(categories))
+		c.JSON(http.StatusOK, tree)
+	}
+}
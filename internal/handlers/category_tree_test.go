@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func testCategories() (map[string]primitive.ObjectID, []models.Category) {
+	ids := map[string]primitive.ObjectID{}
+	for _, name := range []string{"gida", "sut", "peynir", "icecek", "gazli"} {
+		ids[name] = primitive.NewObjectID()
+	}
+	parent := func(name string) *primitive.ObjectID {
+		id := ids[name]
+		return &id
+	}
+	// already sorted by order, as loadCategories returns them
+	return ids, []models.Category{
+		{ID: ids["icecek"], Name: "İçecek", Order: 0},
+		{ID: ids["gida"], Name: "Gıda", Order: 1},
+		{ID: ids["peynir"], Name: "Peynir", ParentID: parent("sut"), Order: 0},
+		{ID: ids["sut"], Name: "Süt Ürünleri", ParentID: parent("gida"), Order: 0},
+		{ID: ids["gazli"], Name: "Gazlı İçecek", ParentID: parent("icecek"), Order: 0},
+	}
+}
+
+func TestBuildCategoryTreeNestsAndDropsOrphans(t *testing.T) {
+	ids, categories := testCategories()
+
+	tree := buildCategoryTree(categories)
+	if len(tree) != 2 || tree[0].Name != "İçecek" || tree[1].Name != "Gıda" {
+		t.Fatalf("unexpected roots: %+v", tree)
+	}
+	sut := tree[1].Children
+	if len(sut) != 1 || sut[0].ID != ids["sut"] || len(sut[0].Children) != 1 || sut[0].Children[0].Name != "Peynir" {
+		t.Fatalf("unexpected subtree: %+v", sut)
+	}
+
+	// an inactive parent hides its whole subtree
+	active := []models.Category{}
+	for _, category := range categories {
+		if category.ID != ids["gida"] {
+			active = append(active, category)
+		}
+	}
+	if tree := buildCategoryTree(active); len(tree) != 1 || tree[0].Name != "İçecek" {
+		t.Fatalf("orphaned subtree must be dropped: %+v", tree)
+	}
+}
+
+func TestCategoryDescendantsAndParentChecks(t *testing.T) {
+	ids, categories := testCategories()
+
+	names := map[string]bool{}
+	for _, category := range categoryDescendants(categories, []primitive.ObjectID{ids["gida"]}) {
+		names[category.Name] = true
+	}
+	if len(names) != 3 || !names["Gıda"] || !names["Süt Ürünleri"] || !names["Peynir"] {
+		t.Fatalf("unexpected descendants: %v", names)
+	}
+
+	if err := checkCategoryParent(categories, ids["gida"], ids["peynir"]); err != errCategoryCycle {
+		t.Fatalf("moving under a descendant must fail, got %v", err)
+	}
+	if err := checkCategoryParent(categories, ids["gida"], ids["gida"]); err != errCategoryCycle {
+		t.Fatalf("moving under itself must fail, got %v", err)
+	}
+	if err := checkCategoryParent(categories, ids["peynir"], ids["icecek"]); err != nil {
+		t.Fatalf("valid move rejected: %v", err)
+	}
+	if err := checkCategoryParent(categories, primitive.NilObjectID, primitive.NewObjectID()); err != errParentNotFound {
+		t.Fatalf("unknown parent must fail, got %v", err)
+	}
+}
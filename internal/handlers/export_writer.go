@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+const exportFlushEvery = 500
+
+// exportQuantity marks a stock or order quantity cell. Plain float64 cells are
+// money and get two decimals; quantities keep only the decimals they have.
+type exportQuantity float64
+
+// tableExporter streams rows to the response so exports never hold the whole
+// result set in memory. Cells may be string, int, float64, exportQuantity or
+// bool.
+type tableExporter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// newTableExporter writes the response headers and the header row for the
+// given format ("csv" or "xlsx").
+func newTableExporter(c *gin.Context, format, filename string, header []string) (tableExporter, error) {
+	headerRow := make([]interface{}, len(header))
+	for i, column := range header {
+		headerRow[i] = column
+	}
+
+	var exporter tableExporter
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Status(http.StatusOK)
+		if _, err := c.Writer.Write([]byte("\xEF\xBB\xBF")); err != nil {
+			return nil, err
+		}
+		exporter = &csvExporter{c: c, writer: csv.NewWriter(c.Writer)}
+	case "xlsx":
+		// excelize's stream writer spills to a temp file past its buffer size,
+		// so large sheets stay out of memory until they are copied out.
+		file := excelize.NewFile()
+		stream, err := file.NewStreamWriter("Sheet1")
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		exporter = &xlsxExporter{c: c, file: file, stream: stream}
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	if err := exporter.WriteRow(headerRow); err != nil {
+		return nil, err
+	}
+	return exporter, nil
+}
+
+type csvExporter struct {
+	c      *gin.Context
+	writer *csv.Writer
+	rows   int
+}
+
+func (e *csvExporter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if text, ok := value.(string); ok {
+			record[i] = escapeCSVFormula(text)
+			continue
+		}
+		record[i] = formatExportCell(value)
+	}
+	if err := e.writer.Write(record); err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%exportFlushEvery == 0 {
+		e.writer.Flush()
+		e.c.Writer.Flush()
+	}
+	return e.writer.Error()
+}
+
+func (e *csvExporter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type xlsxExporter struct {
+	c      *gin.Context
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rows   int
+}
+
+func (e *xlsxExporter) WriteRow(values []interface{}) error {
+	e.rows++
+	cell, err := excelize.CoordinatesToCellName(1, e.rows)
+	if err != nil {
+		return err
+	}
+	for i, value := range values {
+		if quantity, ok := value.(exportQuantity); ok {
+			values[i] = float64(quantity)
+		}
+	}
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExporter) Close() error {
+	defer e.file.Close()
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	e.c.Status(http.StatusOK)
+	return e.file.Write(e.c.Writer)
+}
+
+// csvFormulaPrefixes start a formula when a spreadsheet opens the CSV.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVFormula keeps text cells such as customer names and addresses from
+// running as formulas in Excel by prefixing them with a quote. Only text is
+// escaped; numbers are written as numbers.
+func escapeCSVFormula(text string) string {
+	if text != "" && strings.ContainsRune(csvFormulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func formatExportCell(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case int:
+		return strconv.Itoa(typed)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case float64:
+		return formatMoney(typed)
+	case exportQuantity:
+		return strconv.FormatFloat(roundQuantity(float64(typed)), 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typed)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+// parseExportFormat validates ?format against the formats an endpoint offers;
+// the first allowed format is the default.
+func parseExportFormat(c *gin.Context, allowed ...string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	if format == "" {
+		return allowed[0], nil
+	}
+	for _, candidate := range allowed {
+		if format == candidate {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("format must be one of %s", strings.Join(allowed, ", "))
+}
+
+func exportFilename(prefix string, now time.Time) string {
+	return fmt.Sprintf("%s-%s", prefix, now.Format("20060102-1504"))
+}
+
+// exportLocation is the timezone dates are written in; the tzdata embedded in
+// main makes the lookup work in minimal containers too.
+func exportLocation() *time.Location {
+	loc, err := time.LoadLocation(defaultReportTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSVExportEscapesFormulaText(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	exporter, err := newTableExporter(c, "csv", "test", []string{"name", "phone", "quantity", "total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.WriteRow([]interface{}{`=HYPERLINK("http://x")`, "+905551234567", exportQuantity(-2), -12.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.WriteRow([]interface{}{"@SUM(A1)", "\tTab", "-", "Ayşe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(recorder.Body.String(), "\xEF\xBB\xBF"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "phone", "quantity", "total"},
+		{`'=HYPERLINK("http://x")`, "'+905551234567", "-2", "-12.50"},
+		{"'@SUM(A1)", "'\tTab", "'-", "Ayşe"},
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	columns := map[string]int{"name": 0}
+	if got := importCell(records[1], columns, "name"); got != `=HYPERLINK("http://x")` {
+		t.Fatalf("import must undo the export quote, got %q", got)
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/auth"
+)
+
+/*
+GET /.well-known/jwks.json
+- Diğer servislerin access token doğrulaması için public key listesi
+*/
+func JWKS(tokens *auth.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, tokens.JWKS())
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestOrderExportRowsOnePerItem(t *testing.T) {
+	userID := primitive.NewObjectID()
+	order := adminOrderResponse{
+		ID:     primitive.NewObjectID(),
+		UserID: &userID,
+		Items: []models.OrderItem{
+			{ProductID: primitive.NewObjectID(), Name: "Süt", Price: 34.9, Quantity: 2},
+			{ProductID: primitive.NewObjectID(), Name: "Ekmek", Price: 10, Quantity: 1},
+		},
+		TotalPrice:    79.8,
+		Customer:      models.OrderCustomer{Title: "Ev", Detail: "Atatürk Cd. No:1"},
+		PaymentMethod: "cash",
+		Status:        "approved",
+		CreatedAt:     time.Date(2025, 5, 1, 21, 30, 0, 0, time.UTC),
+	}
+	users := map[primitive.ObjectID]adminOrderUserRecord{
+		userID: {ID: userID, Name: "Ayşe Yılmaz", Phone: "+905551234567"},
+	}
+	loc, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	rows := orderExportRows(order, users, loc)
+	if len(rows) != 2 {
+		t.Fatalf("expected one row per item, got %d", len(rows))
+	}
+	for _, row := range rows {
+		if len(row) != len(orderExportHeader) {
+			t.Fatalf("row has %d cells, header has %d", len(row), len(orderExportHeader))
+		}
+	}
+
+	first := map[string]string{}
+	for i, column := range orderExportHeader {
+		first[column] = formatExportCell(rows[0][i])
+	}
+	if first["orderCode"] != buildOrderCode(order.ID) || first["date"] != "2025-05-02 00:30" {
+		t.Fatalf("unexpected order columns %v", first)
+	}
+	if first["customerName"] != "Ayşe Yılmaz" || first["customerPhone"] != "+905551234567" || first["address"] != "Atatürk Cd. No:1" {
+		t.Fatalf("unexpected customer columns %v", first)
+	}
+	if first["quantity"] != "2" || first["unitPrice"] != "34.90" || first["lineTotal"] != "69.80" {
+		t.Fatalf("unexpected line columns %v", first)
+	}
+}
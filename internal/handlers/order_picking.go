@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+const (
+	uncategorizedPickingGroup = "Kategorisiz"
+	maxPickingOrders          = 500
+)
+
+type pickingOrderRef struct {
+	OrderID   string  `json:"orderId"`
+	OrderCode string  `json:"orderCode"`
+	Quantity  float64 `json:"quantity"`
+}
+
+type pickingItem struct {
+	ProductID string            `json:"productId"`
+	Name      string            `json:"name"`
+	Barcode   string            `json:"barcode,omitempty"`
+	Unit      string            `json:"unit,omitempty"`
+	Quantity  float64           `json:"quantity"`
+	Orders    []pickingOrderRef `json:"orders"`
+}
+
+type pickingGroup struct {
+	Category string        `json:"category"`
+	Quantity float64       `json:"quantity"`
+	Items    []pickingItem `json:"items"`
+}
+
+type pickingList struct {
+	OrderCount int            `json:"orderCount"`
+	OrderCodes []string       `json:"orderCodes"`
+	Quantity   float64        `json:"quantity"`
+	Groups     []pickingGroup `json:"groups"`
+}
+
+// pickingProduct is the shelf information looked up for each ordered product.
+type pickingProduct struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Barcode  string             `bson:"barcode"`
+	Category interface{}        `bson:"category"`
+}
+
+/*
+GET /admin/api/orders/picking-list
+- Onaylanmış siparişlerin kalemleri kategoriye (reyona) göre gruplanır, ürün başına toplam adet
+- ?orderIds=id1,id2 → yalnızca seçilen siparişler (yine onaylı olanlar)
+*/
+func AdminPickingList(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/orders/picking-list"
+
+		filter := bson.M{"status": "approved"}
+		if raw := strings.TrimSpace(c.Query("orderIds")); raw != "" {
+			ids := make([]primitive.ObjectID, 0)
+			for _, part := range strings.Split(raw, ",") {
+				id, err := primitive.ObjectIDFromHex(strings.TrimSpace(part))
+				if err != nil {
+					respondWithError(c, http.StatusBadRequest, route, "invalid orderIds")
+					return
+				}
+				ids = append(ids, id)
+			}
+			filter["_id"] = bson.M{"$in": ids}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		cursor, err := db.Collection("orders").Find(ctx, filter,
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}).SetLimit(maxPickingOrders),
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var orders []adminOrderResponse
+		if err := cursor.All(ctx, &orders); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "decode error")
+			return
+		}
+		enrichAdminOrders(orders)
+
+		products, err := loadPickingProducts(ctx, db, orders)
+		if err != nil {
+			log.Printf("[%s] product lookup failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, buildPickingList(orders, products))
+	}
+}
+
+func loadPickingProducts(ctx context.Context, db *mongo.Database, orders []adminOrderResponse) (map[primitive.ObjectID]pickingProduct, error) {
+	idSet := map[primitive.ObjectID]struct{}{}
+	ids := make([]primitive.ObjectID, 0)
+	for _, order := range orders {
+		for _, item := range order.Items {
+			if _, exists := idSet[item.ProductID]; exists {
+				continue
+			}
+			idSet[item.ProductID] = struct{}{}
+			ids = append(ids, item.ProductID)
+		}
+	}
+
+	products := make(map[primitive.ObjectID]pickingProduct, len(ids))
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	cursor, err := db.Collection("products").Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"barcode": 1, "category": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []pickingProduct
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		products[record.ID] = record
+	}
+	return products, nil
+}
+
+// pickingCategory is the first category of the product, which is the aisle
+// staff walk to. Old documents may store category as a plain string.
+func pickingCategory(product pickingProduct) string {
+	switch typed := product.Category.(type) {
+	case string:
+		if strings.TrimSpace(typed) != "" {
+			return strings.TrimSpace(typed)
+		}
+	case primitive.A:
+		for _, value := range typed {
+			if name, ok := value.(string); ok && strings.TrimSpace(name) != "" {
+				return strings.TrimSpace(name)
+			}
+		}
+	}
+	return uncategorizedPickingGroup
+}
+
+// buildPickingList groups order lines by category and product. Groups and
+// items are sorted by Turkish collation so the printed list follows the shelves.
+func buildPickingList(orders []adminOrderResponse, products map[primitive.ObjectID]pickingProduct) pickingList {
+	list := pickingList{
+		OrderCount: len(orders),
+		OrderCodes: make([]string, 0, len(orders)),
+		Groups:     []pickingGroup{},
+	}
+
+	groupIndex := map[string]int{}
+	itemIndex := map[string]map[primitive.ObjectID]int{}
+
+	for _, order := range orders {
+		list.OrderCodes = append(list.OrderCodes, order.OrderCode)
+		for _, item := range order.Items {
+			product, known := products[item.ProductID]
+			category := uncategorizedPickingGroup
+			if known {
+				category = pickingCategory(product)
+			}
+
+			gi, ok := groupIndex[category]
+			if !ok {
+				gi = len(list.Groups)
+				groupIndex[category] = gi
+				itemIndex[category] = map[primitive.ObjectID]int{}
+				list.Groups = append(list.Groups, pickingGroup{Category: category, Items: []pickingItem{}})
+			}
+			group := &list.Groups[gi]
+
+			ii, ok := itemIndex[category][item.ProductID]
+			if !ok {
+				ii = len(group.Items)
+				itemIndex[category][item.ProductID] = ii
+				group.Items = append(group.Items, pickingItem{
+					ProductID: item.ProductID.Hex(),
+					Name:      item.Name,
+					Barcode:   product.Barcode,
+					Unit:      item.Unit,
+					Orders:    []pickingOrderRef{},
+				})
+			}
+			line := &group.Items[ii]
+			line.Quantity = roundQuantity(line.Quantity + item.Quantity)
+			line.Orders = append(line.Orders, pickingOrderRef{
+				OrderID:   order.ID.Hex(),
+				OrderCode: order.OrderCode,
+				Quantity:  item.Quantity,
+			})
+
+			group.Quantity = roundQuantity(group.Quantity + item.Quantity)
+			list.Quantity = roundQuantity(list.Quantity + item.Quantity)
+		}
+	}
+
+	collator := collate.New(language.Turkish, collate.IgnoreCase)
+	sort.SliceStable(list.Groups, func(i, j int) bool {
+		if (list.Groups[i].Category == uncategorizedPickingGroup) != (list.Groups[j].Category == uncategorizedPickingGroup) {
+			return list.Groups[j].Category == uncategorizedPickingGroup
+		}
+		return collator.CompareString(list.Groups[i].Category, list.Groups[j].Category) < 0
+	})
+	for i := range list.Groups {
+		items := list.Groups[i].Items
+		sort.SliceStable(items, func(a, b int) bool { return collator.CompareString(items[a].Name, items[b].Name) < 0 })
+	}
+
+	return list
+}
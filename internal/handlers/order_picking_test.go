@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestBuildPickingListGroupsByCategory(t *testing.T) {
+	milk, bread, unknown := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	products := map[primitive.ObjectID]pickingProduct{
+		milk:  {ID: milk, Barcode: "869001", Category: primitive.A{"Süt Ürünleri", "Kahvaltılık"}},
+		bread: {ID: bread, Category: "Ekmek"},
+	}
+	orders := []adminOrderResponse{
+		{ID: primitive.NewObjectID(), OrderCode: "A1", Items: []models.OrderItem{
+			{ProductID: milk, Name: "Süt", Quantity: 2},
+			{ProductID: bread, Name: "Ekmek", Quantity: 1},
+		}},
+		{ID: primitive.NewObjectID(), OrderCode: "B2", Items: []models.OrderItem{
+			{ProductID: milk, Name: "Süt", Quantity: 3},
+			{ProductID: unknown, Name: "Silinmiş ürün", Quantity: 1},
+		}},
+	}
+
+	list := buildPickingList(orders, products)
+	if list.OrderCount != 2 || list.Quantity != 7 {
+		t.Fatalf("unexpected totals %+v", list)
+	}
+	if len(list.Groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(list.Groups))
+	}
+	if list.Groups[0].Category != "Ekmek" || list.Groups[1].Category != "Süt Ürünleri" || list.Groups[2].Category != uncategorizedPickingGroup {
+		t.Fatalf("unexpected group order %q %q %q", list.Groups[0].Category, list.Groups[1].Category, list.Groups[2].Category)
+	}
+
+	milkLine := list.Groups[1].Items[0]
+	if milkLine.Quantity != 5 || milkLine.Barcode != "869001" || len(milkLine.Orders) != 2 {
+		t.Fatalf("unexpected milk line %+v", milkLine)
+	}
+}
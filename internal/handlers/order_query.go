@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"math"
 	"net/http"
 	"strings"
@@ -70,6 +71,11 @@ func AdminGetOrders(db *mongo.Database) gin.HandlerFunc {
 			respondWithError(c, http.StatusBadRequest, "GET /admin/api/orders", err.Error())
 			return
 		}
+		cursorMode, after, err := parseCursorParam(c.Request.URL.Query(), "")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, "GET /admin/api/orders", err.Error())
+			return
+		}
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
@@ -80,6 +86,40 @@ func AdminGetOrders(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		if cursorMode {
+			filter, err = withCursor(filter, newestFirst, after)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, "GET /admin/api/orders", err.Error())
+				return
+			}
+
+			cursor, err := db.Collection("orders").Find(ctx, filter,
+				options.Find().SetSort(newestFirst).SetLimit(limit+1))
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, "GET /admin/api/orders", "db error")
+				return
+			}
+			defer cursor.Close(ctx)
+
+			var orders []adminOrderResponse
+			if err := cursor.All(ctx, &orders); err != nil {
+				respondWithError(c, http.StatusInternalServerError, "GET /admin/api/orders", "decode error")
+				return
+			}
+			orders, next := trimCursorPage(orders, limit, "", func(o adminOrderResponse) (bson.A, primitive.ObjectID) {
+				return bson.A{o.CreatedAt}, o.ID
+			})
+
+			attachUserPhones(ctx, db, orders)
+			enrichAdminOrders(orders)
+
+			c.JSON(http.StatusOK, gin.H{
+				"data":       orders,
+				"pagination": cursorPagination(limit, next),
+			})
+			return
+		}
+
 		total, err := db.Collection("orders").CountDocuments(ctx, filter)
 		if err != nil {
 			respondWithError(c, http.StatusInternalServerError, "GET /admin/api/orders", "db error")
@@ -87,7 +127,7 @@ func AdminGetOrders(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		opts := options.Find().
-			SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+			SetSort(newestFirst).
 			SetSkip((page - 1) * limit).
 			SetLimit(limit)
 
@@ -179,14 +219,28 @@ func AdminUpdateOrderStatus(db *mongo.Database) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		update := bson.M{"$set": bson.M{"status": status, "updatedAt": now}}
-		res, err := db.Collection("orders").UpdateOne(ctx, bson.M{"_id": orderID}, update)
-		if err != nil {
+		var current models.Order
+		if err := db.Collection("orders").FindOne(ctx, bson.M{"_id": orderID}).Decode(&current); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				respondWithError(c, http.StatusNotFound, "PUT /admin/api/orders/:id/status", "order not found")
+				return
+			}
 			respondWithError(c, http.StatusInternalServerError, "PUT /admin/api/orders/:id/status", "db error")
 			return
 		}
-		if res.MatchedCount == 0 {
-			respondWithError(c, http.StatusNotFound, "PUT /admin/api/orders/:id/status", "order not found")
+
+		err = changeOrderStatus(ctx, db, current, status, now, stockActorFromContext(c))
+		if errors.Is(err, errOrderStatusChanged) {
+			respondWithError(c, http.StatusConflict, "PUT /admin/api/orders/:id/status", "order status changed, retry")
+			return
+		}
+		if errors.Is(err, errStockUnavailable) {
+			respondWithError(c, http.StatusConflict, "PUT /admin/api/orders/:id/status", "Stok yetersiz")
+			return
+		}
+		if err != nil {
+			log.Printf("[PUT /admin/api/orders/:id/status] update failed: %v", err)
+			respondWithError(c, http.StatusInternalServerError, "PUT /admin/api/orders/:id/status", "db error")
 			return
 		}
 
@@ -441,11 +495,47 @@ func GetMyOrders(db *mongo.Database) gin.HandlerFunc {
 			respondWithError(c, http.StatusBadRequest, "GET /user/orders", err.Error())
 			return
 		}
+		cursorMode, after, err := parseCursorParam(c.Request.URL.Query(), "")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, "GET /user/orders", err.Error())
+			return
+		}
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
 		filter := bson.M{"userId": userID}
+		if cursorMode {
+			filter, err = withCursor(filter, newestFirst, after)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, "GET /user/orders", err.Error())
+				return
+			}
+
+			cursor, err := db.Collection("orders").Find(ctx, filter,
+				options.Find().SetSort(newestFirst).SetLimit(limit+1))
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, "GET /user/orders", "db error")
+				return
+			}
+			defer cursor.Close(ctx)
+
+			var orders []models.Order
+			if err := cursor.All(ctx, &orders); err != nil {
+				respondWithError(c, http.StatusInternalServerError, "GET /user/orders", "decode error")
+				return
+			}
+			orders, next := trimCursorPage(orders, limit, "", func(o models.Order) (bson.A, primitive.ObjectID) {
+				return bson.A{o.CreatedAt}, o.ID
+			})
+
+			c.JSON(http.StatusOK, gin.H{
+				"data":       orders,
+				"pagination": cursorPagination(limit, next),
+			})
+			return
+		}
+
 		total, err := db.Collection("orders").CountDocuments(ctx, filter)
 		if err != nil {
 			respondWithError(c, http.StatusInternalServerError, "GET /user/orders", "db error")
@@ -453,7 +543,7 @@ func GetMyOrders(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		opts := options.Find().
-			SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+			SetSort(newestFirst).
 			SetSkip((page - 1) * limit).
 			SetLimit(limit)
 
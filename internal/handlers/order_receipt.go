@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"backend/internal/models"
+)
+
+// Labels match the admin orders page (public/admin/orders.js).
+var orderStatusLabels = map[string]string{
+	"pending":   "Beklemede",
+	"approved":  "Onaylandı",
+	"cancelled": "İptal Edildi",
+	"delivered": "Teslim Edildi",
+}
+
+var paymentMethodLabels = map[string]string{
+	"cash": "Nakit",
+	"card": "Kart",
+}
+
+/*
+GET /admin/api/orders/:id/receipt.pdf
+- Siparişin PDF fişi (mağaza bilgileri settings'ten)
+*/
+func AdminOrderReceipt(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serveOrderReceipt(c, db, "GET /admin/api/orders/:id/receipt.pdf", nil)
+	}
+}
+
+/*
+GET /user/orders/:id/receipt.pdf
+- Yalnızca kullanıcının kendi siparişi
+*/
+func UserOrderReceipt(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /user/orders/:id/receipt.pdf"
+
+		userIDValue, exists := c.Get("userId")
+		if !exists {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		userID, ok := userIDValue.(primitive.ObjectID)
+		if !ok {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		serveOrderReceipt(c, db, route, &userID)
+	}
+}
+
+// serveOrderReceipt renders the receipt; ownerID restricts it to one user's
+// orders and answers 404 for others so order IDs cannot be probed.
+func serveOrderReceipt(c *gin.Context, db *mongo.Database, route string, ownerID *primitive.ObjectID) {
+	orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, route, "invalid id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": orderID}
+	if ownerID != nil {
+		filter["userId"] = *ownerID
+	}
+
+	var order adminOrderResponse
+	if err := db.Collection("orders").FindOne(ctx, filter).Decode(&order); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "order not found")
+			return
+		}
+		respondWithError(c, http.StatusInternalServerError, route, "db error")
+		return
+	}
+
+	orders := []adminOrderResponse{order}
+	attachUserPhones(ctx, db, orders)
+	attachAddresses(ctx, db, orders)
+	enrichAdminOrders(orders)
+
+	store, err := loadStoreSettings(ctx, db)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, route, "db error")
+		return
+	}
+
+	pdf, err := renderOrderReceipt(orders[0], store, exportLocation())
+	if err != nil {
+		log.Printf("[%s] render failed: %v", route, err)
+		respondWithError(c, http.StatusInternalServerError, route, "receipt could not be generated")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="fis-%s.pdf"`, orders[0].OrderCode))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// receiptTotals splits the charged total into list price and sale discount.
+type receiptTotals struct {
+	Subtotal float64
+	Discount float64
+	Total    float64
+}
+
+func computeReceiptTotals(order adminOrderResponse) receiptTotals {
+	var totals receiptTotals
+	for _, item := range order.Items {
+		quantity := item.Quantity
+		totals.Subtotal += receiptListPrice(item) * quantity
+		totals.Discount += (receiptListPrice(item) - item.Price) * quantity
+	}
+	totals.Subtotal = roundMoney(totals.Subtotal)
+	totals.Discount = roundMoney(totals.Discount)
+	totals.Total = roundMoney(order.TotalPrice)
+	return totals
+}
+
+func receiptListPrice(item models.OrderItem) float64 {
+	if item.OriginalPrice > item.Price {
+		return item.OriginalPrice
+	}
+	return item.Price
+}
+
+// renderOrderReceipt draws an A4 receipt. The Go fonts are embedded as UTF-8
+// fonts because the PDF core fonts cannot encode ğ, ş, ı and İ.
+func renderOrderReceipt(order adminOrderResponse, store models.StoreSettings, loc *time.Location) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("Go", "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes("Go", "B", gobold.TTF)
+	pdf.SetTitle("Sipariş Fişi "+order.OrderCode, true)
+	pdf.SetAuthor(store.Name, true)
+	pdf.SetCreationDate(order.CreatedAt)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := pageWidth - left - right
+
+	// Store header
+	pdf.SetFont("Go", "B", 16)
+	pdf.CellFormat(width, 8, store.Name, "", 1, "C", false, 0, "")
+	pdf.SetFont("Go", "", 9)
+	for _, line := range storeHeaderLines(store) {
+		pdf.CellFormat(width, 4.5, line, "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Order info
+	pdf.SetFont("Go", "B", 12)
+	pdf.CellFormat(width, 7, "SİPARİŞ FİŞİ", "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Go", "", 10)
+	infoRows := [][2]string{
+		{"Sipariş No", order.OrderCode},
+		{"Tarih", order.CreatedAt.In(loc).Format("02.01.2006 15:04")},
+		{"Ödeme", labelOr(paymentMethodLabels, order.PaymentMethod)},
+		{"Durum", labelOr(orderStatusLabels, order.Status)},
+	}
+	for _, row := range infoRows {
+		pdf.SetFont("Go", "B", 10)
+		pdf.CellFormat(30, 5.5, row[0]+":", "", 0, "L", false, 0, "")
+		pdf.SetFont("Go", "", 10)
+		pdf.CellFormat(width-30, 5.5, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	// Delivery address
+	if order.Address != nil {
+		pdf.SetFont("Go", "B", 10)
+		pdf.CellFormat(width, 6, "Teslimat Bilgileri", "", 1, "L", false, 0, "")
+		pdf.SetFont("Go", "", 10)
+		for _, line := range receiptAddressLines(order.Address) {
+			pdf.MultiCell(width, 5, line, "", "L", false)
+		}
+		pdf.Ln(3)
+	}
+
+	// Items
+	columns := []struct {
+		title string
+		width float64
+		align string
+	}{
+		{"Ürün", width - 105, "L"},
+		{"Adet", 15, "R"},
+		{"Birim Fiyat", 30, "R"},
+		{"İndirim", 30, "R"},
+		{"Tutar", 30, "R"},
+	}
+	pdf.SetFont("Go", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	for _, column := range columns {
+		pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Go", "", 10)
+	for _, item := range order.Items {
+		listPrice := receiptListPrice(item)
+		discount := (listPrice - item.Price) * item.Quantity
+		discountText := ""
+		if discount > 0 {
+			discountText = "-" + formatTRY(discount)
+		}
+		cells := []string{
+			item.Name,
+			formatQuantity(item.Quantity, item.Unit),
+			formatTRY(listPrice),
+			discountText,
+			formatTRY(item.Price * item.Quantity),
+		}
+		for i, column := range columns {
+			text := cells[i]
+			if i == 0 {
+				text = truncateToWidth(pdf, text, column.width-2)
+			}
+			pdf.CellFormat(column.width, 6.5, text, "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	// Totals
+	totals := computeReceiptTotals(order)
+	pdf.Ln(2)
+	totalRows := [][2]string{{"Ara Toplam", formatTRY(totals.Subtotal)}}
+	if totals.Discount > 0 {
+		totalRows = append(totalRows, [2]string{"İndirim", "-" + formatTRY(totals.Discount)})
+	}
+	totalRows = append(totalRows, [2]string{"GENEL TOPLAM", formatTRY(totals.Total)})
+	for i, row := range totalRows {
+		style := ""
+		if i == len(totalRows)-1 {
+			style = "B"
+		}
+		pdf.SetFont("Go", style, 10)
+		pdf.CellFormat(width-40, 6, row[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 6, row[1], "", 1, "R", false, 0, "")
+	}
+
+	if store.ReceiptFooter != "" {
+		pdf.Ln(8)
+		pdf.SetFont("Go", "", 9)
+		pdf.MultiCell(width, 5, store.ReceiptFooter, "", "C", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func storeHeaderLines(store models.StoreSettings) []string {
+	lines := make([]string, 0, 4)
+	if store.Address != "" {
+		lines = append(lines, store.Address)
+	}
+	contact := make([]string, 0, 3)
+	for _, value := range []string{store.Phone, store.Email, store.Website} {
+		if value != "" {
+			contact = append(contact, value)
+		}
+	}
+	if len(contact) > 0 {
+		lines = append(lines, strings.Join(contact, " · "))
+	}
+	if store.TaxOffice != "" || store.TaxNumber != "" {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("Vergi Dairesi: %s  VKN: %s", store.TaxOffice, store.TaxNumber)))
+	}
+	return lines
+}
+
+func receiptAddressLines(address *adminOrderAddress) []string {
+	lines := make([]string, 0, 4)
+	if name := strings.TrimSpace(strings.Join([]string{address.Name, address.Phone}, "  ")); name != "" {
+		lines = append(lines, name)
+	}
+	if address.Title != "" {
+		lines = append(lines, address.Title)
+	}
+	if address.FullText != "" {
+		lines = append(lines, address.FullText)
+	}
+	if address.Note != "" {
+		lines = append(lines, "Not: "+address.Note)
+	}
+	return lines
+}
+
+func labelOr(labels map[string]string, key string) string {
+	if label, ok := labels[key]; ok {
+		return label
+	}
+	return key
+}
+
+// formatTRY writes an amount the Turkish way: 1.234,50 TL.
+func formatTRY(value float64) string {
+	negative := value < 0
+	cents := int64(math.Round(math.Abs(value) * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s,%02d TL", sign, grouped.String(), cents%100)
+}
+
+func truncateToWidth(pdf *fpdf.Fpdf, text string, maxWidth float64) string {
+	if pdf.GetStringWidth(text) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestFormatTRY(t *testing.T) {
+	cases := map[float64]string{
+		0:         "0,00 TL",
+		34.9:      "34,90 TL",
+		1234.5:    "1.234,50 TL",
+		1234567.8: "1.234.567,80 TL",
+		-12.345:   "-12,35 TL",
+	}
+	for value, want := range cases {
+		if got := formatTRY(value); got != want {
+			t.Fatalf("formatTRY(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestComputeReceiptTotalsSeparatesSaleDiscount(t *testing.T) {
+	order := adminOrderResponse{
+		Items: []models.OrderItem{
+			{Name: "Peynir", Price: 80, OriginalPrice: 100, Quantity: 2},
+			{Name: "Ekmek", Price: 10, Quantity: 3},
+		},
+		TotalPrice: 190,
+	}
+
+	totals := computeReceiptTotals(order)
+	if totals.Subtotal != 230 || totals.Discount != 40 || totals.Total != 190 {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+}
+
+func TestRenderOrderReceiptProducesPDF(t *testing.T) {
+	order := adminOrderResponse{
+		ID:        primitive.NewObjectID(),
+		OrderCode: "ABCDEF12",
+		Items: []models.OrderItem{
+			{Name: "Şekerli Çörek ığüşöçİ", Price: 45.5, OriginalPrice: 50, Quantity: 1},
+		},
+		TotalPrice:    45.5,
+		PaymentMethod: "cash",
+		Status:        "approved",
+		Address:       &adminOrderAddress{Name: "Gül Işık", FullText: "Çiçek Sk. No:3 Üsküdar"},
+		CreatedAt:     time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	pdf, err := renderOrderReceipt(order, defaultStoreSettings, time.UTC)
+	if err != nil {
+		t.Fatalf("renderOrderReceipt returned error: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("output is not a PDF")
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// Reorder statuses: the items can go straight into the cart, or the client
+// should show what changed first.
+const (
+	reorderStatusCart    = "cart"
+	reorderStatusPreview = "preview"
+)
+
+// Reasons an item of the old order cannot be bought again.
+const (
+	reorderNotFound        = "not_found"
+	reorderInactive        = "inactive"
+	reorderOutOfStock      = "out_of_stock"
+	reorderInvalidQuantity = "invalid_quantity"
+)
+
+type reorderUnavailableItem struct {
+	ProductID primitive.ObjectID `json:"productId"`
+	Name      string             `json:"name"`
+	Quantity  float64            `json:"quantity"`
+	Unit      string             `json:"unit,omitempty"`
+	Reason    string             `json:"reason"`
+	Available *float64           `json:"available,omitempty"`
+}
+
+type reorderPriceChange struct {
+	ProductID primitive.ObjectID `json:"productId"`
+	Name      string             `json:"name"`
+	OldPrice  float64            `json:"oldPrice"`
+	NewPrice  float64            `json:"newPrice"`
+}
+
+// reorderResponse is the cart rebuilt from a past order. Items are priced
+// as of now and can be sent to POST /orders as they are.
+type reorderResponse struct {
+	OrderID      primitive.ObjectID       `json:"orderId"`
+	Status       string                   `json:"status"`
+	Items        []models.OrderItem       `json:"items"`
+	TotalPrice   float64                  `json:"totalPrice"`
+	Unavailable  []reorderUnavailableItem `json:"unavailable"`
+	PriceChanges []reorderPriceChange     `json:"priceChanges"`
+}
+
+func newReorderResponse(orderID primitive.ObjectID) reorderResponse {
+	return reorderResponse{
+		OrderID:      orderID,
+		Items:        []models.OrderItem{},
+		Unavailable:  []reorderUnavailableItem{},
+		PriceChanges: []reorderPriceChange{},
+	}
+}
+
+// mergeOrderLines adds up repeated products so stock is checked against the
+// whole quantity, keeping the first line's name and price.
+func mergeOrderLines(items []models.OrderItem) []models.OrderItem {
+	lines := make([]models.OrderItem, 0, len(items))
+	index := map[primitive.ObjectID]int{}
+	for _, item := range items {
+		if item.ProductID.IsZero() || item.Quantity <= 0 {
+			continue
+		}
+		if i, ok := index[item.ProductID]; ok {
+			lines[i].Quantity = roundQuantity(lines[i].Quantity + item.Quantity)
+			continue
+		}
+		index[item.ProductID] = len(lines)
+		lines = append(lines, item)
+	}
+	return lines
+}
+
+// add puts the re-resolved item in the cart and notes a price change
+// against what was paid last time.
+func (r *reorderResponse) add(previous, current models.OrderItem) {
+	r.Items = append(r.Items, current)
+	if roundMoney(previous.Price) != roundMoney(current.Price) {
+		r.PriceChanges = append(r.PriceChanges, reorderPriceChange{
+			ProductID: current.ProductID,
+			Name:      current.Name,
+			OldPrice:  previous.Price,
+			NewPrice:  current.Price,
+		})
+	}
+}
+
+func (r *reorderResponse) skip(previous models.OrderItem, reason string, available *float64) {
+	r.Unavailable = append(r.Unavailable, reorderUnavailableItem{
+		ProductID: previous.ProductID,
+		Name:      previous.Name,
+		Quantity:  previous.Quantity,
+		Unit:      previous.Unit,
+		Reason:    reason,
+		Available: available,
+	})
+}
+
+func (r *reorderResponse) finish() {
+	total := 0.0
+	for _, item := range r.Items {
+		total += roundMoney(item.Price * item.Quantity)
+	}
+	r.TotalPrice = roundMoney(total)
+	r.Status = reorderStatusCart
+	if len(r.Unavailable) > 0 || len(r.PriceChanges) > 0 {
+		r.Status = reorderStatusPreview
+	}
+}
+
+// reorderFailure maps a resolveOrderItems error to an unavailable reason;
+// ok is false for errors that are not about the item, e.g. db errors.
+func reorderFailure(err error) (reason string, available *float64, ok bool) {
+	var stockErr outOfStockError
+	if errors.As(err, &stockErr) {
+		left := stockErr.Available
+		if left < 0 {
+			left = 0
+		}
+		return reorderOutOfStock, &left, true
+	}
+	var notFoundErr productNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return reorderNotFound, nil, true
+	}
+	var quantityErr invalidQuantityError
+	if errors.As(err, &quantityErr) {
+		return reorderInvalidQuantity, nil, true
+	}
+	return "", nil, false
+}
+
+// inactiveProductIDs returns which of the given products are hidden from the
+// storefront; resolveOrderItems does not check this.
+func inactiveProductIDs(ctx context.Context, db *mongo.Database, ids []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	cursor, err := db.Collection("products").Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "isActive": false},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	inactive := map[primitive.ObjectID]bool{}
+	for cursor.Next(ctx) {
+		var row struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		inactive[row.ID] = true
+	}
+	return inactive, cursor.Err()
+}
+
+// planReorder re-resolves every item of order at current prices and stock.
+func planReorder(ctx context.Context, db *mongo.Database, order models.Order) (reorderResponse, error) {
+	response := newReorderResponse(order.ID)
+	lines := mergeOrderLines(order.Items)
+
+	ids := make([]primitive.ObjectID, len(lines))
+	for i, line := range lines {
+		ids[i] = line.ProductID
+	}
+	inactive, err := inactiveProductIDs(ctx, db, ids)
+	if err != nil {
+		return response, err
+	}
+
+	for _, line := range lines {
+		if inactive[line.ProductID] {
+			response.skip(line, reorderInactive, nil)
+			continue
+		}
+		resolved, err := resolveOrderItems(ctx, db, []createOrderItemRequest{{
+			ProductID: line.ProductID.Hex(),
+			Quantity:  line.Quantity,
+		}})
+		if err != nil {
+			reason, available, ok := reorderFailure(err)
+			if !ok {
+				return response, err
+			}
+			response.skip(line, reason, available)
+			continue
+		}
+		response.add(line, resolved[0])
+	}
+
+	response.finish()
+	return response, nil
+}
+
+/*
+POST /user/orders/:id/reorder
+- Kullanıcının geçmiş siparişindeki ürünler güncel fiyat ve stokla yeniden çözülür
+- Sipariş oluşturmaz; sepete konacak kalemleri döner (POST /orders'a aynen gönderilebilir)
+- Her şey aynıysa status "cart"; eksik ürün ya da fiyat farkı varsa status "preview"
+- "unavailable": bulunamayan, pasif, stokta yetmeyen ürünler (reason, varsa available)
+- "priceChanges": son ödenen fiyattan farklı olanlar (oldPrice, newPrice)
+- Başka kullanıcının siparişi 404
+*/
+func ReorderMyOrder(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /user/orders/:id/reorder"
+		defer handlePanic(c, route)
+
+		userIDValue, exists := c.Get("userId")
+		if !exists {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+		userID, ok := userIDValue.(primitive.ObjectID)
+		if !ok {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		if err := ensureDBConnection(c.Request.Context(), db); err != nil {
+			respondWithError(c, http.StatusServiceUnavailable, route, "database unavailable")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var order models.Order
+		err = db.Collection("orders").FindOne(ctx, bson.M{"_id": orderID, "userId": userID}).Decode(&order)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "order not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		response, err := planReorder(ctx, db, order)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		log.Printf("[%s] order=%s status=%s items=%d unavailable=%d priceChanges=%d",
+			route, orderID.Hex(), response.Status, len(response.Items), len(response.Unavailable), len(response.PriceChanges))
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestMergeOrderLinesAddsUpRepeatedProducts(t *testing.T) {
+	milk, bread := primitive.NewObjectID(), primitive.NewObjectID()
+	lines := mergeOrderLines([]models.OrderItem{
+		{ProductID: milk, Name: "Süt", Price: 30, Quantity: 1},
+		{ProductID: bread, Name: "Ekmek", Price: 10, Quantity: 2},
+		{ProductID: milk, Name: "Süt", Price: 28, Quantity: 2},
+		{ProductID: primitive.NilObjectID, Quantity: 1},
+	})
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %+v", lines)
+	}
+	if lines[0].ProductID != milk || lines[0].Quantity != 3 || lines[0].Price != 30 {
+		t.Fatalf("expected 3 milk at the first price, got %+v", lines[0])
+	}
+}
+
+func TestReorderResponseMarksChangesAsPreview(t *testing.T) {
+	milk, bread := primitive.NewObjectID(), primitive.NewObjectID()
+
+	unchanged := newReorderResponse(primitive.NewObjectID())
+	unchanged.add(models.OrderItem{ProductID: milk, Price: 30, Quantity: 2}, models.OrderItem{ProductID: milk, Price: 30, Quantity: 2})
+	unchanged.finish()
+	if unchanged.Status != reorderStatusCart || unchanged.TotalPrice != 60 {
+		t.Fatalf("expected a ready cart of 60, got %+v", unchanged)
+	}
+
+	changed := newReorderResponse(primitive.NewObjectID())
+	changed.add(models.OrderItem{ProductID: milk, Price: 30, Quantity: 1}, models.OrderItem{ProductID: milk, Price: 32.5, Quantity: 1})
+	changed.skip(models.OrderItem{ProductID: bread, Name: "Ekmek", Quantity: 2}, reorderInactive, nil)
+	changed.finish()
+	if changed.Status != reorderStatusPreview {
+		t.Fatalf("expected preview, got %s", changed.Status)
+	}
+	if len(changed.PriceChanges) != 1 || changed.PriceChanges[0].OldPrice != 30 || changed.PriceChanges[0].NewPrice != 32.5 {
+		t.Fatalf("unexpected price changes %+v", changed.PriceChanges)
+	}
+	if len(changed.Items) != 1 || changed.TotalPrice != 32.5 {
+		t.Fatalf("unavailable items must stay out of the cart, got %+v", changed)
+	}
+
+	// Each line is rounded like buildOrderFromResolvedItems, so the preview
+	// matches the order total.
+	rounded := newReorderResponse(primitive.NewObjectID())
+	rounded.add(models.OrderItem{ProductID: milk, Price: 0.25, Quantity: 0.5}, models.OrderItem{ProductID: milk, Price: 0.25, Quantity: 0.5})
+	rounded.add(models.OrderItem{ProductID: bread, Price: 0.25, Quantity: 0.5}, models.OrderItem{ProductID: bread, Price: 0.25, Quantity: 0.5})
+	rounded.finish()
+	if rounded.TotalPrice != 0.26 {
+		t.Fatalf("expected per-line rounding to 0.26, got %v", rounded.TotalPrice)
+	}
+}
+
+func TestReorderFailureClassifiesItemErrors(t *testing.T) {
+	id := primitive.NewObjectID()
+	reason, available, ok := reorderFailure(outOfStockError{ProductID: id, Available: 1.5, Requested: 3})
+	if !ok || reason != reorderOutOfStock || available == nil || *available != 1.5 {
+		t.Fatalf("unexpected out of stock classification %q %v %v", reason, available, ok)
+	}
+	if reason, _, ok := reorderFailure(productNotFoundError{ProductID: id}); !ok || reason != reorderNotFound {
+		t.Fatalf("expected not_found, got %q", reason)
+	}
+	quantityErr := invalidQuantityError{ProductID: id, Name: "Ekmek", Err: errors.New("quantity must be a whole number")}
+	if reason, _, ok := reorderFailure(fmt.Errorf("resolve: %w", quantityErr)); !ok || reason != reorderInvalidQuantity {
+		t.Fatalf("expected invalid_quantity, got %q", reason)
+	}
+	if _, _, ok := reorderFailure(errors.New("connection reset")); ok {
+		t.Fatalf("db errors must not be reported as unavailable items")
+	}
+}
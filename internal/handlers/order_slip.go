@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+// Characters per line in the printer's default font A.
+var slipPaperColumns = map[string]int{
+	"58": 32,
+	"80": 48,
+}
+
+type slipAlign int
+
+const (
+	slipLeft slipAlign = iota
+	slipCenter
+)
+
+type slipLine struct {
+	Text   string
+	Align  slipAlign
+	Bold   bool
+	Double bool
+}
+
+/*
+GET /admin/api/orders/:id/slip
+- Termal yazıcı fişi: ?width=58|80 (varsayılan 80)
+- ?format=escpos → yazıcıya doğrudan gönderilecek ESC/POS baytları (CP857)
+- ?format=text (varsayılan) → aynı fişin düz metin önizlemesi
+*/
+func AdminOrderSlip(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/orders/:id/slip"
+
+		orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		columns, ok := slipPaperColumns[strings.TrimSuffix(strings.TrimSpace(c.DefaultQuery("width", "80")), "mm")]
+		if !ok {
+			respondWithError(c, http.StatusBadRequest, route, "width must be 58 or 80")
+			return
+		}
+
+		format, err := parseExportFormat(c, "text", "escpos")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var order adminOrderResponse
+		if err := db.Collection("orders").FindOne(ctx, bson.M{"_id": orderID}).Decode(&order); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				respondWithError(c, http.StatusNotFound, route, "order not found")
+				return
+			}
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		orders := []adminOrderResponse{order}
+		attachUserPhones(ctx, db, orders)
+		attachAddresses(ctx, db, orders)
+		enrichAdminOrders(orders)
+
+		store, err := loadStoreSettings(ctx, db)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		lines := buildOrderSlip(orders[0], store, exportLocation(), columns)
+		if format == "escpos" {
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="fis-%s.bin"`, orders[0].OrderCode))
+			c.Data(http.StatusOK, "application/octet-stream", encodeESCPOS(lines))
+			return
+		}
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(renderSlipText(lines, columns)))
+	}
+}
+
+// buildOrderSlip lays out the slip as fixed-width lines so the text preview
+// and the ESC/POS output are identical.
+func buildOrderSlip(order adminOrderResponse, store models.StoreSettings, loc *time.Location, columns int) []slipLine {
+	rule := strings.Repeat("-", columns)
+	lines := []slipLine{
+		{Text: store.Name, Align: slipCenter, Bold: true, Double: true},
+	}
+	for _, text := range storeHeaderLines(store) {
+		for _, wrapped := range wrapSlipText(text, columns) {
+			lines = append(lines, slipLine{Text: wrapped, Align: slipCenter})
+		}
+	}
+
+	lines = append(lines,
+		slipLine{Text: rule},
+		slipLine{Text: "Sipariş: " + order.OrderCode, Bold: true},
+		slipLine{Text: "Tarih: " + order.CreatedAt.In(loc).Format("02.01.2006 15:04")},
+		slipLine{Text: "Ödeme: " + labelOr(paymentMethodLabels, order.PaymentMethod)},
+	)
+
+	if order.Address != nil {
+		lines = append(lines, slipLine{Text: rule})
+		for _, text := range receiptAddressLines(order.Address) {
+			for _, wrapped := range wrapSlipText(text, columns) {
+				lines = append(lines, slipLine{Text: wrapped})
+			}
+		}
+	}
+
+	lines = append(lines, slipLine{Text: rule})
+	for _, item := range order.Items {
+		for _, wrapped := range wrapSlipText(formatQuantity(item.Quantity, item.Unit)+" x "+item.Name, columns) {
+			lines = append(lines, slipLine{Text: wrapped})
+		}
+		amount := formatTRY(item.Price * item.Quantity)
+		if item.OriginalPrice > item.Price {
+			amount = "(" + formatTRY(item.OriginalPrice*item.Quantity) + ") " + amount
+		}
+		lines = append(lines, slipLine{Text: padSlipColumns("", amount, columns)})
+	}
+
+	totals := computeReceiptTotals(order)
+	lines = append(lines, slipLine{Text: rule})
+	if totals.Discount > 0 {
+		lines = append(lines,
+			slipLine{Text: padSlipColumns("Ara Toplam", formatTRY(totals.Subtotal), columns)},
+			slipLine{Text: padSlipColumns("İndirim", "-"+formatTRY(totals.Discount), columns)},
+		)
+	}
+	lines = append(lines, slipLine{Text: padSlipColumns("TOPLAM", formatTRY(totals.Total), columns), Bold: true})
+
+	if store.ReceiptFooter != "" {
+		lines = append(lines, slipLine{})
+		for _, wrapped := range wrapSlipText(store.ReceiptFooter, columns) {
+			lines = append(lines, slipLine{Text: wrapped, Align: slipCenter})
+		}
+	}
+	return lines
+}
+
+func renderSlipText(lines []slipLine, columns int) string {
+	var out strings.Builder
+	for _, line := range lines {
+		text := line.Text
+		if line.Align == slipCenter {
+			if pad := (columns - utf8.RuneCountInString(text)) / 2; pad > 0 {
+				text = strings.Repeat(" ", pad) + text
+			}
+		}
+		out.WriteString(text)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+// ESC/POS commands (Epson compatible).
+var (
+	escposInit        = []byte{0x1B, 0x40}
+	escposCodePage857 = []byte{0x1B, 0x74, 13}
+	escposAlignLeft   = []byte{0x1B, 0x61, 0}
+	escposAlignCenter = []byte{0x1B, 0x61, 1}
+	escposBoldOn      = []byte{0x1B, 0x45, 1}
+	escposBoldOff     = []byte{0x1B, 0x45, 0}
+	escposDoubleOn    = []byte{0x1D, 0x21, 0x11}
+	escposDoubleOff   = []byte{0x1D, 0x21, 0x00}
+	escposFeedAndCut  = []byte{0x1B, 0x64, 4, 0x1D, 0x56, 66, 0}
+)
+
+// encodeESCPOS turns slip lines into printer bytes. Text is sent in code page
+// 857 (Turkish), which is selected at the start of the job.
+func encodeESCPOS(lines []slipLine) []byte {
+	var buf bytes.Buffer
+	buf.Write(escposInit)
+	buf.Write(escposCodePage857)
+
+	for _, line := range lines {
+		if line.Align == slipCenter {
+			buf.Write(escposAlignCenter)
+		} else {
+			buf.Write(escposAlignLeft)
+		}
+		if line.Bold {
+			buf.Write(escposBoldOn)
+		}
+		if line.Double {
+			buf.Write(escposDoubleOn)
+		}
+		buf.Write(encodeCP857(line.Text))
+		buf.WriteByte('\n')
+		if line.Double {
+			buf.Write(escposDoubleOff)
+		}
+		if line.Bold {
+			buf.Write(escposBoldOff)
+		}
+	}
+
+	buf.Write(escposAlignLeft)
+	buf.Write(escposFeedAndCut)
+	return buf.Bytes()
+}
+
+// cp857 covers the Turkish letters and the accented Latin letters that show
+// up in product names; anything else outside ASCII prints as '?'.
+var cp857 = map[rune]byte{
+	'Ç': 0x80, 'ü': 0x81, 'é': 0x82, 'â': 0x83, 'ä': 0x84, 'à': 0x85, 'å': 0x86, 'ç': 0x87,
+	'ê': 0x88, 'ë': 0x89, 'è': 0x8A, 'ï': 0x8B, 'î': 0x8C, 'ı': 0x8D, 'Ä': 0x8E, 'Å': 0x8F,
+	'É': 0x90, 'æ': 0x91, 'Æ': 0x92, 'ô': 0x93, 'ö': 0x94, 'ò': 0x95, 'û': 0x96, 'ù': 0x97,
+	'İ': 0x98, 'Ö': 0x99, 'Ü': 0x9A, 'ø': 0x9B, '£': 0x9C, 'Ø': 0x9D, 'Ş': 0x9E, 'ş': 0x9F,
+	'á': 0xA0, 'í': 0xA1, 'ó': 0xA2, 'ú': 0xA3, 'ñ': 0xA4, 'Ñ': 0xA5, 'Ğ': 0xA6, 'ğ': 0xA7,
+	'Â': 0xB6, 'À': 0xB7, 'Ê': 0xD2, 'Î': 0xD7, 'Ô': 0xE2, 'Û': 0xEA, '·': 0xFA,
+}
+
+func encodeCP857(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		case r == '…':
+			out = append(out, '.', '.', '.')
+		default:
+			if b, ok := cp857[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// wrapSlipText breaks text on spaces so no line exceeds the paper width;
+// words longer than a line are cut.
+func wrapSlipText(text string, columns int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		for utf8.RuneCountInString(word) > columns {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:columns]))
+			word = string(runes[columns:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= columns:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func padSlipColumns(left, right string, columns int) string {
+	gap := columns - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
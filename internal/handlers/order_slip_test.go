@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestBuildOrderSlipFitsPaperWidth(t *testing.T) {
+	order := adminOrderResponse{
+		ID:        primitive.NewObjectID(),
+		OrderCode: "ABCDEF12",
+		Items: []models.OrderItem{
+			{Name: "Organik Tam Buğday Ekmeği Dilimlenmiş Büyük Boy", Price: 42.5, Quantity: 2},
+			{Name: "Peynir", Price: 80, OriginalPrice: 100, Quantity: 1},
+		},
+		TotalPrice:    165,
+		PaymentMethod: "card",
+		Address:       &adminOrderAddress{Name: "Gül Işık", FullText: "Çiçek Sokak No:3 Daire:5 Üsküdar İstanbul"},
+		CreatedAt:     time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	for _, columns := range slipPaperColumns {
+		text := renderSlipText(buildOrderSlip(order, defaultStoreSettings, time.UTC, columns), columns)
+		for _, line := range strings.Split(text, "\n") {
+			if utf8.RuneCountInString(line) > columns {
+				t.Fatalf("line exceeds %d columns: %q", columns, line)
+			}
+		}
+		if !strings.Contains(text, "TOPLAM") || !strings.Contains(text, "165,00 TL") {
+			t.Fatalf("slip is missing the total:\n%s", text)
+		}
+	}
+}
+
+func TestEncodeESCPOSUsesTurkishCodePage(t *testing.T) {
+	out := encodeESCPOS([]slipLine{{Text: "Şığ İçöü"}})
+
+	if !bytes.HasPrefix(out, append(append([]byte{}, escposInit...), escposCodePage857...)) {
+		t.Fatalf("expected init and code page selection at start")
+	}
+	if !bytes.Contains(out, []byte{0x9E, 0x8D, 0xA7, ' ', 0x98, 0x87, 0x94, 0x81}) {
+		t.Fatalf("Turkish letters not encoded as CP857: % x", out)
+	}
+	if !bytes.HasSuffix(out, escposFeedAndCut) {
+		t.Fatalf("expected feed and cut at end")
+	}
+}
@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func parsePaginationParams(pageStr, limitStr string) (int64, int64, error) {
@@ -31,3 +38,153 @@ func parsePaginationParams(pageStr, limitStr string) (int64, int64, error) {
 
 	return page, limit, nil
 }
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the position of the last item a client has seen: the sort it
+// was listed with, its sort key values and its _id. Clients only see it as an
+// opaque token.
+type pageCursor struct {
+	Sort   string             `bson:"s"`
+	Values bson.A             `bson:"v"`
+	ID     primitive.ObjectID `bson:"id"`
+}
+
+// encode is BSON rather than JSON so dates and numbers keep their types.
+func (p pageCursor) encode() string {
+	raw, err := bson.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodePageCursor(token, sortName string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor pageCursor
+	if err := bson.Unmarshal(raw, &cursor); err != nil || cursor.ID.IsZero() {
+		return nil, errInvalidCursor
+	}
+	if cursor.Sort != sortName {
+		return nil, errors.New("cursor does not match sort")
+	}
+	return &cursor, nil
+}
+
+// parseCursorParam reports whether the request uses cursor pagination:
+// ?cursor= is present, and empty for the first page. after is nil on the
+// first page.
+func parseCursorParam(query url.Values, sortName string) (active bool, after *pageCursor, err error) {
+	values, ok := query["cursor"]
+	if !ok {
+		return false, nil, nil
+	}
+	if len(values) == 0 || values[0] == "" {
+		return true, nil, nil
+	}
+	after, err = decodePageCursor(values[0], sortName)
+	return true, after, err
+}
+
+// isCursorValue reports whether v can be a sort key value. Cursors come from
+// clients, so documents and arrays, which could carry query operators, are
+// refused.
+func isCursorValue(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, bool, int32, int64, float64,
+		primitive.DateTime, primitive.Decimal128, primitive.ObjectID:
+		return true
+	}
+	return false
+}
+
+// afterCursor matches the documents that sort after cursor. sortKeys must
+// end with _id so the order is total; Mongo sorts null and missing values
+// first, which the null branches mirror.
+func afterCursor(sortKeys bson.D, cursor *pageCursor) (bson.M, error) {
+	if len(sortKeys) == 0 || sortKeys[len(sortKeys)-1].Key != "_id" || len(cursor.Values) != len(sortKeys)-1 {
+		return nil, errInvalidCursor
+	}
+	for _, value := range cursor.Values {
+		if !isCursorValue(value) {
+			return nil, errInvalidCursor
+		}
+	}
+
+	var branches bson.A
+	prefix := bson.M{}
+	for i, key := range sortKeys {
+		var value interface{} = cursor.ID
+		if key.Key != "_id" {
+			value = cursor.Values[i]
+		}
+		descending := key.Value == -1
+
+		branch := func(condition interface{}) bson.M {
+			out := bson.M{key.Key: condition}
+			for k, v := range prefix {
+				out[k] = v
+			}
+			return out
+		}
+		switch {
+		case value == nil && !descending:
+			branches = append(branches, branch(bson.M{"$ne": nil}))
+		case value == nil:
+			// nothing sorts below null
+		case descending:
+			branches = append(branches, branch(bson.M{"$lt": value}))
+			if key.Key != "_id" {
+				branches = append(branches, branch(nil))
+			}
+		default:
+			branches = append(branches, branch(bson.M{"$gt": value}))
+		}
+		prefix[key.Key] = value
+	}
+	return bson.M{"$or": branches}, nil
+}
+
+// newestFirst is the list order of orders and admin products; _id breaks
+// ties between documents created in the same millisecond.
+var newestFirst = bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}
+
+// withCursor narrows filter to the documents after cursor (nil keeps it).
+func withCursor(filter bson.M, sortKeys bson.D, after *pageCursor) (bson.M, error) {
+	if after == nil {
+		return filter, nil
+	}
+	keyset, err := afterCursor(sortKeys, after)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$and": bson.A{filter, keyset}}, nil
+}
+
+// trimCursorPage drops the extra item fetched to tell whether another page
+// follows and returns the cursor after the last item kept.
+func trimCursorPage[T any](items []T, limit int64, sortName string, key func(T) (bson.A, primitive.ObjectID)) ([]T, *pageCursor) {
+	if int64(len(items)) <= limit {
+		return items, nil
+	}
+	items = items[:limit]
+	values, id := key(items[len(items)-1])
+	return items, &pageCursor{Sort: sortName, Values: values, ID: id}
+}
+
+// cursorPagination is the pagination block of a cursor-mode response.
+// nextCursor is null on the last page.
+func cursorPagination(limit int64, next *pageCursor) gin.H {
+	var token interface{}
+	if next != nil {
+		token = next.encode()
+	}
+	return gin.H{
+		"limit":      limit,
+		"nextCursor": token,
+		"hasMore":    next != nil,
+	}
+}
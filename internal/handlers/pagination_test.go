@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPageCursorRoundTripKeepsTypes(t *testing.T) {
+	createdAt := primitive.NewDateTimeFromTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	cursor := pageCursor{Sort: sortPopular, Values: bson.A{12.5, createdAt}, ID: primitive.NewObjectID()}
+
+	active, after, err := parseCursorParam(url.Values{"cursor": {cursor.encode()}}, sortPopular)
+	if err != nil || !active {
+		t.Fatalf("active=%v err=%v", active, err)
+	}
+	if after.ID != cursor.ID || !reflect.DeepEqual(after.Values, cursor.Values) {
+		t.Fatalf("got %+v, want %+v", after, cursor)
+	}
+
+	if _, _, err := parseCursorParam(url.Values{"cursor": {cursor.encode()}}, sortName); err == nil {
+		t.Fatal("a cursor must not be reused with another sort")
+	}
+	if _, _, err := parseCursorParam(url.Values{"cursor": {"not-a-cursor"}}, ""); err == nil {
+		t.Fatal("garbage cursor must be rejected")
+	}
+	if active, after, err := parseCursorParam(url.Values{"cursor": {""}}, ""); !active || after != nil || err != nil {
+		t.Fatalf("empty cursor starts cursor mode: active=%v after=%v err=%v", active, after, err)
+	}
+	if active, _, _ := parseCursorParam(url.Values{"page": {"2"}}, ""); active {
+		t.Fatal("page mode must stay the default")
+	}
+}
+
+func TestAfterCursorBranches(t *testing.T) {
+	id := primitive.NewObjectID()
+	createdAt := primitive.NewDateTimeFromTime(time.Now())
+
+	got, err := afterCursor(newestFirst, &pageCursor{Values: bson.A{createdAt}, ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bson.M{"$or": bson.A{
+		bson.M{"createdAt": bson.M{"$lt": createdAt}},
+		bson.M{"createdAt": nil},
+		bson.M{"createdAt": createdAt, "_id": bson.M{"$lt": id}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v\nwant %v", got, want)
+	}
+
+	// ascending past a missing price: every priced product is still ahead
+	asc := bson.D{{Key: "_effectivePrice", Value: 1}, {Key: "_id", Value: 1}}
+	got, _ = afterCursor(asc, &pageCursor{Values: bson.A{nil}, ID: id})
+	first := got["$or"].(bson.A)[0].(bson.M)
+	if !reflect.DeepEqual(first, bson.M{"_effectivePrice": bson.M{"$ne": nil}}) {
+		t.Fatalf("unexpected null branch %v", first)
+	}
+
+	if _, err := afterCursor(newestFirst, &pageCursor{ID: id}); err == nil {
+		t.Fatal("cursor without sort values must be rejected")
+	}
+	for _, value := range []interface{}{bson.D{{Key: "$gt", Value: ""}}, bson.M{"$ne": nil}, bson.A{1, 2}} {
+		if _, err := afterCursor(newestFirst, &pageCursor{Values: bson.A{value}, ID: id}); err == nil {
+			t.Fatalf("cursor value %v must be rejected", value)
+		}
+	}
+}
+
+func TestTrimCursorPageAndRankedPages(t *testing.T) {
+	key := func(n int) (bson.A, primitive.ObjectID) { return bson.A{n}, primitive.ObjectID{byte(n)} }
+
+	items, next := trimCursorPage([]int{1, 2, 3}, 2, "", key)
+	if len(items) != 2 || next == nil || next.Values[0] != 2 {
+		t.Fatalf("items=%v next=%+v", items, next)
+	}
+	if _, next := trimCursorPage([]int{1, 2}, 2, "", key); next != nil {
+		t.Fatal("last page must not have a next cursor")
+	}
+
+	ids := []primitive.ObjectID{{1}, {2}, {3}, {4}, {5}}
+	page, next, err := rankedPageAfter(ids, nil, 2, sortRelevance)
+	if err != nil || len(page) != 2 || next.ID != ids[1] {
+		t.Fatalf("first page %v next %+v err %v", page, next, err)
+	}
+	page, next, _ = rankedPageAfter(ids, next, 2, sortRelevance)
+	if page[0] != ids[2] || next.ID != ids[3] {
+		t.Fatalf("second page %v next %+v", page, next)
+	}
+	page, next, _ = rankedPageAfter(ids, next, 2, sortRelevance)
+	if len(page) != 1 || next != nil {
+		t.Fatalf("last page %v next %+v", page, next)
+	}
+	if _, _, err := rankedPageAfter(ids[:2], &pageCursor{ID: ids[4]}, 2, sortRelevance); err == nil {
+		t.Fatal("a cursor whose product dropped out must be rejected")
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+var productExportHeader = []string{
+	"id", "barcode", "name", "brand", "category", "price", "saleEnabled", "salePrice",
+	"costPrice", "effectivePrice", "isOnSale", "unit", "stock", "reorderLevel", "inStock",
+	"isActive", "isCampaign", "variantLabel", "description", "imagePath", "createdAt",
+}
+
+// productExportRecord is the NDJSON shape: the product plus computed fields.
+type productExportRecord struct {
+	models.Product
+	EffectivePrice float64 `json:"effectivePrice"`
+}
+
+/*
+GET /admin/api/products/export
+- GetAllProducts ile aynı filtreler (category, search, isActive), sayfalama yok
+- ?format=csv|xlsx|ndjson (varsayılan csv)
+- Ürünler cursor üzerinden tek tek yazılır, koleksiyon belleğe alınmaz
+*/
+func ExportProducts(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/products/export"
+
+		format, err := parseExportFormat(c, "csv", "xlsx", "ndjson")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+		defer cancel()
+
+		cursor, err := db.Collection("products").Find(ctx,
+			buildAdminProductsFilter(c),
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetBatchSize(500),
+		)
+		if err != nil {
+			log.Printf("[%s] find failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer cursor.Close(ctx)
+
+		filename := exportFilename("urunler", time.Now())
+		if format == "ndjson" {
+			err = streamProductsNDJSON(ctx, c, cursor, filename)
+		} else {
+			err = streamProductsTable(ctx, c, cursor, format, filename)
+		}
+		if err != nil {
+			// Headers are already sent; all we can do is stop and log.
+			log.Printf("[%s] export aborted: %v", route, err)
+		}
+	}
+}
+
+func streamProductsNDJSON(ctx context.Context, c *gin.Context, cursor *mongo.Cursor, filename string) error {
+	c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ndjson"`, filename))
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	rows := 0
+	return forEachProduct(ctx, cursor, func(product models.Product) error {
+		if err := encoder.Encode(productExportRecord{
+			Product:        product,
+			EffectivePrice: effectiveProductPrice(product.Price, product.SaleEnabled, product.SalePrice),
+		}); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+}
+
+func streamProductsTable(ctx context.Context, c *gin.Context, cursor *mongo.Cursor, format, filename string) error {
+	exporter, err := newTableExporter(c, format, filename, productExportHeader)
+	if err != nil {
+		return err
+	}
+
+	if err := forEachProduct(ctx, cursor, func(product models.Product) error {
+		return exporter.WriteRow(productExportRow(product))
+	}); err != nil {
+		return err
+	}
+	return exporter.Close()
+}
+
+func productExportRow(product models.Product) []interface{} {
+	return []interface{}{
+		product.ID.Hex(),
+		product.Barcode,
+		product.Name,
+		product.Brand,
+		strings.Join(product.Category, "; "),
+		product.Price,
+		product.SaleEnabled,
+		product.SalePrice,
+		product.CostPrice,
+		effectiveProductPrice(product.Price, product.SaleEnabled, product.SalePrice),
+		product.IsOnSale,
+		product.Unit,
+		exportQuantity(product.Stock),
+		exportQuantity(product.ReorderLevel),
+		product.InStock,
+		product.IsActive,
+		product.IsCampaign,
+		product.VariantLabel,
+		product.Description,
+		product.ImagePath,
+		product.CreatedAt.In(exportLocation()).Format("2006-01-02 15:04"),
+	}
+}
+
+// forEachProduct decodes one document at a time through the same
+// normalization as decodeProducts.
+func forEachProduct(ctx context.Context, cursor *mongo.Cursor, fn func(models.Product) error) error {
+	for cursor.Next(ctx) {
+		var raw bson.M
+		if err := cursor.Decode(&raw); err != nil {
+			return err
+		}
+		product, err := normalizeProductDocument(raw)
+		if err != nil {
+			return err
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestProductExportRowMatchesHeader(t *testing.T) {
+	product := models.Product{
+		ID:          primitive.NewObjectID(),
+		Name:        "Çay 1kg",
+		Price:       200,
+		SaleEnabled: true,
+		SalePrice:   180,
+		IsOnSale:    true,
+		Category:    models.StringList{"İçecek", "Kahvaltılık"},
+		Stock:       3,
+		InStock:     true,
+		CreatedAt:   time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC),
+	}
+
+	row := productExportRow(product)
+	if len(row) != len(productExportHeader) {
+		t.Fatalf("row has %d cells, header has %d", len(row), len(productExportHeader))
+	}
+	values := map[string]string{}
+	for i, column := range productExportHeader {
+		values[column] = formatExportCell(row[i])
+	}
+	if values["effectivePrice"] != "180.00" {
+		t.Fatalf("unexpected effective price %q", values["effectivePrice"])
+	}
+	if values["inStock"] != "true" || values["category"] != "İçecek; Kahvaltılık" {
+		t.Fatalf("unexpected computed cells %v", values)
+	}
+	if values["createdAt"] != "2025-01-02 12:00" {
+		t.Fatalf("expected Istanbul time, got %q", values["createdAt"])
+	}
+}
+
+func TestProductExportHeaderIsImportable(t *testing.T) {
+	columns, err := mapImportHeader(productExportHeader)
+	if err != nil {
+		t.Fatalf("mapImportHeader returned error: %v", err)
+	}
+	for _, field := range []string{"barcode", "name", "price", "saleEnabled", "salePrice", "category", "stock", "reorderLevel", "costPrice"} {
+		if _, ok := columns[field]; !ok {
+			t.Fatalf("export column for %s is not recognised by the import", field)
+		}
+	}
+}
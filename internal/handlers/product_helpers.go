@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -52,6 +55,10 @@ func parseLooseNumber(value interface{}) float64 {
 	}
 }
 
+// publicProductProjection hides the purchasing fields from customer-facing
+// product listings.
+var publicProductProjection = bson.M{"costPrice": 0, "reorderLevel": 0, "soldCount": 0}
+
 func normalizeProductDocument(raw bson.M) (models.Product, error) {
 	if cat, ok := raw["category"].(string); ok {
 		raw["category"] = []string{cat}
@@ -71,20 +78,13 @@ func normalizeProductDocument(raw bson.M) (models.Product, error) {
 	}
 
 	if val, ok := raw["stock"]; ok {
-		switch typed := val.(type) {
-		case int32:
-			raw["stock"] = int(typed)
-		case int64:
-			raw["stock"] = int(typed)
-		case float64:
-			raw["stock"] = int(typed)
-		case int:
-			raw["stock"] = typed
-		default:
-			raw["stock"] = 0
-		}
+		raw["stock"] = parseLooseNumber(val)
 	} else {
-		raw["stock"] = 0
+		raw["stock"] = 0.0
+	}
+
+	if val, ok := raw["reorderLevel"]; ok {
+		raw["reorderLevel"] = parseLooseNumber(val)
 	}
 
 	if val, ok := raw["saleEnabled"]; ok {
@@ -115,12 +115,76 @@ func normalizeProductDocument(raw bson.M) (models.Product, error) {
 		return models.Product{}, err
 	}
 
+	if p.Unit == "" {
+		p.Unit = models.UnitPiece
+	}
 	p.InStock = p.Stock > 0
 	p.IsOnSale = isProductOnSale(p.Price, p.SaleEnabled, p.SalePrice)
+	if len(p.Images) == 0 && p.ImagePath != "" {
+		p.Images = productGallery(p)
+	}
 
 	return p, nil
 }
 
+// parseProductUnit accepts the English and Turkish unit names; an empty
+// value means piece.
+func parseProductUnit(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "piece", "adet":
+		return models.UnitPiece, nil
+	case "kg", "kilo", "kilogram":
+		return models.UnitKg, nil
+	default:
+		return "", fmt.Errorf("unit must be piece or kg")
+	}
+}
+
+// validateQuantity checks an order, stock or purchase quantity against the
+// product's unit: whole numbers for pieces, grams precision for kg.
+func validateQuantity(unit string, quantity float64) error {
+	if math.IsNaN(quantity) || math.IsInf(quantity, 0) {
+		return errors.New("invalid quantity")
+	}
+	if unit == models.UnitKg {
+		if roundQuantity(quantity) != quantity {
+			return errors.New("kg quantities allow at most 3 decimals")
+		}
+		return nil
+	}
+	if quantity != math.Trunc(quantity) {
+		return errors.New("quantity must be a whole number")
+	}
+	return nil
+}
+
+// roundQuantity rounds to grams so repeated decimal arithmetic on weighed
+// stock does not drift.
+func roundQuantity(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// formatQuantity writes a quantity for people: "3" for pieces, "1,25 kg"
+// for weighed items.
+func formatQuantity(quantity float64, unit string) string {
+	text := strings.Replace(strconv.FormatFloat(roundQuantity(quantity), 'f', -1, 64), ".", ",", 1)
+	if unit == models.UnitKg {
+		return text + " kg"
+	}
+	return text
+}
+
+// productDisplayName is the name used on orders, receipts and lists; a
+// variant carries its label, e.g. "Domates 1 kg".
+func productDisplayName(product models.Product) string {
+	name := strings.TrimSpace(product.Name)
+	label := strings.TrimSpace(product.VariantLabel)
+	if label == "" || strings.HasSuffix(strings.ToLower(name), strings.ToLower(label)) {
+		return name
+	}
+	return name + " " + label
+}
+
 func decodeProducts(ctx context.Context, cursor *mongo.Cursor) ([]models.Product, error) {
 	products := make([]models.Product, 0)
 
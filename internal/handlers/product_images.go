@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/imaging"
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+const (
+	// maxImageUploadSize is the raw upload limit; stored files are the much
+	// smaller resized renditions.
+	maxImageUploadSize = 15 << 20
+	maxProductImages   = 10
+)
+
+var (
+	imageEncoderOnce sync.Once
+	imageEncoder     imaging.Encoder
+)
+
+func productImageEncoder() imaging.Encoder {
+	imageEncoderOnce.Do(func() {
+		imageEncoder = imaging.NewEncoder()
+	})
+	return imageEncoder
+}
+
+// saveProductImage sniffs, decodes and resizes an upload and stores its
+// thumbnail, medium and large renditions. The original is not kept.
+func saveProductImage(ctx context.Context, uploads storage.Storage, file *multipart.FileHeader) (models.ProductImage, error) {
+	return saveImageRenditions(ctx, uploads, "products", file)
+}
+
+// saveImageRenditions stores the renditions of an upload under uploads/dir.
+func saveImageRenditions(ctx context.Context, uploads storage.Storage, dir string, file *multipart.FileHeader) (models.ProductImage, error) {
+	if file.Size > maxImageUploadSize {
+		return models.ProductImage{}, fmt.Errorf("image file too large (max 15MB)")
+	}
+
+	in, err := file.Open()
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	defer in.Close()
+
+	data, err := io.ReadAll(io.LimitReader(in, maxImageUploadSize+1))
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	if len(data) > maxImageUploadSize {
+		return models.ProductImage{}, fmt.Errorf("image file too large (max 15MB)")
+	}
+
+	renditions, err := imaging.Process(data, productImageEncoder())
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+
+	image := models.ProductImage{ID: primitive.NewObjectID().Hex()}
+	written := make([]string, 0, len(renditions))
+	for _, rendition := range renditions {
+		relPath := path.Join("uploads", dir, image.ID+"-"+rendition.Size.Name+rendition.Ext)
+		if err := uploads.Put(ctx, relPath, rendition.Data, rendition.ContentType); err != nil {
+			log.Printf("[UPLOAD] saveImageRenditions: failed to write %s: %v", relPath, err)
+			for _, done := range written {
+				_ = safeDeleteUpload(ctx, uploads, done)
+			}
+			return models.ProductImage{}, err
+		}
+		written = append(written, relPath)
+
+		switch rendition.Size.Name {
+		case "large":
+			image.Large = relPath
+			image.Width, image.Height = rendition.Width, rendition.Height
+		case "medium":
+			image.Medium = relPath
+		case "thumb":
+			image.Thumbnail = relPath
+		}
+	}
+	log.Printf("[UPLOAD] saveImageRenditions: dir=%s id=%s file=%q size=%d", dir, image.ID, file.Filename, file.Size)
+	return image, nil
+}
+
+// saveProductImages stores several uploads; if one fails the ones already
+// stored are removed again.
+func saveProductImages(ctx context.Context, uploads storage.Storage, files []*multipart.FileHeader) ([]models.ProductImage, error) {
+	images := make([]models.ProductImage, 0, len(files))
+	for _, file := range files {
+		image, err := saveProductImage(ctx, uploads, file)
+		if err != nil {
+			deleteProductImageFiles(ctx, uploads, images)
+			return nil, fmt.Errorf("%s: %w", file.Filename, err)
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// deleteProductImageFiles removes every rendition through safeDeleteUpload.
+func deleteProductImageFiles(ctx context.Context, uploads storage.Storage, images []models.ProductImage) {
+	for _, image := range images {
+		for _, relPath := range []string{image.Thumbnail, image.Medium, image.Large} {
+			if err := safeDeleteUpload(ctx, uploads, relPath); err != nil {
+				log.Printf("[UPLOAD] delete %s failed: %v", relPath, err)
+			}
+		}
+	}
+}
+
+// productGallery returns the product's images. Products saved before the
+// gallery existed only have imagePath; it is treated as their single image,
+// with an ID derived from the path so it stays the same between requests.
+func productGallery(product models.Product) []models.ProductImage {
+	if len(product.Images) > 0 {
+		return product.Images
+	}
+	if strings.TrimSpace(product.ImagePath) == "" {
+		return []models.ProductImage{}
+	}
+	return []models.ProductImage{{
+		ID:        legacyImageID(product.ImagePath),
+		Thumbnail: product.ImagePath,
+		Medium:    product.ImagePath,
+		Large:     product.ImagePath,
+	}}
+}
+
+// legacyImageID is the ID of an imagePath-only image: ObjectID-sized hex of
+// the path's hash.
+func legacyImageID(imagePath string) string {
+	sum := sha1.Sum([]byte(imagePath))
+	return hex.EncodeToString(sum[:12])
+}
+
+// coverImagePath is the imagePath kept for single-image clients.
+func coverImagePath(images []models.ProductImage) string {
+	if len(images) == 0 {
+		return ""
+	}
+	return images[0].Medium
+}
+
+// mergeUploadedImages puts new uploads into a gallery. A new cover replaces
+// the first image; the others are appended. It returns the images that
+// dropped out so their files can be deleted.
+func mergeUploadedImages(gallery []models.ProductImage, cover *models.ProductImage, extra []models.ProductImage) ([]models.ProductImage, []models.ProductImage) {
+	merged := make([]models.ProductImage, 0, len(gallery)+len(extra)+1)
+	var removed []models.ProductImage
+	if cover != nil {
+		merged = append(merged, *cover)
+		if len(gallery) > 0 {
+			removed = append(removed, gallery[0])
+			gallery = gallery[1:]
+		}
+	}
+	merged = append(merged, gallery...)
+	merged = append(merged, extra...)
+	return merged, removed
+}
+
+// reorderImages returns the gallery in the order of ids, which must name
+// every image exactly once.
+func reorderImages(gallery []models.ProductImage, ids []string) ([]models.ProductImage, error) {
+	if len(ids) != len(gallery) {
+		return nil, errors.New("imageIds must list every image once")
+	}
+	byID := make(map[string]models.ProductImage, len(gallery))
+	for _, image := range gallery {
+		byID[image.ID] = image
+	}
+	ordered := make([]models.ProductImage, 0, len(ids))
+	for _, id := range ids {
+		image, ok := byID[id]
+		if !ok {
+			return nil, errors.New("imageIds must list every image once")
+		}
+		delete(byID, id)
+		ordered = append(ordered, image)
+	}
+	return ordered, nil
+}
+
+// imageGallerySet is the update that stores a gallery and its cover path.
+func imageGallerySet(images []models.ProductImage) bson.M {
+	update := bson.M{"$unset": bson.M{"image": ""}}
+	if len(images) == 0 {
+		update["$unset"] = bson.M{"image": "", "images": "", "imagePath": ""}
+		return update
+	}
+	update["$set"] = bson.M{"images": images, "imagePath": coverImagePath(images)}
+	return update
+}
+
+func loadProductForImages(ctx context.Context, db *mongo.Database, id primitive.ObjectID) (models.Product, error) {
+	var product models.Product
+	err := db.Collection("products").FindOne(ctx, bson.M{"_id": id, "isDeleted": bson.M{"$ne": true}}).Decode(&product)
+	return product, err
+}
+
+func saveGallery(ctx context.Context, db *mongo.Database, id primitive.ObjectID, images []models.ProductImage) error {
+	res, err := db.Collection("products").UpdateOne(ctx,
+		bson.M{"_id": id, "isDeleted": bson.M{"$ne": true}},
+		imageGallerySet(images),
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+/*
+POST /admin/api/products/:id/images
+- multipart "images" (birden fazla dosya) galerinin sonuna eklenir
+- Her görsel küçük/orta/büyük boyutlara ölçeklenir, EXIF silinir
+- Ürün başına en fazla 10 görsel
+*/
+func AddProductImages(db *mongo.Database, uploads storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/products/:id/images"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		form, err := c.MultipartForm()
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "multipart/form-data required")
+			return
+		}
+		files := append(form.File["images"], form.File["image"]...)
+		if len(files) == 0 {
+			respondWithError(c, http.StatusBadRequest, route, "images required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		product, err := loadProductForImages(ctx, db, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "product not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		gallery := productGallery(product)
+		if len(gallery)+len(files) > maxProductImages {
+			respondWithError(c, http.StatusBadRequest, route, fmt.Sprintf("a product can have at most %d images", maxProductImages))
+			return
+		}
+
+		uploaded, err := saveProductImages(ctx, uploads, files)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		gallery, _ = mergeUploadedImages(gallery, nil, uploaded)
+		if err := saveGallery(ctx, db, id, gallery); err != nil {
+			deleteProductImageFiles(ctx, uploads, uploaded)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"images": gallery, "imagePath": coverImagePath(gallery)})
+	}
+}
+
+/*
+PUT /admin/api/products/:id/images/order
+- {"imageIds": ["...", "..."]}; ilk görsel kapak olur
+*/
+func ReorderProductImages(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "PUT /admin/api/products/:id/images/order"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		var req struct {
+			ImageIDs []string `json:"imageIds"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		product, err := loadProductForImages(ctx, db, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "product not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		ordered, err := reorderImages(productGallery(product), req.ImageIDs)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+		if err := saveGallery(ctx, db, id, ordered); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"images": ordered, "imagePath": coverImagePath(ordered)})
+	}
+}
+
+/*
+DELETE /admin/api/products/:id/images/:imageId
+- Görseli galeriden çıkarır ve tüm boyutlarının dosyalarını siler
+*/
+func DeleteProductImage(db *mongo.Database, uploads storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /admin/api/products/:id/images/:imageId"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+		imageID := strings.TrimSpace(c.Param("imageId"))
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		product, err := loadProductForImages(ctx, db, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "product not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		gallery := productGallery(product)
+		remaining := make([]models.ProductImage, 0, len(gallery))
+		var removed []models.ProductImage
+		for _, image := range gallery {
+			if image.ID == imageID {
+				removed = append(removed, image)
+				continue
+			}
+			remaining = append(remaining, image)
+		}
+		if len(removed) == 0 {
+			respondWithError(c, http.StatusNotFound, route, "image not found")
+			return
+		}
+
+		if err := saveGallery(ctx, db, id, remaining); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		deleteProductImageFiles(ctx, uploads, removed)
+
+		c.JSON(http.StatusOK, gin.H{"images": remaining, "imagePath": coverImagePath(remaining)})
+	}
+}
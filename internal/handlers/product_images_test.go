@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestMergeUploadedImagesReplacesCoverAndAppends(t *testing.T) {
+	gallery := []models.ProductImage{{ID: "a", Medium: "uploads/products/a-medium.webp"}, {ID: "b"}}
+	cover := models.ProductImage{ID: "c", Medium: "uploads/products/c-medium.webp"}
+
+	merged, removed := mergeUploadedImages(gallery, &cover, []models.ProductImage{{ID: "d"}})
+	if len(merged) != 3 || merged[0].ID != "c" || merged[1].ID != "b" || merged[2].ID != "d" {
+		t.Fatalf("unexpected gallery %+v", merged)
+	}
+	if len(removed) != 1 || removed[0].ID != "a" {
+		t.Fatalf("expected old cover to be removed, got %+v", removed)
+	}
+	if coverImagePath(merged) != "uploads/products/c-medium.webp" {
+		t.Fatalf("unexpected cover path %q", coverImagePath(merged))
+	}
+
+	appended, removed := mergeUploadedImages(gallery, nil, []models.ProductImage{{ID: "d"}})
+	if len(appended) != 3 || appended[0].ID != "a" || len(removed) != 0 {
+		t.Fatalf("expected append only, got %+v removed %+v", appended, removed)
+	}
+}
+
+func TestReorderImagesRequiresEveryImageOnce(t *testing.T) {
+	gallery := []models.ProductImage{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	ordered, err := reorderImages(gallery, []string{"c", "a", "b"})
+	if err != nil || ordered[0].ID != "c" || ordered[2].ID != "b" {
+		t.Fatalf("unexpected order %+v %v", ordered, err)
+	}
+	for _, ids := range [][]string{{"a", "b"}, {"a", "a", "b"}, {"a", "b", "x"}} {
+		if _, err := reorderImages(gallery, ids); err == nil {
+			t.Fatalf("expected error for %v", ids)
+		}
+	}
+}
+
+func TestProductGalleryWrapsLegacyImagePath(t *testing.T) {
+	gallery := productGallery(models.Product{ImagePath: "uploads/products/old.jpg"})
+	if len(gallery) != 1 || gallery[0].Thumbnail != "uploads/products/old.jpg" || gallery[0].ID == "" {
+		t.Fatalf("unexpected legacy gallery %+v", gallery)
+	}
+	again := productGallery(models.Product{ImagePath: "uploads/products/old.jpg"})
+	if again[0].ID != gallery[0].ID {
+		t.Fatalf("legacy image ID must be stable, got %q and %q", gallery[0].ID, again[0].ID)
+	}
+	if _, err := reorderImages(again, []string{gallery[0].ID}); err != nil {
+		t.Fatalf("legacy image must be reorderable by its ID: %v", err)
+	}
+	if len(productGallery(models.Product{})) != 0 {
+		t.Fatal("expected empty gallery without images")
+	}
+}
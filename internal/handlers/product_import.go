@@ -0,0 +1,943 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+const (
+	maxImportFileSize = 10 << 20
+	maxImportRows     = 5000
+)
+
+// importColumnAliases maps accepted header spellings (lower-case) to the
+// product field they fill. Turkish headers are accepted for files exported
+// from the accounting software.
+var importColumnAliases = map[string]string{
+	"barcode":         "barcode",
+	"barkod":          "barcode",
+	"name":            "name",
+	"ad":              "name",
+	"ürün adı":        "name",
+	"price":           "price",
+	"fiyat":           "price",
+	"saleenabled":     "saleEnabled",
+	"sale_enabled":    "saleEnabled",
+	"indirim":         "saleEnabled",
+	"saleprice":       "salePrice",
+	"sale_price":      "salePrice",
+	"indirimli fiyat": "salePrice",
+	"costprice":       "costPrice",
+	"cost_price":      "costPrice",
+	"maliyet":         "costPrice",
+	"category":        "category",
+	"categories":      "category",
+	"category_id":     "category",
+	"kategori":        "category",
+	"brand":           "brand",
+	"marka":           "brand",
+	"description":     "description",
+	"açıklama":        "description",
+	"stock":           "stock",
+	"stok":            "stock",
+	"reorderlevel":    "reorderLevel",
+	"reorder_level":   "reorderLevel",
+	"kritik stok":     "reorderLevel",
+	"unit":            "unit",
+	"birim":           "unit",
+	"variantlabel":    "variantLabel",
+	"variant_label":   "variantLabel",
+	"varyant":         "variantLabel",
+	"isactive":        "isActive",
+	"aktif":           "isActive",
+	"iscampaign":      "isCampaign",
+	"kampanya":        "isCampaign",
+}
+
+// productImportFields holds the cells of one row. A nil pointer means the
+// column is missing or the cell is empty, so an update leaves that field alone.
+type productImportFields struct {
+	Name         *string
+	Barcode      string
+	Brand        *string
+	Description  *string
+	Price        *float64
+	SaleEnabled  *bool
+	SalePrice    *float64
+	CostPrice    *float64
+	Stock        *float64
+	ReorderLevel *float64
+	Unit         *string
+	VariantLabel *string
+	IsActive     *bool
+	IsCampaign   *bool
+	Categories   []string
+}
+
+type productImportReportRow struct {
+	Row       int      `json:"row"`
+	Action    string   `json:"action"`
+	Barcode   string   `json:"barcode,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	ProductID string   `json:"productId,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+type productImportSummary struct {
+	Rows      int `json:"rows"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Errors    int `json:"errors"`
+}
+
+type productImportPlan struct {
+	Report []productImportReportRow
+	Writes []mongo.WriteModel
+	Stock  []importStockChange
+}
+
+// importStockChange is a stock level the import sets; the commit turns it
+// into a stock movement.
+type importStockChange struct {
+	ProductID primitive.ObjectID
+	Stock     float64
+	Created   bool
+}
+
+/*
+POST /admin/api/products/import
+- multipart "file": .csv (virgül veya noktalı virgül) ya da .xlsx (ilk sayfa)
+- İlk satır başlık; barkodu mevcut ürünle eşleşen satırlar güncellenir, diğerleri oluşturulur
+- ?dryRun=true → hiçbir şey yazmadan satır bazlı rapor
+- Hatalı satır varsa yazılmaz (422); ?skipErrors=true ile geçerli satırlar yazılır
+*/
+func ImportProducts(db *mongo.Database, productSearch *search.Index) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/products/import"
+
+		dryRun, err := parseOptionalBoolQuery(c, "dryRun")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+		skipErrors, err := parseOptionalBoolQuery(c, "skipErrors")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "file required")
+			return
+		}
+		if fileHeader.Size > maxImportFileSize {
+			respondWithError(c, http.StatusBadRequest, route, "file too large (max 10MB)")
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "file could not be read")
+			return
+		}
+		defer file.Close()
+
+		records, rawNumbers, err := readImportRecords(fileHeader.Filename, file)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+		if len(records) < 2 {
+			respondWithError(c, http.StatusBadRequest, route, "file has no data rows")
+			return
+		}
+		if len(records)-1 > maxImportRows {
+			respondWithError(c, http.StatusBadRequest, route, fmt.Sprintf("too many rows (max %d)", maxImportRows))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+		defer cancel()
+
+		plan, err := buildProductImportPlan(ctx, db, records, rawNumbers, time.Now())
+		if err != nil {
+			var headerErr importHeaderError
+			if errors.As(err, &headerErr) {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+			log.Printf("[%s] plan failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		summary := summarizeImport(plan.Report)
+		response := gin.H{
+			"dryRun":    dryRun,
+			"committed": false,
+			"summary":   summary,
+			"rows":      plan.Report,
+		}
+
+		if dryRun {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		if summary.Errors > 0 && !skipErrors {
+			c.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+
+		if len(plan.Writes) > 0 {
+			if err := commitProductImport(ctx, db, plan, stockActorFromContext(c)); err != nil {
+				log.Printf("[%s] commit failed: %v", route, err)
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+		}
+
+		log.Printf("[%s] imported created=%d updated=%d skipped=%d", route, summary.Created, summary.Updated, summary.Errors)
+		response["committed"] = true
+		productSearch.Invalidate()
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// commitProductImport writes the products and their stock movements in one
+// transaction. Current stock levels are read inside the transaction so the
+// recorded "before" values are the ones actually overwritten.
+func commitProductImport(ctx context.Context, db *mongo.Database, plan productImportPlan, actor models.StockActor) error {
+	return runInTransaction(ctx, db, func(sessCtx mongo.SessionContext) error {
+		before := map[primitive.ObjectID]float64{}
+		updatedIDs := make([]primitive.ObjectID, 0, len(plan.Stock))
+		for _, change := range plan.Stock {
+			if !change.Created {
+				updatedIDs = append(updatedIDs, change.ProductID)
+			}
+		}
+		if len(updatedIDs) > 0 {
+			cursor, err := db.Collection("products").Find(sessCtx,
+				bson.M{"_id": bson.M{"$in": updatedIDs}},
+				options.Find().SetProjection(bson.M{"stock": 1}),
+			)
+			if err != nil {
+				return err
+			}
+			var current []bson.M
+			if err := cursor.All(sessCtx, &current); err != nil {
+				return err
+			}
+			for _, doc := range current {
+				if id, ok := doc["_id"].(primitive.ObjectID); ok {
+					before[id] = parseLooseNumber(doc["stock"])
+				}
+			}
+		}
+
+		if _, err := db.Collection("products").BulkWrite(sessCtx, plan.Writes, options.BulkWrite().SetOrdered(true)); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, change := range plan.Stock {
+			previous := before[change.ProductID]
+			if previous == change.Stock {
+				continue
+			}
+			if err := recordStockMovement(sessCtx, db, &models.StockMovement{
+				ProductID: change.ProductID,
+				Type:      models.StockMovementImport,
+				Quantity:  roundQuantity(change.Stock - previous),
+				Before:    previous,
+				After:     change.Stock,
+				Reason:    "toplu içe aktarma",
+				Actor:     actor,
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type importHeaderError struct {
+	message string
+}
+
+func (e importHeaderError) Error() string {
+	return e.message
+}
+
+// buildProductImportPlan validates every row and prepares the writes without
+// touching the database beyond lookups, so dry runs and commits share it.
+func buildProductImportPlan(ctx context.Context, db *mongo.Database, records [][]string, rawNumbers bool, now time.Time) (productImportPlan, error) {
+	columns, err := mapImportHeader(records[0])
+	if err != nil {
+		return productImportPlan{}, err
+	}
+
+	categories, err := loadImportCategoryResolver(ctx, db)
+	if err != nil {
+		return productImportPlan{}, err
+	}
+
+	barcodes := make([]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if barcode := importCell(record, columns, "barcode"); barcode != "" {
+			barcodes = append(barcodes, barcode)
+		}
+	}
+	existingByBarcode, err := loadProductsByBarcode(ctx, db, barcodes)
+	if err != nil {
+		return productImportPlan{}, err
+	}
+
+	plan := productImportPlan{
+		Report: make([]productImportReportRow, 0, len(records)-1),
+		Writes: make([]mongo.WriteModel, 0, len(records)-1),
+	}
+	seenBarcodes := map[string]int{}
+
+	for index, record := range records[1:] {
+		rowNumber := index + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		fields, rowErrors := parseProductImportRecord(record, columns, rawNumbers)
+		row := productImportReportRow{Row: rowNumber, Barcode: fields.Barcode}
+		if fields.Name != nil {
+			row.Name = *fields.Name
+		}
+
+		if fields.Barcode != "" {
+			if firstRow, ok := seenBarcodes[fields.Barcode]; ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("duplicate barcode (first seen on row %d)", firstRow))
+			} else {
+				seenBarcodes[fields.Barcode] = rowNumber
+			}
+		}
+
+		var rowCategories categoryRefs
+		if len(fields.Categories) > 0 {
+			refs, err := categories.resolve(fields.Categories)
+			if err != nil {
+				rowErrors = append(rowErrors, err.Error())
+			}
+			rowCategories = refs
+		}
+
+		var write mongo.WriteModel
+		var stockChange *importStockChange
+		if existing, ok := existingByBarcode[fields.Barcode]; ok && fields.Barcode != "" {
+			row.Action = "update"
+			row.ProductID = existing.ID.Hex()
+			if row.Name == "" {
+				row.Name = existing.Name
+			}
+			set, errs := buildImportUpdate(existing, fields, rowCategories)
+			rowErrors = append(rowErrors, errs...)
+			if len(set) > 0 {
+				write = mongo.NewUpdateOneModel().
+					SetFilter(bson.M{"_id": existing.ID, "isDeleted": bson.M{"$ne": true}}).
+					SetUpdate(bson.M{"$set": set})
+			}
+			if fields.Stock != nil {
+				stockChange = &importStockChange{ProductID: existing.ID, Stock: *fields.Stock}
+			}
+		} else {
+			row.Action = "create"
+			product, errs := buildImportProduct(fields, rowCategories, now)
+			rowErrors = append(rowErrors, errs...)
+			if len(errs) == 0 {
+				product.ID = primitive.NewObjectID()
+				row.ProductID = product.ID.Hex()
+				write = mongo.NewInsertOneModel().SetDocument(product)
+				stockChange = &importStockChange{ProductID: product.ID, Stock: product.Stock, Created: true}
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			row.Action = "error"
+			row.Errors = rowErrors
+			row.ProductID = ""
+			if existing, ok := existingByBarcode[fields.Barcode]; ok {
+				row.ProductID = existing.ID.Hex()
+			}
+			write = nil
+		}
+		if row.Action == "update" && write == nil {
+			row.Action = "unchanged"
+		}
+
+		plan.Report = append(plan.Report, row)
+		if write != nil {
+			plan.Writes = append(plan.Writes, write)
+			if stockChange != nil {
+				plan.Stock = append(plan.Stock, *stockChange)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func buildImportProduct(fields productImportFields, categories categoryRefs, now time.Time) (models.Product, []string) {
+	var errs []string
+
+	name := ""
+	if fields.Name != nil {
+		name = *fields.Name
+	}
+	if name == "" {
+		errs = append(errs, "name required")
+	}
+
+	price := 0.0
+	if fields.Price == nil || *fields.Price <= 0 {
+		errs = append(errs, "invalid price")
+	} else {
+		price = *fields.Price
+	}
+
+	saleEnabled := fields.SaleEnabled != nil && *fields.SaleEnabled
+	salePrice := 0.0
+	if fields.SalePrice != nil {
+		salePrice = *fields.SalePrice
+	}
+	if price > 0 {
+		if err := validateSaleFields(price, saleEnabled, salePrice, fields.SalePrice != nil); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(fields.Categories) == 0 {
+		errs = append(errs, "category required")
+	}
+
+	unit := models.UnitPiece
+	if fields.Unit != nil {
+		unit = *fields.Unit
+	}
+
+	stock := 0.0
+	if fields.Stock == nil {
+		errs = append(errs, "stock required")
+	} else if *fields.Stock < 0 {
+		errs = append(errs, "stock must be zero or greater")
+	} else if err := validateQuantity(unit, *fields.Stock); err != nil {
+		errs = append(errs, "stock: "+err.Error())
+	} else {
+		stock = *fields.Stock
+	}
+
+	costPrice := 0.0
+	if fields.CostPrice != nil {
+		if *fields.CostPrice < 0 {
+			errs = append(errs, "costPrice must be zero or greater")
+		} else {
+			costPrice = roundMoney(*fields.CostPrice)
+		}
+	}
+
+	reorderLevel := 0.0
+	if fields.ReorderLevel != nil {
+		if *fields.ReorderLevel < 0 {
+			errs = append(errs, "reorderLevel must be zero or greater")
+		} else {
+			reorderLevel = *fields.ReorderLevel
+		}
+	}
+
+	isActive := true
+	if fields.IsActive != nil {
+		isActive = *fields.IsActive
+	}
+	isCampaign := fields.IsCampaign != nil && *fields.IsCampaign
+
+	product := models.Product{
+		Name:         name,
+		Price:        price,
+		SaleEnabled:  saleEnabled,
+		SalePrice:    salePrice,
+		Category:     models.StringList(categories.Names),
+		CategoryIDs:  categories.IDs,
+		Barcode:      fields.Barcode,
+		Unit:         unit,
+		Stock:        stock,
+		ReorderLevel: reorderLevel,
+		CostPrice:    costPrice,
+		IsActive:     isActive,
+		IsCampaign:   isCampaign,
+		IsDeleted:    false,
+		CreatedAt:    now,
+	}
+	if fields.Brand != nil {
+		product.Brand = *fields.Brand
+	}
+	if fields.Description != nil {
+		product.Description = *fields.Description
+	}
+	if fields.VariantLabel != nil {
+		product.VariantLabel = *fields.VariantLabel
+	}
+
+	return product, errs
+}
+
+func buildImportUpdate(existing models.Product, fields productImportFields, categories categoryRefs) (bson.M, []string) {
+	var errs []string
+	set := bson.M{}
+
+	if fields.Name != nil {
+		if *fields.Name == "" {
+			errs = append(errs, "name required")
+		} else {
+			set["name"] = *fields.Name
+		}
+	}
+	if fields.Price != nil {
+		if *fields.Price <= 0 {
+			errs = append(errs, "invalid price")
+		} else {
+			set["price"] = *fields.Price
+		}
+	}
+
+	saleUpdate, err := resolveSaleUpdate(existing.Price, existing.SaleEnabled, existing.SalePrice, saleUpdateInput{
+		Price:       fields.Price,
+		SaleEnabled: fields.SaleEnabled,
+		SalePrice:   fields.SalePrice,
+	})
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		if saleUpdate.SetSaleEnabled {
+			set["saleEnabled"] = saleUpdate.SaleEnabled
+		}
+		if saleUpdate.SetSalePrice {
+			set["salePrice"] = saleUpdate.SalePrice
+		}
+	}
+
+	if len(categories.IDs) > 0 {
+		categories.setOn(set)
+	}
+	if fields.Brand != nil {
+		set["brand"] = *fields.Brand
+	}
+	if fields.Description != nil {
+		set["description"] = *fields.Description
+	}
+	unit := existing.Unit
+	if fields.Unit != nil {
+		unit = *fields.Unit
+		set["unit"] = unit
+	}
+	if fields.VariantLabel != nil {
+		set["variantLabel"] = *fields.VariantLabel
+	}
+	if fields.Stock != nil {
+		if *fields.Stock < 0 {
+			errs = append(errs, "stock must be zero or greater")
+		} else if err := validateQuantity(unit, *fields.Stock); err != nil {
+			errs = append(errs, "stock: "+err.Error())
+		} else {
+			set["stock"] = *fields.Stock
+			set["inStock"] = *fields.Stock > 0
+		}
+	} else if fields.Unit != nil {
+		if err := validateQuantity(unit, existing.Stock); err != nil {
+			errs = append(errs, "stock: "+err.Error())
+		}
+	}
+	if fields.ReorderLevel != nil {
+		if *fields.ReorderLevel < 0 {
+			errs = append(errs, "reorderLevel must be zero or greater")
+		} else {
+			set["reorderLevel"] = *fields.ReorderLevel
+		}
+	}
+	if fields.CostPrice != nil {
+		if *fields.CostPrice < 0 {
+			errs = append(errs, "costPrice must be zero or greater")
+		} else {
+			set["costPrice"] = roundMoney(*fields.CostPrice)
+		}
+	}
+	if fields.IsActive != nil {
+		set["isActive"] = *fields.IsActive
+	}
+	if fields.IsCampaign != nil {
+		set["isCampaign"] = *fields.IsCampaign
+	}
+
+	return set, errs
+}
+
+func summarizeImport(rows []productImportReportRow) productImportSummary {
+	summary := productImportSummary{Rows: len(rows)}
+	for _, row := range rows {
+		switch row.Action {
+		case "create":
+			summary.Created++
+		case "update":
+			summary.Updated++
+		case "unchanged":
+			summary.Unchanged++
+		case "error":
+			summary.Errors++
+		}
+	}
+	return summary
+}
+
+// readImportRecords returns all rows (header first) of a CSV or XLSX file.
+// XLSX cells are read unformatted; the bool reports that, so numbers are
+// taken with "." as the decimal separator whatever the sheet's locale.
+func readImportRecords(filename string, r io.Reader) ([][]string, bool, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		data, err := io.ReadAll(io.LimitReader(r, maxImportFileSize+1))
+		if err != nil {
+			return nil, false, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.Comma = detectCSVDelimiter(data)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid csv: %v", err)
+		}
+		return records, false, nil
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid xlsx: %v", err)
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, false, errors.New("xlsx has no sheets")
+		}
+		rows, err := workbook.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid xlsx: %v", err)
+		}
+		return rows, true, nil
+	default:
+		return nil, false, errors.New("file must be .csv or .xlsx")
+	}
+}
+
+// detectCSVDelimiter picks ';' when the header uses it, as Excel does for
+// Turkish locales where ',' is the decimal separator.
+func detectCSVDelimiter(data []byte) rune {
+	firstLine, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		return ';'
+	}
+	return ','
+}
+
+func mapImportHeader(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for index, raw := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(raw, "\uFEFF")))
+		field, ok := importColumnAliases[key]
+		if !ok {
+			continue
+		}
+		if _, exists := columns[field]; exists {
+			return nil, importHeaderError{message: fmt.Sprintf("duplicate column for %s", field)}
+		}
+		columns[field] = index
+	}
+
+	if _, ok := columns["barcode"]; !ok {
+		if _, ok := columns["name"]; !ok {
+			return nil, importHeaderError{message: "header must contain at least a barcode or name column"}
+		}
+	}
+	return columns, nil
+}
+
+// importCell returns a trimmed cell, undoing the quote our CSV exports put
+// before formula-like text so an export can be imported again.
+func importCell(record []string, columns map[string]int, field string) string {
+	index, ok := columns[field]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	value := record[index]
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		value = value[1:]
+	}
+	return strings.TrimSpace(value)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseProductImportRecord converts one row to typed fields, collecting every
+// problem instead of stopping at the first so the report is complete.
+// rawNumbers is set for XLSX files; see readImportRecords.
+func parseProductImportRecord(record []string, columns map[string]int, rawNumbers bool) (productImportFields, []string) {
+	var errs []string
+	fields := productImportFields{Barcode: importCell(record, columns, "barcode")}
+
+	if value := importCell(record, columns, "unit"); value != "" {
+		unit, err := parseProductUnit(value)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			fields.Unit = &unit
+		}
+	}
+	kg := fields.Unit != nil && *fields.Unit == models.UnitKg
+
+	optionalString := func(field string) *string {
+		if _, ok := columns[field]; !ok {
+			return nil
+		}
+		value := importCell(record, columns, field)
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+	optionalFloat := func(field string, threeDecimals bool) *float64 {
+		value := importCell(record, columns, field)
+		if value == "" {
+			return nil
+		}
+		if rawNumbers {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				return &parsed
+			}
+		}
+		parsed, err := parseImportNumber(value, threeDecimals)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be a number", field))
+			return nil
+		}
+		return &parsed
+	}
+	optionalBool := func(field string) *bool {
+		value := importCell(record, columns, field)
+		if value == "" {
+			return nil
+		}
+		parsed, err := parseImportBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be boolean", field))
+			return nil
+		}
+		return &parsed
+	}
+
+	fields.Name = optionalString("name")
+	fields.Brand = optionalString("brand")
+	fields.Description = optionalString("description")
+	fields.VariantLabel = optionalString("variantLabel")
+	fields.Stock = optionalFloat("stock", kg)
+	fields.ReorderLevel = optionalFloat("reorderLevel", kg)
+	fields.Price = optionalFloat("price", false)
+	fields.SalePrice = optionalFloat("salePrice", false)
+	fields.CostPrice = optionalFloat("costPrice", false)
+	fields.SaleEnabled = optionalBool("saleEnabled")
+	fields.IsActive = optionalBool("isActive")
+	fields.IsCampaign = optionalBool("isCampaign")
+
+	if value := importCell(record, columns, "category"); value != "" {
+		for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == '|' || r == ';' }) {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				fields.Categories = append(fields.Categories, trimmed)
+			}
+		}
+	}
+
+	return fields, errs
+}
+
+// parseImportNumber accepts both "1,250.50" and the Turkish "1.250,50": when
+// both separators appear, the last one is the decimal separator. A single
+// separator followed by exactly three digits, as in "1.250", could be either
+// and is rejected, unless the integer part is 0 ("0,125") or threeDecimals
+// is set for kg quantities, which have up to three decimals.
+func parseImportNumber(value string, threeDecimals bool) (float64, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	lastDot, lastComma := strings.LastIndex(normalized, "."), strings.LastIndex(normalized, ",")
+
+	decimal, thousands := "", ""
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal, thousands = ".", ","
+		if lastComma > lastDot {
+			decimal, thousands = ",", "."
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		sep, last := ".", lastDot
+		if lastComma >= 0 {
+			sep, last = ",", lastComma
+		}
+		integer := strings.TrimLeft(normalized[:last], "+-")
+		switch {
+		case strings.Count(normalized, sep) > 1:
+			thousands = sep
+		case len(normalized)-last-1 == 3 && integer != "0" && integer != "" && !threeDecimals:
+			return 0, fmt.Errorf("ambiguous number %q", value)
+		default:
+			decimal = sep
+		}
+	}
+
+	integer, fraction, hasFraction := normalized, "", false
+	if decimal != "" {
+		if strings.Count(normalized, decimal) > 1 {
+			return 0, fmt.Errorf("invalid number %q", value)
+		}
+		integer, fraction, hasFraction = strings.Cut(normalized, decimal)
+	}
+	if thousands != "" {
+		groups := strings.Split(strings.TrimLeft(integer, "+-"), thousands)
+		for i, group := range groups {
+			if (i == 0 && (len(group) == 0 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+				return 0, fmt.Errorf("invalid thousands grouping in %q", value)
+			}
+		}
+		integer = strings.ReplaceAll(integer, thousands, "")
+	}
+	if hasFraction {
+		integer += "." + fraction
+	}
+	return strconv.ParseFloat(integer, 64)
+}
+
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "evet", "e", "var":
+		return true, nil
+	case "hayır", "hayir", "h", "yok":
+		return false, nil
+	}
+	return parseBoolValue(value)
+}
+
+func parseOptionalBoolQuery(c *gin.Context, key string) (bool, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be boolean", key)
+	}
+	return parsed, nil
+}
+
+// importCategoryResolver resolves category cells given either as names or
+// as ObjectIDs, mirroring resolveCategoriesByIDs: order is kept and unknown
+// values are an error.
+type importCategoryResolver struct {
+	byID   map[string]models.Category
+	byName map[string]models.Category
+}
+
+func loadImportCategoryResolver(ctx context.Context, db *mongo.Database) (importCategoryResolver, error) {
+	cursor, err := db.Collection("categories").Find(ctx, bson.M{})
+	if err != nil {
+		return importCategoryResolver{}, err
+	}
+	var categories []models.Category
+	if err := cursor.All(ctx, &categories); err != nil {
+		return importCategoryResolver{}, err
+	}
+
+	resolver := importCategoryResolver{
+		byID:   make(map[string]models.Category, len(categories)),
+		byName: make(map[string]models.Category, len(categories)),
+	}
+	for _, category := range categories {
+		resolver.byID[category.ID.Hex()] = category
+		resolver.byName[search.Key(category.Name)] = category
+	}
+	return resolver, nil
+}
+
+func (r importCategoryResolver) resolve(values []string) (categoryRefs, error) {
+	categories := make([]models.Category, 0, len(values))
+	for _, value := range values {
+		if category, ok := r.byID[value]; ok {
+			categories = append(categories, category)
+			continue
+		}
+		if category, ok := r.byName[search.Key(value)]; ok {
+			categories = append(categories, category)
+			continue
+		}
+		return categoryRefs{}, fmt.Errorf("category not found: %s", value)
+	}
+	return refsOf(categories), nil
+}
+
+func loadProductsByBarcode(ctx context.Context, db *mongo.Database, barcodes []string) (map[string]models.Product, error) {
+	result := map[string]models.Product{}
+	if len(barcodes) == 0 {
+		return result, nil
+	}
+
+	cursor, err := db.Collection("products").Find(ctx, bson.M{
+		"barcode":   bson.M{"$in": barcodes},
+		"isDeleted": bson.M{"$ne": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products, err := decodeProducts(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if _, exists := result[product.Barcode]; !exists {
+			result[product.Barcode] = product
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+func TestReadImportRecordsDetectsSemicolonCSV(t *testing.T) {
+	data := "\xEF\xBB\xBFBarkod;Ad;Fiyat;Stok\n869001;Süt 1L;34,90;12\n"
+
+	records, rawNumbers, err := readImportRecords("urunler.CSV", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("readImportRecords returned error: %v", err)
+	}
+	if len(records) != 2 || len(records[0]) != 4 {
+		t.Fatalf("unexpected records %#v", records)
+	}
+	if records[0][0] != "Barkod" {
+		t.Fatalf("expected BOM to be stripped, got %q", records[0][0])
+	}
+
+	if rawNumbers {
+		t.Fatalf("csv numbers must go through separator detection")
+	}
+	if _, _, err := readImportRecords("urunler.txt", strings.NewReader(data)); err == nil {
+		t.Fatalf("expected unsupported extension to fail")
+	}
+}
+
+func TestParseProductImportRecord(t *testing.T) {
+	columns, err := mapImportHeader([]string{"Barkod", "Ad", "Fiyat", "Stok", "Kategori", "Aktif", "Açıklama"})
+	if err != nil {
+		t.Fatalf("mapImportHeader returned error: %v", err)
+	}
+
+	fields, errs := parseProductImportRecord([]string{" 869001 ", "Süt 1L", "1.234,50", "12", "Süt | Kahvaltılık", "evet", ""}, columns, false)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if fields.Barcode != "869001" || fields.Name == nil || *fields.Name != "Süt 1L" {
+		t.Fatalf("unexpected identity fields %#v", fields)
+	}
+	if fields.Price == nil || *fields.Price != 1234.5 {
+		t.Fatalf("expected Turkish decimal to parse, got %v", fields.Price)
+	}
+	if fields.Stock == nil || *fields.Stock != 12 {
+		t.Fatalf("unexpected stock %v", fields.Stock)
+	}
+	if fields.IsActive == nil || !*fields.IsActive {
+		t.Fatalf("expected isActive true")
+	}
+	if fields.Description != nil {
+		t.Fatalf("expected empty cell to leave description unset")
+	}
+	if len(fields.Categories) != 2 || fields.Categories[1] != "Kahvaltılık" {
+		t.Fatalf("unexpected categories %v", fields.Categories)
+	}
+
+	_, errs = parseProductImportRecord([]string{"1", "x", "abc", "bir", "", "belki", ""}, columns, false)
+	if len(errs) != 3 {
+		t.Fatalf("expected price, stock and isActive errors, got %v", errs)
+	}
+}
+
+func TestMapImportHeaderRequiresIdentityColumn(t *testing.T) {
+	if _, err := mapImportHeader([]string{"Fiyat", "Stok"}); err == nil {
+		t.Fatalf("expected header without barcode or name to fail")
+	}
+	if _, err := mapImportHeader([]string{"barcode", "Barkod"}); err == nil {
+		t.Fatalf("expected duplicate columns to fail")
+	}
+}
+
+func TestImportCategoryResolver(t *testing.T) {
+	sut := models.Category{ID: primitive.NewObjectID(), Name: "Süt"}
+	kahvalti := models.Category{ID: primitive.NewObjectID(), Name: "Kahvaltılık"}
+	icecek := models.Category{ID: primitive.NewObjectID(), Name: "İçecek"}
+	resolver := importCategoryResolver{
+		byID:   map[string]models.Category{sut.ID.Hex(): sut},
+		byName: map[string]models.Category{search.Key(kahvalti.Name): kahvalti, search.Key(icecek.Name): icecek},
+	}
+
+	refs, err := resolver.resolve([]string{"KAHVALTILIK", sut.ID.Hex(), "Kahvaltılık", "içecek"})
+	if err != nil {
+		t.Fatalf("resolve returned error: %v", err)
+	}
+	if strings.Join(refs.Names, ",") != "Kahvaltılık,Süt,İçecek" {
+		t.Fatalf("unexpected names %v", refs.Names)
+	}
+	if len(refs.IDs) != 3 || refs.IDs[0] != kahvalti.ID || refs.IDs[1] != sut.ID {
+		t.Fatalf("unexpected ids %v", refs.IDs)
+	}
+
+	if _, err := resolver.resolve([]string{"Yok"}); err == nil {
+		t.Fatalf("expected unknown category to fail")
+	}
+}
+
+func TestParseImportNumber(t *testing.T) {
+	cases := []struct {
+		value string
+		want  float64
+		ok    bool
+	}{
+		{value: "1.250,50", want: 1250.5, ok: true},
+		{value: "1,250.50", want: 1250.5, ok: true},
+		{value: "12,5", want: 12.5, ok: true},
+		{value: "1250", want: 1250, ok: true},
+		{value: "34,90", want: 34.9, ok: true},
+		{value: "12.50", want: 12.5, ok: true},
+		{value: "1.250.000", want: 1250000, ok: true},
+		{value: "0.125", want: 0.125, ok: true},
+		{value: "-0,125", want: -0.125, ok: true},
+		{value: "1.250", ok: false}, // 1250 or 1.25
+		{value: "1,250", ok: false},
+		{value: "1.25.0,5", ok: false},
+		{value: "1,2,5", ok: false},
+		{value: "abc", ok: false},
+	}
+	for _, tc := range cases {
+		got, err := parseImportNumber(tc.value, false)
+		if (err == nil) != tc.ok || (tc.ok && got != tc.want) {
+			t.Errorf("parseImportNumber(%q) = %v, %v; want %v, ok=%v", tc.value, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseImportRecordKgQuantities(t *testing.T) {
+	columns, err := mapImportHeader([]string{"Barkod", "Ad", "Fiyat", "Stok", "Birim"})
+	if err != nil {
+		t.Fatalf("mapImportHeader returned error: %v", err)
+	}
+
+	fields, errs := parseProductImportRecord([]string{"869002", "Peynir", "250,00", "2,500", "kg"}, columns, false)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if fields.Stock == nil || *fields.Stock != 2.5 {
+		t.Fatalf("kg stock 2,500 should be 2.5, got %v", fields.Stock)
+	}
+
+	_, errs = parseProductImportRecord([]string{"869003", "Süt", "34,90", "2,500", "adet"}, columns, false)
+	if len(errs) != 1 {
+		t.Fatalf("piece stock 2,500 is ambiguous, got %v", errs)
+	}
+}
+
+func TestReadImportRecordsUsesRawXLSXNumbers(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	sheet := file.GetSheetName(0)
+	if err := file.SetSheetRow(sheet, "A1", &[]interface{}{"Barkod", "Ad", "Fiyat", "Stok", "Birim"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.SetSheetRow(sheet, "A2", &[]interface{}{"869004", "Zeytin", 1250.5, 0.125, "kg"}); err != nil {
+		t.Fatal(err)
+	}
+	// Displayed as "1.250,50" and "0,125" in a Turkish sheet.
+	style, err := file.NewStyle(&excelize.Style{NumFmt: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := file.SetCellStyle(sheet, "C2", "D2", style); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, rawNumbers, err := readImportRecords("urunler.xlsx", &buf)
+	if err != nil || !rawNumbers || len(records) != 2 {
+		t.Fatalf("unexpected records %v raw=%v err=%v", records, rawNumbers, err)
+	}
+	columns, err := mapImportHeader(records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, errs := parseProductImportRecord(records[1], columns, rawNumbers)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if *fields.Price != 1250.5 || *fields.Stock != 0.125 {
+		t.Fatalf("expected raw cell values, got price %v stock %v", *fields.Price, *fields.Stock)
+	}
+}
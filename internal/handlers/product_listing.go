@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// productListParams are the storefront listing filters. Brand, category
+// and price are facet filters: each facet's counts ignore its own filter so
+// the client can offer the other values too.
+type productListParams struct {
+	Categories []string
+	// CategoryIDs and CategoryNames are Categories resolved with their
+	// subcategories; see expandCategoryFilter. The names match products
+	// that have no categoryIds yet.
+	CategoryIDs   []primitive.ObjectID
+	CategoryNames []string
+	Brands        []string
+	MinPrice      *float64
+	MaxPrice      *float64
+	OnSale        bool
+	InStock       bool
+	Sort          string
+}
+
+const (
+	sortNewest    = "newest"
+	sortPriceAsc  = "price_asc"
+	sortPriceDesc = "price_desc"
+	sortName      = "name"
+	sortPopular   = "popular"
+	sortRelevance = "relevance"
+)
+
+var validProductSorts = map[string]struct{}{
+	sortNewest: {}, sortPriceAsc: {}, sortPriceDesc: {}, sortName: {}, sortPopular: {}, sortRelevance: {},
+}
+
+// priceBucketBounds are the lower bounds of the price facet buckets; the
+// last bucket is open-ended.
+var priceBucketBounds = []float64{0, 25, 50, 100, 250, 500, 1000}
+
+const facetValueLimit = 50
+
+// onSaleExpr and effectivePriceExpr mirror isProductOnSale and
+// effectiveProductPrice inside the database.
+var onSaleExpr = bson.M{"$and": bson.A{
+	bson.M{"$eq": bson.A{"$saleEnabled", true}},
+	bson.M{"$gt": bson.A{"$salePrice", 0}},
+	bson.M{"$lt": bson.A{"$salePrice", "$price"}},
+}}
+
+var effectivePriceExpr = bson.M{"$cond": bson.A{onSaleExpr, "$salePrice", "$price"}}
+
+// queryList reads a repeatable, comma-separable parameter:
+// ?brand=a&brand=b or ?brand=a,b.
+func queryList(query url.Values, key string) []string {
+	var out []string
+	for _, raw := range query[key] {
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func parseProductListParams(query url.Values) (productListParams, error) {
+	params := productListParams{
+		Categories: queryList(query, "category"),
+		Brands:     queryList(query, "brand"),
+		Sort:       strings.TrimSpace(query.Get("sort")),
+	}
+
+	for key, target := range map[string]**float64{"minPrice": &params.MinPrice, "maxPrice": &params.MaxPrice} {
+		raw := strings.TrimSpace(query.Get(key))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+		if err != nil || value < 0 {
+			return params, fmt.Errorf("invalid %s", key)
+		}
+		*target = &value
+	}
+	if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
+		return params, errors.New("minPrice must not exceed maxPrice")
+	}
+
+	for key, target := range map[string]*bool{"onSale": &params.OnSale, "inStock": &params.InStock} {
+		if raw := strings.TrimSpace(query.Get(key)); raw != "" {
+			value, err := parseBoolValue(raw)
+			if err != nil {
+				return params, fmt.Errorf("%s must be boolean", key)
+			}
+			*target = value
+		}
+	}
+
+	if params.Sort != "" {
+		if _, ok := validProductSorts[params.Sort]; !ok {
+			return params, fmt.Errorf("invalid sort %q", params.Sort)
+		}
+	}
+	return params, nil
+}
+
+// applyTo adds the non-facet filters to the base filter.
+func (p productListParams) applyTo(filter bson.M) {
+	if p.OnSale {
+		filter["$expr"] = onSaleExpr
+	}
+	if p.InStock {
+		filter["stock"] = bson.M{"$gt": 0}
+	}
+}
+
+// facetMatch is the brand/category/price filter without the one named by
+// skip ("brand", "category" or "price").
+func (p productListParams) facetMatch(skip string) bson.M {
+	match := bson.M{}
+	if skip != "brand" && len(p.Brands) > 0 {
+		match["brand"] = bson.M{"$in": p.Brands}
+	}
+	if skip != "category" && len(p.Categories) > 0 {
+		match["$or"] = bson.A{
+			bson.M{"categoryIds": bson.M{"$in": p.CategoryIDs}},
+			bson.M{"categoryIds.0": bson.M{"$exists": false}, "category": bson.M{"$in": p.CategoryNames}},
+		}
+	}
+	if skip != "price" {
+		price := bson.M{}
+		if p.MinPrice != nil {
+			price["$gte"] = *p.MinPrice
+		}
+		if p.MaxPrice != nil {
+			price["$lte"] = *p.MaxPrice
+		}
+		if len(price) > 0 {
+			match["_effectivePrice"] = price
+		}
+	}
+	return match
+}
+
+func (p productListParams) sortStage() bson.D {
+	switch p.Sort {
+	case sortPriceAsc:
+		return bson.D{{Key: "_effectivePrice", Value: 1}, {Key: "_id", Value: 1}}
+	case sortPriceDesc:
+		return bson.D{{Key: "_effectivePrice", Value: -1}, {Key: "_id", Value: 1}}
+	case sortName:
+		return bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}
+	case sortPopular:
+		return bson.D{{Key: "soldCount", Value: -1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}
+	default:
+		return bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}
+	}
+}
+
+// productListPage selects what the "data" facet returns.
+type productListPage struct {
+	Page  int64
+	Limit int64
+	// Ranked returns only the IDs of all matches; the caller orders and
+	// pages them by search relevance.
+	Ranked bool
+	// Cursor switches from skip to keyset paging: the page comes from
+	// buildProductPagePipeline and the $facet stage only counts.
+	Cursor bool
+	// After (nil on the first page) filters to the documents past the
+	// cursor.
+	After bson.M
+	// SkipFacets leaves out the total and the brand, category and price
+	// counts; later cursor pages do not recompute them.
+	SkipFacets bool
+}
+
+// buildProductPagePipeline returns one keyset page: one extra document tells
+// whether there is a next page, and each document carries its sort key
+// values in "_cursor". The cursor $match and $sort come right after the
+// filter so they can use an index; only the price sort has to wait for
+// _effectivePrice.
+func buildProductPagePipeline(filter bson.M, p productListParams, window productListPage) mongo.Pipeline {
+	sortKeys := p.sortStage()
+	keyset := mongo.Pipeline{}
+	if window.After != nil {
+		keyset = append(keyset, bson.D{{Key: "$match", Value: window.After}})
+	}
+	keyset = append(keyset, bson.D{{Key: "$sort", Value: sortKeys}})
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	byPrice := sortKeys[0].Key == "_effectivePrice"
+	if !byPrice {
+		pipeline = append(pipeline, keyset...)
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$addFields", Value: bson.M{"_effectivePrice": effectivePriceExpr}}},
+		bson.D{{Key: "$match", Value: p.facetMatch("")}},
+	)
+	if byPrice {
+		pipeline = append(pipeline, keyset...)
+	}
+
+	keyValues := bson.A{}
+	for _, key := range sortKeys {
+		if key.Key != "_id" {
+			keyValues = append(keyValues, "$"+key.Key)
+		}
+	}
+	return append(pipeline,
+		bson.D{{Key: "$limit", Value: window.Limit + 1}},
+		bson.D{{Key: "$addFields", Value: bson.M{"_cursor": keyValues}}},
+		bson.D{{Key: "$project", Value: productListProjection()}},
+	)
+}
+
+// productListProjection drops the computed price and the fields the
+// storefront must not see.
+func productListProjection() bson.M {
+	projection := bson.M{"_effectivePrice": 0}
+	for field := range publicProductProjection {
+		projection[field] = 0
+	}
+	return projection
+}
+
+// buildProductListPipeline matches, then computes the page and every facet in
+// one $facet stage. In cursor mode the page is left out; see
+// buildProductPagePipeline.
+func buildProductListPipeline(filter bson.M, p productListParams, window productListPage) mongo.Pipeline {
+	matched := p.facetMatch("")
+	facets := bson.M{
+		"total": bson.A{bson.M{"$match": matched}, bson.M{"$count": "count"}},
+		"brands": bson.A{
+			bson.M{"$match": p.facetMatch("brand")},
+			bson.M{"$match": bson.M{"brand": bson.M{"$nin": bson.A{nil, ""}}}},
+			bson.M{"$group": bson.M{"_id": "$brand", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": facetValueLimit},
+		},
+		"categories": bson.A{
+			bson.M{"$match": p.facetMatch("category")},
+			bson.M{"$project": bson.M{"ref": categoryRefsExpr}},
+			bson.M{"$unwind": "$ref"},
+			bson.M{"$group": bson.M{"_id": "$ref", "count": bson.M{"$sum": 1}}},
+			bson.M{"$lookup": bson.M{"from": "categories", "localField": "_id.id", "foreignField": "_id", "as": "byId"}},
+			bson.M{"$lookup": bson.M{"from": "categories", "localField": "_id.name", "foreignField": "name", "as": "byName"}},
+			bson.M{"$addFields": bson.M{"category": bson.M{"$arrayElemAt": bson.A{bson.M{"$concatArrays": bson.A{"$byId", "$byName"}}, 0}}}},
+			bson.M{"$match": bson.M{"$or": bson.A{
+				bson.M{"category": bson.M{"$exists": true}},
+				bson.M{"_id.name": bson.M{"$exists": true}},
+			}}},
+			bson.M{"$group": bson.M{
+				"_id":   bson.M{"$ifNull": bson.A{"$category._id", "$_id.name"}},
+				"name":  bson.M{"$first": bson.M{"$ifNull": bson.A{"$category.name", "$_id.name"}}},
+				"id":    bson.M{"$first": "$category._id"},
+				"slug":  bson.M{"$first": "$category.slug"},
+				"count": bson.M{"$sum": "$count"},
+			}},
+			bson.M{"$project": bson.M{"_id": "$name", "id": 1, "slug": 1, "count": 1}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": facetValueLimit},
+		},
+		"prices": bson.A{
+			bson.M{"$match": p.facetMatch("price")},
+			bson.M{"$bucket": bson.M{
+				"groupBy":    "$_effectivePrice",
+				"boundaries": priceBucketBounds,
+				"default":    "above",
+				"output":     bson.M{"count": bson.M{"$sum": 1}},
+			}},
+		},
+	}
+
+	if window.SkipFacets {
+		facets = bson.M{}
+	}
+	switch {
+	case window.Ranked:
+		facets["data"] = bson.A{bson.M{"$match": matched}, bson.M{"$project": bson.M{"_id": 1}}}
+	case !window.Cursor:
+		facets["data"] = bson.A{
+			bson.M{"$match": matched},
+			bson.M{"$sort": p.sortStage()},
+			bson.M{"$skip": (window.Page - 1) * window.Limit},
+			bson.M{"$limit": window.Limit},
+			bson.M{"$project": productListProjection()},
+		}
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"_effectivePrice": effectivePriceExpr}}},
+		{{Key: "$facet", Value: facets}},
+	}
+}
+
+// categoryRefsExpr lists a product's categories for the category facet:
+// {id} for each of its categoryIds or, for products not linked yet, {name}
+// for each legacy category name.
+var categoryRefsExpr = bson.M{"$cond": bson.A{
+	bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$categoryIds", bson.A{}}}}, 0}},
+	bson.M{"$map": bson.M{"input": "$categoryIds", "in": bson.M{"id": "$$this"}}},
+	bson.M{"$map": bson.M{
+		"input": bson.M{"$filter": bson.M{
+			"input": bson.M{"$let": bson.M{
+				"vars": bson.M{"names": bson.M{"$ifNull": bson.A{"$category", bson.A{}}}},
+				"in":   bson.M{"$cond": bson.A{bson.M{"$isArray": "$$names"}, "$$names", bson.A{"$$names"}}},
+			}},
+			"cond": bson.M{"$and": bson.A{bson.M{"$eq": bson.A{bson.M{"$type": "$$this"}, "string"}}, bson.M{"$ne": bson.A{"$$this", ""}}}},
+		}},
+		"in": bson.M{"name": "$$this"},
+	}},
+}}
+
+// facetCount is a brand or category value; categories also carry their ID
+// and slug.
+type facetCount struct {
+	Value string              `bson:"_id" json:"value"`
+	ID    *primitive.ObjectID `bson:"id,omitempty" json:"id,omitempty"`
+	Slug  string              `bson:"slug,omitempty" json:"slug,omitempty"`
+	Count int64               `bson:"count" json:"count"`
+}
+
+type priceFacet struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+type productFacets struct {
+	Brands     []facetCount `json:"brands"`
+	Categories []facetCount `json:"categories"`
+	Prices     []priceFacet `json:"prices"`
+}
+
+// productListResult is the single document the $facet stage returns.
+type productListResult struct {
+	Data  []bson.M `bson:"data"`
+	Total []struct {
+		Count int64 `bson:"count"`
+	} `bson:"total"`
+	Brands     []facetCount `bson:"brands"`
+	Categories []facetCount `bson:"categories"`
+	Prices     []struct {
+		ID    interface{} `bson:"_id"`
+		Count int64       `bson:"count"`
+	} `bson:"prices"`
+}
+
+func (r productListResult) total() int64 {
+	if len(r.Total) == 0 {
+		return 0
+	}
+	return r.Total[0].Count
+}
+
+// facets turns the $bucket output (keyed by lower bound, or "above") into
+// min/max ranges, listing empty buckets too.
+func (r productListResult) facets() productFacets {
+	counts := map[float64]int64{}
+	var above int64
+	for _, bucket := range r.Prices {
+		if _, ok := bucket.ID.(string); ok {
+			above = bucket.Count
+			continue
+		}
+		counts[parseLooseNumber(bucket.ID)] = bucket.Count
+	}
+
+	prices := make([]priceFacet, 0, len(priceBucketBounds))
+	for i, min := range priceBucketBounds {
+		facet := priceFacet{Min: min, Count: counts[min]}
+		if i+1 < len(priceBucketBounds) {
+			max := priceBucketBounds[i+1]
+			facet.Max = &max
+		} else {
+			facet.Count = above
+		}
+		prices = append(prices, facet)
+	}
+
+	out := productFacets{Brands: r.Brands, Categories: r.Categories, Prices: prices}
+	if out.Brands == nil {
+		out.Brands = []facetCount{}
+	}
+	if out.Categories == nil {
+		out.Categories = []facetCount{}
+	}
+	return out
+}
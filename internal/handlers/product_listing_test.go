@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseProductListParams(t *testing.T) {
+	params, err := parseProductListParams(url.Values{
+		"category": {"Süt Ürünleri,Kahvaltılık", "İçecek"},
+		"brand":    {"Pınar"},
+		"minPrice": {"10,5"},
+		"maxPrice": {"100"},
+		"onSale":   {"true"},
+		"sort":     {"price_asc"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params.Categories) != 3 || params.Categories[2] != "İçecek" {
+		t.Fatalf("categories: %v", params.Categories)
+	}
+	if *params.MinPrice != 10.5 || *params.MaxPrice != 100 || !params.OnSale || params.InStock {
+		t.Fatalf("unexpected params: %+v", params)
+	}
+
+	for _, bad := range []url.Values{
+		{"minPrice": {"50"}, "maxPrice": {"10"}},
+		{"minPrice": {"-1"}},
+		{"sort": {"cheapest"}},
+		{"inStock": {"maybe"}},
+	} {
+		if _, err := parseProductListParams(bad); err == nil {
+			t.Fatalf("expected %v to be rejected", bad)
+		}
+	}
+}
+
+func TestFacetMatchLeavesOutItsOwnFilter(t *testing.T) {
+	min := 20.0
+	params := productListParams{Brands: []string{"Pınar"}, Categories: []string{"Süt"}, MinPrice: &min}
+
+	all := params.facetMatch("")
+	if len(all) != 3 {
+		t.Fatalf("expected brand, category and price, got %v", all)
+	}
+	category, ok := all["$or"].(bson.A)
+	if !ok || len(category) != 2 {
+		t.Fatalf("category filter must also match unlinked products by name: %v", all)
+	}
+	brands := params.facetMatch("brand")
+	if _, ok := brands["brand"]; ok || len(brands) != 2 {
+		t.Fatalf("brand facet must ignore the brand filter: %v", brands)
+	}
+	if price := params.facetMatch("price"); price["_effectivePrice"] != nil {
+		t.Fatalf("price facet must ignore the price filter: %v", price)
+	}
+
+	filter := bson.M{}
+	productListParams{OnSale: true, InStock: true}.applyTo(filter)
+	if filter["$expr"] == nil || filter["stock"] == nil {
+		t.Fatalf("onSale/inStock not applied: %v", filter)
+	}
+}
+
+func TestPriceFacetsListEveryBucket(t *testing.T) {
+	result := productListResult{}
+	result.Prices = append(result.Prices,
+		struct {
+			ID    interface{} `bson:"_id"`
+			Count int64       `bson:"count"`
+		}{ID: 25.0, Count: 4},
+		struct {
+			ID    interface{} `bson:"_id"`
+			Count int64       `bson:"count"`
+		}{ID: "above", Count: 2},
+	)
+
+	prices := result.facets().Prices
+	if len(prices) != len(priceBucketBounds) {
+		t.Fatalf("expected %d buckets, got %d", len(priceBucketBounds), len(prices))
+	}
+	if prices[1].Min != 25 || *prices[1].Max != 50 || prices[1].Count != 4 {
+		t.Fatalf("unexpected 25-50 bucket: %+v", prices[1])
+	}
+	last := prices[len(prices)-1]
+	if last.Max != nil || last.Count != 2 {
+		t.Fatalf("unexpected open bucket: %+v", last)
+	}
+	if prices[0].Count != 0 {
+		t.Fatalf("empty bucket must report zero: %+v", prices[0])
+	}
+}
+
+func TestProductPagePipelineSortsBeforeFacets(t *testing.T) {
+	after := bson.M{"$or": bson.A{}}
+	window := productListPage{Limit: 20, Cursor: true, After: after}
+
+	stages := func(pipeline mongo.Pipeline) []string {
+		var names []string
+		for _, stage := range pipeline {
+			names = append(names, stage[0].Key)
+		}
+		return names
+	}
+
+	newest := stages(buildProductPagePipeline(bson.M{}, productListParams{Sort: sortNewest}, window))
+	want := []string{"$match", "$match", "$sort", "$addFields", "$match", "$limit", "$addFields", "$project"}
+	if !reflect.DeepEqual(newest, want) {
+		t.Fatalf("cursor match and sort must follow the filter: %v", newest)
+	}
+	byPrice := stages(buildProductPagePipeline(bson.M{}, productListParams{Sort: sortPriceAsc}, window))
+	if byPrice[1] != "$addFields" || byPrice[4] != "$sort" {
+		t.Fatalf("price sort needs _effectivePrice first: %v", byPrice)
+	}
+
+	facet := buildProductListPipeline(bson.M{}, productListParams{}, window)[2][0].Value.(bson.M)
+	if _, ok := facet["data"]; ok {
+		t.Fatal("cursor pages must not be computed inside $facet")
+	}
+}
+
+func TestProductListPipelineSkipsFacets(t *testing.T) {
+	window := productListPage{Limit: 20, Ranked: true, Cursor: true, SkipFacets: true}
+	facet := buildProductListPipeline(bson.M{}, productListParams{}, window)[2][0].Value.(bson.M)
+	if _, ok := facet["data"]; !ok || len(facet) != 1 {
+		t.Fatalf("later ranked pages must only compute data: %v", facet)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+func hitObjectIDs(hits []search.Hit) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(hits))
+	for _, hit := range hits {
+		if id, err := primitive.ObjectIDFromHex(hit.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// rankObjectIDs orders ids like the search hits. IDs the index did not
+// return are dropped.
+func rankObjectIDs(ids []primitive.ObjectID, hits []search.Hit) []primitive.ObjectID {
+	present := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		present[id] = true
+	}
+	ordered := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range hitObjectIDs(hits) {
+		if present[id] {
+			ordered = append(ordered, id)
+			delete(present, id)
+		}
+	}
+	return ordered
+}
+
+// orderProductsByIDs returns products in the order of ids.
+func orderProductsByIDs(products []models.Product, ids []primitive.ObjectID) []models.Product {
+	byID := make(map[primitive.ObjectID]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	ordered := make([]models.Product, 0, len(products))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			ordered = append(ordered, product)
+		}
+	}
+	return ordered
+}
+
+// pageOf slices an in-memory result for page/limit.
+func pageOf[T any](items []T, page, limit int64) []T {
+	start := (page - 1) * limit
+	if start >= int64(len(items)) {
+		return []T{}
+	}
+	end := start + limit
+	if end > int64(len(items)) {
+		end = int64(len(items))
+	}
+	return items[start:end]
+}
+
+// rankedPageAfter is the cursor page of ids, which are already in relevance
+// order: the limit IDs after the cursor's product. A cursor whose product no
+// longer matches cannot be continued.
+func rankedPageAfter(ids []primitive.ObjectID, after *pageCursor, limit int64, sortName string) ([]primitive.ObjectID, *pageCursor, error) {
+	start := 0
+	if after != nil {
+		start = -1
+		for i, id := range ids {
+			if id == after.ID {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, nil, errors.New("cursor expired, restart from the first page")
+		}
+	}
+
+	page := ids[start:]
+	if int64(len(page)) <= limit {
+		return page, nil, nil
+	}
+	page = page[:limit]
+	return page, &pageCursor{Sort: sortName, Values: bson.A{}, ID: page[len(page)-1]}, nil
+}
+
+const (
+	defaultSuggestLimit = 5
+	maxSuggestLimit     = 10
+)
+
+/*
+GET /products/suggest?q=
+- Yazılan metinle başlayan ürün adları, markalar ve kategoriler
+- Bellekteki arama indeksinden döner, veritabanına gitmez
+- Pasif, silinmiş ürünler ve varyantlar önerilmez
+- ?limit= her grup için (varsayılan 5, en fazla 10)
+*/
+func SuggestProducts(productSearch *search.Index) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /products/suggest"
+
+		limit := defaultSuggestLimit
+		if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				respondWithError(c, http.StatusBadRequest, route, "invalid limit")
+				return
+			}
+			limit = parsed
+		}
+		if limit > maxSuggestLimit {
+			limit = maxSuggestLimit
+		}
+
+		c.JSON(http.StatusOK, productSearch.Suggest(c.Query("q"), limit))
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+func TestRankObjectIDsFollowsHitsAndPages(t *testing.T) {
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	hits := []search.Hit{{ID: c.Hex()}, {ID: primitive.NewObjectID().Hex()}, {ID: a.Hex()}}
+
+	ranked := rankObjectIDs([]primitive.ObjectID{a, b, c}, hits)
+	if len(ranked) != 2 || ranked[0] != c || ranked[1] != a {
+		t.Fatalf("unexpected order: %v", ranked)
+	}
+	if page := pageOf(ranked, 2, 1); len(page) != 1 || page[0] != a {
+		t.Fatalf("unexpected page: %v", page)
+	}
+	if page := pageOf(ranked, 3, 1); len(page) != 0 {
+		t.Fatalf("expected an empty page, got %v", page)
+	}
+
+	products := orderProductsByIDs([]models.Product{{ID: a}, {ID: c}}, ranked)
+	if len(products) != 2 || products[0].ID != c {
+		t.Fatalf("products not in rank order: %+v", products)
+	}
+}
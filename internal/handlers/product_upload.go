@@ -2,19 +2,15 @@ package handlers
 
 import (
 	"errors"
-	"fmt"
-	"io"
 	"log"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+	"backend/internal/storage"
 )
 
 /*
@@ -24,28 +20,37 @@ import (
 */
 
 type MultipartProductInput struct {
-	Name           string
-	NameSet        bool
-	Price          float64
-	PriceSet       bool
-	CategoryIDs    []string
-	CategoryIDSet  bool
-	Description    string
-	DescriptionSet bool
-	Barcode        string
-	BarcodeSet     bool
-	Brand          string
-	BrandSet       bool
-	ImagePath      string
-	ImageSet       bool
-	Stock          int
-	StockSet       bool
-	InStock        bool
-	InStockSet     bool
-	IsActive       bool
-	IsActiveSet    bool
-	IsCampaign     bool
-	IsCampaignSet  bool
+	Name            string
+	NameSet         bool
+	Price           float64
+	PriceSet        bool
+	CategoryIDs     []string
+	CategoryIDSet   bool
+	Description     string
+	DescriptionSet  bool
+	Barcode         string
+	BarcodeSet      bool
+	Brand           string
+	BrandSet        bool
+	CoverImage      *models.ProductImage
+	Images          []models.ProductImage
+	ImageSet        bool
+	Stock           float64
+	StockSet        bool
+	Unit            string
+	UnitSet         bool
+	VariantLabel    string
+	VariantLabelSet bool
+	ReorderLevel    float64
+	ReorderLevelSet bool
+	CostPrice       float64
+	CostPriceSet    bool
+	InStock         bool
+	InStockSet      bool
+	IsActive        bool
+	IsActiveSet     bool
+	IsCampaign      bool
+	IsCampaignSet   bool
 
 	SaleEnabled    bool
 	SaleEnabledSet bool
@@ -59,7 +64,7 @@ type MultipartProductInput struct {
 =======================
 */
 
-func parseMultipartProductRequest(c *gin.Context) (MultipartProductInput, error) {
+func parseMultipartProductRequest(c *gin.Context, uploads storage.Storage) (MultipartProductInput, error) {
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
 		log.Println("PARSE ERROR:", err)
 		return MultipartProductInput{}, err
@@ -89,6 +94,20 @@ func parseMultipartProductRequest(c *gin.Context) (MultipartProductInput, error)
 		input.BrandSet = true
 	}
 
+	if value, ok := c.GetPostForm("unit"); ok {
+		unit, err := parseProductUnit(value)
+		if err != nil {
+			return MultipartProductInput{}, err
+		}
+		input.Unit = unit
+		input.UnitSet = true
+	}
+
+	if value, ok := getPostFormAny(c, "variantLabel", "variant_label"); ok {
+		input.VariantLabel = strings.TrimSpace(value)
+		input.VariantLabelSet = true
+	}
+
 	// ---- NUMBER FIELDS ----
 
 	if value, ok := c.GetPostForm("price"); ok {
@@ -103,7 +122,7 @@ func parseMultipartProductRequest(c *gin.Context) (MultipartProductInput, error)
 
 	if value, ok := c.GetPostForm("stock"); ok {
 		v := strings.TrimSpace(value)
-		parsed, err := strconv.Atoi(v)
+		parsed, err := strconv.ParseFloat(v, 64)
 		if err != nil {
 			return MultipartProductInput{}, err
 		}
@@ -111,6 +130,32 @@ func parseMultipartProductRequest(c *gin.Context) (MultipartProductInput, error)
 		input.StockSet = true
 	}
 
+	// reorderLevel: boş gelirse 0 (uyarı kapalı)
+	if value, ok := getPostFormAny(c, "reorderLevel", "reorder_level"); ok {
+		v := strings.TrimSpace(value)
+		if v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return MultipartProductInput{}, err
+			}
+			input.ReorderLevel = parsed
+		}
+		input.ReorderLevelSet = true
+	}
+
+	// costPrice: boş gelirse 0 (maliyet bilinmiyor)
+	if value, ok := getPostFormAny(c, "costPrice", "cost_price"); ok {
+		v := strings.TrimSpace(value)
+		if v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return MultipartProductInput{}, err
+			}
+			input.CostPrice = parsed
+		}
+		input.CostPriceSet = true
+	}
+
 	// ✅ salePrice: boş gelebilir -> 0 kabul et
 	if value, ok := getPostFormAny(c, "salePrice", "sale_price"); ok {
 		v := strings.TrimSpace(value)
@@ -182,14 +227,16 @@ func parseMultipartProductRequest(c *gin.Context) (MultipartProductInput, error)
 		}
 	}
 
-	// ---- IMAGE FILE ----
+	// ---- IMAGE FILES ----
+	// "image" is the cover (replaces the first gallery image on update),
+	// "images" are appended to the gallery.
 	file, err := c.FormFile("image")
 	if err == nil {
-		imagePath, err := saveImage(file)
+		cover, err := saveProductImage(c.Request.Context(), uploads, file)
 		if err != nil {
 			return MultipartProductInput{}, err
 		}
-		input.ImagePath = imagePath
+		input.CoverImage = &cover
 		input.ImageSet = true
 	} else {
 		// toleranslı hata kontrolü (Gin sürümleri farkı)
@@ -200,66 +247,21 @@ func parseMultipartProductRequest(c *gin.Context) (MultipartProductInput, error)
 		}
 	}
 
-	return input, nil
-}
-
-/*
-=======================
-  IMAGE SAVE
-=======================
-*/
-
-func saveImage(file *multipart.FileHeader) (string, error) {
-	extension := strings.ToLower(filepath.Ext(file.Filename))
-	if extension == "" {
-		return "", fmt.Errorf("image file extension is required")
-	}
-	allowedExtensions := map[string]struct{}{
-		".jpg":  {},
-		".jpeg": {},
-		".png":  {},
-		".webp": {},
-	}
-	if _, ok := allowedExtensions[extension]; !ok {
-		return "", fmt.Errorf("unsupported image type: %s", extension)
-	}
-	const maxImageSize = 5 << 20
-	if file.Size > maxImageSize {
-		return "", fmt.Errorf("image file too large (max 5MB)")
-	}
-
-	filename := primitive.NewObjectID().Hex() + extension
-
-	dir := filepath.Join(publicRootDir, "uploads", "products")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		log.Printf("[UPLOAD] saveImage: failed to create directory %s: %v", dir, err)
-		return "", err
-	}
-
-	fullPath := filepath.Join(dir, filename)
-	log.Printf("[UPLOAD] saveImage: filename=%s ext=%s fullPath=%s", filename, extension, fullPath)
-
-	out, err := os.Create(fullPath)
-	if err != nil {
-		log.Printf("[UPLOAD] saveImage: failed to create file %s: %v", fullPath, err)
-		return "", err
-	}
-	defer out.Close()
-
-	in, err := file.Open()
-	if err != nil {
-		log.Printf("[UPLOAD] saveImage: failed to open upload %s: %v", file.Filename, err)
-		return "", err
-	}
-	defer in.Close()
-
-	if _, err := io.Copy(out, in); err != nil {
-		log.Printf("[UPLOAD] saveImage: failed to save file %s: %v", fullPath, err)
-		return "", err
+	if c.Request.MultipartForm != nil {
+		if files := c.Request.MultipartForm.File["images"]; len(files) > 0 {
+			images, err := saveProductImages(c.Request.Context(), uploads, files)
+			if err != nil {
+				if input.CoverImage != nil {
+					deleteProductImageFiles(c.Request.Context(), uploads, []models.ProductImage{*input.CoverImage})
+				}
+				return MultipartProductInput{}, err
+			}
+			input.Images = images
+			input.ImageSet = true
+		}
 	}
 
-	// DB’ye yazılacak path
-	return path.Join("uploads", "products", filename), nil
+	return input, nil
 }
 
 /*
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"backend/internal/storage"
 )
 
 func TestParseMultipartProductRequest_PicksLastSaleEnabledValue(t *testing.T) {
@@ -25,7 +27,7 @@ func TestParseMultipartProductRequest_PicksLastSaleEnabledValue(t *testing.T) {
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	c.Request = req
 
-	parsed, err := parseMultipartProductRequest(c)
+	parsed, err := parseMultipartProductRequest(c, storage.NewLocal(t.TempDir(), "/public"))
 	if err != nil {
 		t.Fatalf("parseMultipartProductRequest returned error: %v", err)
 	}
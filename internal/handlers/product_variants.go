@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+// createVariantRequest either attaches an existing product (ProductID) or
+// creates a new SKU under the parent from the remaining fields.
+type createVariantRequest struct {
+	ProductID        *string  `json:"productId"`
+	Label            string   `json:"label"`
+	VariantAttribute string   `json:"variantAttribute"`
+	Barcode          string   `json:"barcode"`
+	Unit             string   `json:"unit"`
+	Price            float64  `json:"price"`
+	SaleEnabled      bool     `json:"saleEnabled"`
+	SalePrice        *float64 `json:"salePrice"`
+	CostPrice        float64  `json:"costPrice"`
+	Stock            float64  `json:"stock"`
+	ReorderLevel     float64  `json:"reorderLevel"`
+	IsActive         *bool    `json:"isActive"`
+}
+
+// topLevelProductFilter keeps variants out of listings; they are shown
+// through their parent's variants summary instead.
+var topLevelProductFilter = bson.M{"$exists": false}
+
+// variantSummary is the customer-facing view of one SKU in a group.
+func variantSummary(product models.Product) models.ProductVariant {
+	return models.ProductVariant{
+		ID:             product.ID,
+		Label:          product.VariantLabel,
+		Barcode:        product.Barcode,
+		Unit:           product.Unit,
+		Price:          product.Price,
+		SalePrice:      product.SalePrice,
+		EffectivePrice: effectiveProductPrice(product.Price, product.SaleEnabled, product.SalePrice),
+		IsOnSale:       product.IsOnSale,
+		Stock:          product.Stock,
+		InStock:        product.Stock > 0,
+	}
+}
+
+// buildVariantGroup lists the parent first, then its variants from the
+// cheapest up, so the group reads "500 g, 1 kg, 2 kg".
+func buildVariantGroup(parent models.Product, children []models.Product) []models.ProductVariant {
+	sorted := make([]models.Product, len(children))
+	copy(sorted, children)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return effectiveProductPrice(sorted[i].Price, sorted[i].SaleEnabled, sorted[i].SalePrice) <
+			effectiveProductPrice(sorted[j].Price, sorted[j].SaleEnabled, sorted[j].SalePrice)
+	})
+
+	group := make([]models.ProductVariant, 0, len(sorted)+1)
+	group = append(group, variantSummary(parent))
+	for _, child := range sorted {
+		group = append(group, variantSummary(child))
+	}
+	return group
+}
+
+// loadVariants returns the variants of the given parents keyed by parent ID.
+// Public callers pass onlyActive so hidden SKUs stay hidden.
+func loadVariants(ctx context.Context, db *mongo.Database, parentIDs []primitive.ObjectID, onlyActive bool) (map[primitive.ObjectID][]models.Product, error) {
+	byParent := make(map[primitive.ObjectID][]models.Product, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return byParent, nil
+	}
+
+	filter := bson.M{
+		"parentId":  bson.M{"$in": parentIDs},
+		"isDeleted": bson.M{"$ne": true},
+	}
+	if onlyActive {
+		filter["isActive"] = bson.M{"$ne": false}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	if onlyActive {
+		opts.SetProjection(publicProductProjection)
+	}
+
+	cursor, err := db.Collection("products").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	children, err := decodeProducts(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		byParent[*child.ParentID] = append(byParent[*child.ParentID], child)
+	}
+	return byParent, nil
+}
+
+// attachVariants fills Variants on every product that heads a group.
+func attachVariants(ctx context.Context, db *mongo.Database, products []models.Product, onlyActive bool) error {
+	parentIDs := make([]primitive.ObjectID, 0)
+	for _, product := range products {
+		if product.VariantAttribute != "" {
+			parentIDs = append(parentIDs, product.ID)
+		}
+	}
+
+	byParent, err := loadVariants(ctx, db, parentIDs, onlyActive)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		if products[i].VariantAttribute == "" {
+			continue
+		}
+		products[i].Variants = buildVariantGroup(products[i], byParent[products[i].ID])
+	}
+	return nil
+}
+
+// loadVariantParent finds a product that can head a variant group: it must
+// exist and must not be a variant itself.
+func loadVariantParent(ctx context.Context, db *mongo.Database, id primitive.ObjectID) (models.Product, int, string) {
+	var raw bson.M
+	err := db.Collection("products").FindOne(ctx, bson.M{"_id": id, "isDeleted": bson.M{"$ne": true}}).Decode(&raw)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Product{}, http.StatusNotFound, "product not found"
+	}
+	if err != nil {
+		return models.Product{}, http.StatusInternalServerError, "db error"
+	}
+	parent, err := normalizeProductDocument(raw)
+	if err != nil {
+		return models.Product{}, http.StatusInternalServerError, "decode error"
+	}
+	if parent.ParentID != nil {
+		return models.Product{}, http.StatusBadRequest, "product is a variant of another product"
+	}
+	return parent, 0, ""
+}
+
+/*
+GET /admin/api/products/:id/variants
+- Ürün ve varyantları (pasif olanlar dahil)
+*/
+func GetProductVariants(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/products/:id/variants"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		parent, status, message := loadVariantParent(ctx, db, id)
+		if status != 0 {
+			respondWithError(c, status, route, message)
+			return
+		}
+
+		byParent, err := loadVariants(ctx, db, []primitive.ObjectID{id}, false)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		variants := byParent[id]
+		if variants == nil {
+			variants = []models.Product{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"parent":           parent,
+			"variantAttribute": parent.VariantAttribute,
+			"data":             variants,
+		})
+	}
+}
+
+/*
+POST /admin/api/products/:id/variants
+  - Yeni varyant: {"label": "1 kg", "price": 59.9, "stock": 10, "barcode": "...", "variantAttribute": "weight"}
+    kategori, marka ve açıklama ana üründen kopyalanır
+  - Mevcut ürünü bağlama: {"productId": "...", "label": "500 g"}
+  - variantAttribute (size, weight, pack) ana ürün ilk kez gruplanırken zorunludur
+  - Varyantın varyantı olamaz
+*/
+func CreateProductVariant(db *mongo.Database, productSearch *search.Index) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/products/:id/variants"
+
+		parentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		var req createVariantRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+		label := strings.TrimSpace(req.Label)
+		if label == "" {
+			respondWithError(c, http.StatusBadRequest, route, "label required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		parent, status, message := loadVariantParent(ctx, db, parentID)
+		if status != 0 {
+			respondWithError(c, status, route, message)
+			return
+		}
+
+		attribute := strings.TrimSpace(strings.ToLower(req.VariantAttribute))
+		if attribute == "" {
+			attribute = parent.VariantAttribute
+		}
+		if attribute == "" {
+			respondWithError(c, http.StatusBadRequest, route, "variantAttribute required")
+			return
+		}
+		if _, ok := models.VariantAttributes[attribute]; !ok {
+			respondWithError(c, http.StatusBadRequest, route, "variantAttribute must be size, weight or pack")
+			return
+		}
+
+		var variant models.Product
+		err = runInTransaction(ctx, db, func(sessCtx mongo.SessionContext) error {
+			if req.ProductID != nil {
+				variant, err = attachExistingVariant(sessCtx, db, parent, *req.ProductID, label)
+			} else {
+				variant, err = insertNewVariant(sessCtx, db, parent, req, label, stockActorFromContext(c))
+			}
+			if err != nil {
+				return err
+			}
+			if parent.VariantAttribute == attribute {
+				return nil
+			}
+			_, err := db.Collection("products").UpdateOne(sessCtx,
+				bson.M{"_id": parent.ID},
+				bson.M{"$set": bson.M{"variantAttribute": attribute}},
+			)
+			return err
+		})
+		var reqErr variantRequestError
+		if errors.As(err, &reqErr) {
+			respondWithError(c, reqErr.status, route, reqErr.message)
+			return
+		}
+		if err != nil {
+			log.Printf("[%s] failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		productSearch.Invalidate()
+		c.JSON(http.StatusCreated, variant)
+	}
+}
+
+type variantRequestError struct {
+	status  int
+	message string
+}
+
+func (e variantRequestError) Error() string {
+	return e.message
+}
+
+func attachExistingVariant(ctx context.Context, db *mongo.Database, parent models.Product, rawID, label string) (models.Product, error) {
+	id, err := primitive.ObjectIDFromHex(strings.TrimSpace(rawID))
+	if err != nil {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "invalid productId"}
+	}
+	if id == parent.ID {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "a product cannot be its own variant"}
+	}
+
+	var raw bson.M
+	err = db.Collection("products").FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":              id,
+			"isDeleted":        bson.M{"$ne": true},
+			"parentId":         bson.M{"$exists": false},
+			"variantAttribute": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"parentId": parent.ID, "variantLabel": label}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&raw)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "product not found, already a variant or heads its own group"}
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+	return normalizeProductDocument(raw)
+}
+
+func insertNewVariant(ctx context.Context, db *mongo.Database, parent models.Product, req createVariantRequest, label string, actor models.StockActor) (models.Product, error) {
+	if req.Price <= 0 {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "invalid price"}
+	}
+	salePrice := 0.0
+	if req.SalePrice != nil {
+		salePrice = *req.SalePrice
+	}
+	if err := validateSaleFields(req.Price, req.SaleEnabled, salePrice, req.SalePrice != nil); err != nil {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, err.Error()}
+	}
+	unit := parent.Unit
+	if strings.TrimSpace(req.Unit) != "" {
+		parsed, err := parseProductUnit(req.Unit)
+		if err != nil {
+			return models.Product{}, variantRequestError{http.StatusBadRequest, err.Error()}
+		}
+		unit = parsed
+	}
+	if req.Stock < 0 {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "stock must be zero or greater"}
+	}
+	if err := validateQuantity(unit, req.Stock); err != nil {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "stock: " + err.Error()}
+	}
+	if req.ReorderLevel < 0 || req.CostPrice < 0 {
+		return models.Product{}, variantRequestError{http.StatusBadRequest, "reorderLevel and costPrice must be zero or greater"}
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	parentID := parent.ID
+	variant := models.Product{
+		ID:           primitive.NewObjectID(),
+		Name:         parent.Name,
+		Price:        req.Price,
+		SaleEnabled:  req.SaleEnabled,
+		SalePrice:    salePrice,
+		CostPrice:    roundMoney(req.CostPrice),
+		IsOnSale:     isProductOnSale(req.Price, req.SaleEnabled, salePrice),
+		Category:     parent.Category,
+		CategoryIDs:  parent.CategoryIDs,
+		Description:  parent.Description,
+		Barcode:      strings.TrimSpace(req.Barcode),
+		Brand:        parent.Brand,
+		Unit:         unit,
+		Stock:        req.Stock,
+		InStock:      req.Stock > 0,
+		ReorderLevel: req.ReorderLevel,
+		IsActive:     isActive,
+		CreatedAt:    time.Now(),
+		ParentID:     &parentID,
+		VariantLabel: label,
+	}
+
+	if _, err := db.Collection("products").InsertOne(ctx, variant); err != nil {
+		return models.Product{}, err
+	}
+	if variant.Stock == 0 {
+		return variant, nil
+	}
+	err := recordStockMovement(ctx, db, &models.StockMovement{
+		ProductID: variant.ID,
+		Type:      models.StockMovementAdjustment,
+		Quantity:  variant.Stock,
+		After:     variant.Stock,
+		Reason:    "ilk stok",
+		Actor:     actor,
+	})
+	return variant, err
+}
+
+/*
+DELETE /admin/api/products/:id/variants/:variantId
+- Varyantı gruptan çıkarır; ürün silinmez, tek başına listelenir
+- Son varyant çıkınca ana ürünün gruplaması kaldırılır
+*/
+func DeleteProductVariant(db *mongo.Database, productSearch *search.Index) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /admin/api/products/:id/variants/:variantId"
+
+		parentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+		variantID, err := primitive.ObjectIDFromHex(c.Param("variantId"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid variantId")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		res, err := db.Collection("products").UpdateOne(ctx,
+			bson.M{"_id": variantID, "parentId": parentID},
+			bson.M{"$unset": bson.M{"parentId": ""}},
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if res.MatchedCount == 0 {
+			respondWithError(c, http.StatusNotFound, route, "variant not found")
+			return
+		}
+
+		if err := ungroupIfEmpty(ctx, db, parentID); err != nil {
+			log.Printf("[%s] ungroup failed: %v", route, err)
+		}
+
+		productSearch.Invalidate()
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ungroupIfEmpty clears the parent's variant attribute once no variant
+// points at it any more.
+func ungroupIfEmpty(ctx context.Context, db *mongo.Database, parentID primitive.ObjectID) error {
+	remaining, err := db.Collection("products").CountDocuments(ctx, bson.M{"parentId": parentID})
+	if err != nil || remaining > 0 {
+		return err
+	}
+	_, err = db.Collection("products").UpdateOne(ctx,
+		bson.M{"_id": parentID},
+		bson.M{"$unset": bson.M{"variantAttribute": ""}},
+	)
+	return err
+}
+
+// detachVariants turns the variants of a deleted parent into standalone
+// products so they do not disappear from listings with it.
+func detachVariants(ctx context.Context, db *mongo.Database, parentID primitive.ObjectID) error {
+	_, err := db.Collection("products").UpdateMany(ctx,
+		bson.M{"parentId": parentID},
+		bson.M{"$unset": bson.M{"parentId": ""}},
+	)
+	return err
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestValidateQuantity(t *testing.T) {
+	cases := []struct {
+		unit     string
+		quantity float64
+		ok       bool
+	}{
+		{models.UnitPiece, 3, true},
+		{models.UnitPiece, 1.5, false},
+		{"", 2, true},
+		{models.UnitKg, 1.25, true},
+		{models.UnitKg, 0.125, true},
+		{models.UnitKg, 0.1255, false},
+	}
+	for _, tc := range cases {
+		if err := validateQuantity(tc.unit, tc.quantity); (err == nil) != tc.ok {
+			t.Errorf("validateQuantity(%q, %v) error = %v, want ok=%v", tc.unit, tc.quantity, err, tc.ok)
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	if got := formatQuantity(3, models.UnitPiece); got != "3" {
+		t.Fatalf("expected 3, got %q", got)
+	}
+	if got := formatQuantity(1.25, models.UnitKg); got != "1,25 kg" {
+		t.Fatalf("expected 1,25 kg, got %q", got)
+	}
+}
+
+func TestBuildVariantGroupParentFirstThenCheapest(t *testing.T) {
+	parent := models.Product{ID: primitive.NewObjectID(), Name: "Domates", VariantLabel: "500 g", Price: 30, Stock: 4}
+	large := models.Product{ID: primitive.NewObjectID(), VariantLabel: "2 kg", Price: 100}
+	small := models.Product{ID: primitive.NewObjectID(), VariantLabel: "1 kg", Price: 70, SaleEnabled: true, SalePrice: 55, IsOnSale: true, Stock: 2}
+
+	group := buildVariantGroup(parent, []models.Product{large, small})
+	if len(group) != 3 || group[0].ID != parent.ID || group[1].ID != small.ID || group[2].ID != large.ID {
+		t.Fatalf("unexpected order %+v", group)
+	}
+	if group[1].EffectivePrice != 55 || !group[1].InStock || group[2].InStock {
+		t.Fatalf("unexpected summaries %+v", group)
+	}
+	if got := productDisplayName(models.Product{Name: "Domates", VariantLabel: "1 kg"}); got != "Domates 1 kg" {
+		t.Fatalf("unexpected display name %q", got)
+	}
+}
@@ -9,8 +9,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-
-	"backend/internal/models"
 )
 
 func GetCategories(db *mongo.Database) gin.HandlerFunc {
@@ -28,21 +26,11 @@ func GetCategories(db *mongo.Database) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		cursor, err := db.Collection("categories").Find(
-			ctx,
-			bson.M{"isActive": true},
-		)
+		categories, err := loadCategories(ctx, db, bson.M{"isActive": true})
 		if err != nil {
 			respondWithError(c, http.StatusInternalServerError, route, "db error")
 			return
 		}
-		defer cursor.Close(ctx)
-
-		var categories []models.Category
-		if err := cursor.All(ctx, &categories); err != nil {
-			respondWithError(c, http.StatusInternalServerError, route, "decode error")
-			return
-		}
 
 		log.Printf("[%s] returning %d categories", route, len(categories))
 		c.JSON(http.StatusOK, categories)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -12,11 +13,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"backend/internal/alerts"
+	"backend/internal/auth"
+	"backend/internal/middleware"
 	"backend/internal/models"
 )
 
@@ -28,7 +31,7 @@ type createOrderItemRequest struct {
 	ProductID string   `json:"productId" binding:"required"`
 	Name      string   `json:"name"`
 	Price     *float64 `json:"price"`
-	Quantity  int      `json:"quantity" binding:"required"`
+	Quantity  float64  `json:"quantity" binding:"required"`
 }
 
 type createOrderCustomerRequest struct {
@@ -81,7 +84,7 @@ type createOrderRequest struct {
    CREATE ORDER
 ========================= */
 
-func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
+func CreateOrder(db *mongo.Database, tokens *auth.TokenService, notifier alerts.Notifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const route = "POST /orders"
 		defer handlePanic(c, route)
@@ -110,12 +113,19 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := userIDFromHeader(c.GetHeader("Authorization"), jwtSecret)
+		userID, err := userIDFromHeader(c.GetHeader("Authorization"), tokens)
 		if err != nil {
 			log.Println("[ORDER] [ERROR] token validation failed:", err)
 			respondOrderError(c, http.StatusUnauthorized, "unauthorized")
 			return
 		}
+		if userID != nil {
+			if err := middleware.ActiveUser(c.Request.Context(), db, *userID); err != nil {
+				log.Println("[ORDER] [ERROR] user check failed:", err)
+				respondOrderError(c, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+		}
 
 		resolvedItems, err := resolveOrderItems(c.Request.Context(), db, req.Items)
 		if err != nil {
@@ -139,6 +149,14 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 			return
 		}
 		order.UserID = userID
+		// The ID is assigned up front so the sale movements can point at the
+		// order before it is inserted.
+		order.ID = primitive.NewObjectID()
+
+		actor := models.StockActor{Type: "guest"}
+		if userID != nil {
+			actor = models.StockActor{Type: "user", ID: userID}
+		}
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
@@ -151,7 +169,10 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 		defer session.EndSession(ctx)
 
 		var orderID primitive.ObjectID
+		var lowStock []alerts.LowStockEvent
 		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			// The callback may be retried; only the committed attempt alerts.
+			lowStock = lowStock[:0]
 			for _, item := range order.Items {
 				var rawProduct bson.M
 				err := db.Collection("products").FindOne(
@@ -180,23 +201,23 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 						Requested: item.Quantity,
 					}
 				}
-				filter := bson.M{
-					"_id":       item.ProductID,
-					"isDeleted": bson.M{"$ne": true},
-					"stock":     bson.M{"$gte": item.Quantity},
-				}
-				update := bson.M{"$inc": bson.M{"stock": -item.Quantity}}
-
-				res, err := db.Collection("products").UpdateOne(sessCtx, filter, update)
+				movement, err := adjustStock(sessCtx, db, item.ProductID, -item.Quantity, models.StockMovement{
+					Type:    models.StockMovementSale,
+					OrderID: &order.ID,
+					Actor:   actor,
+				})
 				if err != nil {
+					if errors.Is(err, errStockUnavailable) {
+						return nil, outOfStockError{
+							ProductID: item.ProductID,
+							Available: product.Stock,
+							Requested: item.Quantity,
+						}
+					}
 					return nil, err
 				}
-				if res.MatchedCount == 0 {
-					return nil, outOfStockError{
-						ProductID: item.ProductID,
-						Available: product.Stock,
-						Requested: item.Quantity,
-					}
+				if crossedReorderLevel(movement.Before, movement.After, product.ReorderLevel) {
+					lowStock = append(lowStock, newLowStockEvent(product, movement))
 				}
 			}
 			res, err := db.Collection("orders").InsertOne(sessCtx, order)
@@ -227,6 +248,8 @@ func CreateOrder(db *mongo.Database, jwtSecret string) gin.HandlerFunc {
 			order.ID = orderID
 		}
 
+		dispatchLowStockAlerts(notifier, lowStock)
+
 		if userID != nil {
 			log.Println("[ORDER] [INFO] order created for user:", userID.Hex())
 		} else {
@@ -277,17 +300,27 @@ func resolveOrderItems(ctx context.Context, db *mongo.Database, reqItems []creat
 			return nil, err
 		}
 
+		if err := validateQuantity(product.Unit, item.Quantity); err != nil {
+			return nil, invalidQuantityError{ProductID: productID, Name: productDisplayName(product), Err: err}
+		}
+
 		if product.Stock < item.Quantity {
 			return nil, outOfStockError{ProductID: productID, Available: product.Stock, Requested: item.Quantity}
 		}
 
 		unitPrice := effectiveProductPrice(product.Price, product.SaleEnabled, product.SalePrice)
-		items = append(items, models.OrderItem{
+		orderItem := models.OrderItem{
 			ProductID: productID,
-			Name:      strings.TrimSpace(product.Name),
+			Name:      productDisplayName(product),
 			Price:     unitPrice,
 			Quantity:  item.Quantity,
-		})
+			Unit:      product.Unit,
+			CostPrice: product.CostPrice,
+		}
+		if product.IsOnSale {
+			orderItem.OriginalPrice = product.Price
+		}
+		items = append(items, orderItem)
 	}
 
 	return items, nil
@@ -307,12 +340,12 @@ func buildOrderFromResolvedItems(req createOrderRequest, items []models.OrderIte
 
 	var total float64
 	for _, item := range items {
-		total += item.Price * float64(item.Quantity)
+		total += roundMoney(item.Price * item.Quantity)
 	}
 
 	return models.Order{
 		Items:         items,
-		TotalPrice:    total,
+		TotalPrice:    roundMoney(total),
 		Customer:      models.OrderCustomer(*req.Customer),
 		PaymentMethod: req.PaymentMethod.ID,
 		Status:        "pending",
@@ -364,7 +397,7 @@ func respondOrderError(c *gin.Context, status int, message string) {
 	})
 }
 
-func userIDFromHeader(header, secret string) (*primitive.ObjectID, error) {
+func userIDFromHeader(header string, tokens *auth.TokenService) (*primitive.ObjectID, error) {
 	raw := strings.TrimSpace(header)
 	if raw == "" {
 		return nil, nil
@@ -375,18 +408,11 @@ func userIDFromHeader(header, secret string) (*primitive.ObjectID, error) {
 		return nil, errors.New("invalid token format")
 	}
 
-	token, err := jwt.Parse(parts[1], func(t *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
-	if err != nil || !token.Valid {
+	claims, err := tokens.Parse(parts[1])
+	if err != nil {
 		return nil, errors.New("invalid token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
-	}
-
 	userIDValue, ok := claims["userId"].(string)
 	if !ok || strings.TrimSpace(userIDValue) == "" {
 		return nil, errors.New("userId claim missing")
@@ -402,8 +428,8 @@ func userIDFromHeader(header, secret string) (*primitive.ObjectID, error) {
 
 type outOfStockError struct {
 	ProductID primitive.ObjectID
-	Available int
-	Requested int
+	Available float64
+	Requested float64
 }
 
 func (e outOfStockError) Error() string {
@@ -417,3 +443,17 @@ type productNotFoundError struct {
 func (e productNotFoundError) Error() string {
 	return "product not found"
 }
+
+type invalidQuantityError struct {
+	ProductID primitive.ObjectID
+	Name      string
+	Err       error
+}
+
+func (e invalidQuantityError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e invalidQuantityError) Unwrap() error {
+	return e.Err
+}
@@ -9,27 +9,41 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/search"
 )
 
 /*
 GET /products
 - Varsayılan pagination: page=1, limit=20
 - Geçiş için: page/limit hiç verilmezse eski array response korunur
+- Varyantlar ayrı listelenmez; ana ürünün "variants" alanında döner
+- ?search= sonuçları alaka sırasıyla döner (ad > marka > açıklama)
+- Arama Türkçe harf farkını ve tek harflik yazım hatasını tolere eder
+- ?category= id, isim ya da slug; üst kategori tüm alt kategorileri kapsar
+- ?category=a,b ?brand=x,y ?minPrice= ?maxPrice= (indirimli fiyat) ?onSale=true ?inStock=true
+- ?sort=newest|price_asc|price_desc|name|popular|relevance
+- Sayfalı yanıtta "facets": marka, kategori ve fiyat aralığı sayıları
+- ?cursor= (ilk sayfa boş) verilirse sayfalama nextCursor ile ilerler; page yok sayılır
+- Cursor modunda "facets" yalnızca ilk sayfada döner; sonraki sayfalarda ?facets=true ile istenir
 */
-func GetProducts(db *mongo.Database) gin.HandlerFunc {
+func GetProducts(db *mongo.Database, productSearch *search.Index) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const route = "GET /products"
 		defer handlePanic(c, route)
 
 		log.Printf(
-			"[%s] hit page=%s limit=%s category=%s search=%s",
+			"[%s] hit page=%s limit=%s category=%s search=%s sort=%s",
 			route,
 			c.Query("page"),
 			c.Query("limit"),
 			c.Query("category"),
 			c.Query("search"),
+			c.Query("sort"),
 		)
 
 		if err := ensureDBConnection(c.Request.Context(), db); err != nil {
@@ -37,24 +51,36 @@ func GetProducts(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
+		params, err := parseProductListParams(c.Request.URL.Query())
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
 		// BASE FILTER
 		filter := bson.M{
 			"isActive":  bson.M{"$ne": false},
 			"isDeleted": bson.M{"$ne": true},
+			"parentId":  topLevelProductFilter,
 		}
+		params.applyTo(filter)
 
-		if category := strings.TrimSpace(c.Query("category")); category != "" {
-			filter["category"] = bson.M{"$in": []string{category}}
-		}
-
-		if search := strings.TrimSpace(c.Query("search")); search != "" {
-			filter["$or"] = []bson.M{
-				{"name": bson.M{"$regex": search, "$options": "i"}},
-				{"brand": bson.M{"$regex": search, "$options": "i"}},
-				{"description": bson.M{"$regex": search, "$options": "i"}},
-				{"barcode": bson.M{"$regex": search, "$options": "i"}},
+		var hits []search.Hit
+		textSearch := false
+		if query := strings.TrimSpace(c.Query("search")); query != "" {
+			if productSearch.Ready() {
+				hits = productSearch.Search(query, nil)
+				filter["_id"] = bson.M{"$in": hitObjectIDs(hits)}
+			} else {
+				// index still building after startup
+				filter["$text"] = bson.M{"$search": query}
+				textSearch = true
 			}
 		}
+		if params.Sort == "" && hits != nil {
+			params.Sort = sortRelevance
+		}
+		ranked := params.Sort == sortRelevance && hits != nil
 
 		pageStr := strings.TrimSpace(c.Query("page"))
 		limitStr := strings.TrimSpace(c.Query("limit"))
@@ -65,30 +91,151 @@ func GetProducts(db *mongo.Database) gin.HandlerFunc {
 			return
 		}
 
-		findOptions := options.Find().
-			SetSort(bson.D{{Key: "createdAt", Value: -1}}).
-			SetSkip((page - 1) * limit).
-			SetLimit(limit)
+		cursorMode, after, err := parseCursorParam(c.Request.URL.Query(), params.Sort)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+		// Cursor pages after the first only carry facets when asked for.
+		withFacets := !cursorMode || after == nil || c.Query("facets") == "true"
+		window := productListPage{Page: page, Limit: limit, Ranked: ranked, Cursor: cursorMode, SkipFacets: !withFacets}
+		if after != nil && !ranked {
+			if window.After, err = afterCursor(params.sortStage(), after); err != nil {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+		}
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		total, err := db.Collection("products").CountDocuments(ctx, filter)
-		if err != nil {
+		if params.CategoryIDs, params.CategoryNames, err = expandCategoryFilter(ctx, db, params.Categories); err != nil {
 			respondWithError(c, http.StatusInternalServerError, route, "db error")
 			return
 		}
 
-		cursor, err := db.Collection("products").Find(ctx, filter, findOptions)
-		if err != nil {
+		aggregateOptions := options.Aggregate()
+		if params.Sort == sortName && !textSearch {
+			// Turkish alphabetical order (Ç after C, İ after I); $text
+			// queries do not support collations.
+			aggregateOptions.SetCollation(&options.Collation{Locale: "tr"})
+		}
+		var result productListResult
+		if withFacets || ranked {
+			cursor, err := db.Collection("products").Aggregate(ctx,
+				buildProductListPipeline(filter, params, window),
+				aggregateOptions,
+			)
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+			defer cursor.Close(ctx)
+
+			if cursor.Next(ctx) {
+				if err := cursor.Decode(&result); err != nil {
+					respondWithError(c, http.StatusInternalServerError, route, "decode error")
+					return
+				}
+			}
+			if err := cursor.Err(); err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+		}
+
+		if cursorMode && !ranked {
+			rows, err := db.Collection("products").Aggregate(ctx,
+				buildProductPagePipeline(filter, params, window),
+				aggregateOptions,
+			)
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+			defer rows.Close(ctx)
+			if err := rows.All(ctx, &result.Data); err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+		}
+
+		total := result.total()
+		products := make([]models.Product, 0, len(result.Data))
+		var next *pageCursor
+		if ranked {
+			// The pipeline only narrowed the hits down; order and page them
+			// by relevance, then load the page.
+			ids := make([]primitive.ObjectID, 0, len(result.Data))
+			for _, raw := range result.Data {
+				if id, ok := raw["_id"].(primitive.ObjectID); ok {
+					ids = append(ids, id)
+				}
+			}
+			rankedIDs := rankObjectIDs(ids, hits)
+
+			var pageIDs []primitive.ObjectID
+			if cursorMode {
+				pageIDs, next, err = rankedPageAfter(rankedIDs, after, limit, params.Sort)
+				if err != nil {
+					respondWithError(c, http.StatusBadRequest, route, err.Error())
+					return
+				}
+			} else {
+				pageIDs = pageOf(rankedIDs, page, limit)
+			}
+
+			found, err := db.Collection("products").Find(ctx,
+				bson.M{"_id": bson.M{"$in": pageIDs}},
+				options.Find().SetProjection(publicProductProjection),
+			)
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "db error")
+				return
+			}
+			defer found.Close(ctx)
+
+			loaded, err := decodeProducts(ctx, found)
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, route, "decode error")
+				return
+			}
+			products = orderProductsByIDs(loaded, pageIDs)
+		} else {
+			data := result.Data
+			if cursorMode && int64(len(data)) > limit {
+				data = data[:limit]
+				last := data[len(data)-1]
+				id, _ := last["_id"].(primitive.ObjectID)
+				values, _ := last["_cursor"].(bson.A)
+				next = &pageCursor{Sort: params.Sort, Values: values, ID: id}
+			}
+			for _, raw := range data {
+				delete(raw, "_cursor")
+				product, err := normalizeProductDocument(raw)
+				if err != nil {
+					respondWithError(c, http.StatusInternalServerError, route, "decode error")
+					return
+				}
+				products = append(products, product)
+			}
+		}
+
+		if err := attachVariants(ctx, db, products, true); err != nil {
 			respondWithError(c, http.StatusInternalServerError, route, "db error")
 			return
 		}
-		defer cursor.Close(ctx)
 
-		products, err := decodeProducts(ctx, cursor)
-		if err != nil {
-			respondWithError(c, http.StatusInternalServerError, route, "decode error")
+		if cursorMode {
+			log.Printf("[%s] returning %d products (cursor)", route, len(products))
+			response := gin.H{
+				"data":       products,
+				"pagination": cursorPagination(limit, next),
+			}
+			if withFacets {
+				response["facets"] = result.facets()
+			}
+			c.JSON(http.StatusOK, response)
 			return
 		}
 
@@ -114,6 +261,7 @@ func GetProducts(db *mongo.Database) gin.HandlerFunc {
 				"total":      total,
 				"totalPages": totalPages,
 			},
+			"facets": result.facets(),
 		})
 	}
 }
@@ -146,12 +294,14 @@ func GetCampaignProducts(db *mongo.Database) gin.HandlerFunc {
 			"isActive":   true,
 			"isCampaign": true,
 			"isDeleted":  bson.M{"$ne": true},
+			"parentId":   topLevelProductFilter,
 		}
 
 		findOptions := options.Find().
 			SetSkip((page - 1) * limit).
 			SetLimit(limit).
-			SetSort(bson.D{{Key: "createdAt", Value: -1}})
+			SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+			SetProjection(publicProductProjection)
 
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
@@ -174,6 +324,10 @@ func GetCampaignProducts(db *mongo.Database) gin.HandlerFunc {
 			respondWithError(c, http.StatusInternalServerError, route, "decode error")
 			return
 		}
+		if err := attachVariants(ctx, db, products, true); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
 
 		totalPages := int64(0)
 		if limit > 0 {
@@ -0,0 +1,733 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// errPurchaseOrderChanged means the purchase order was changed by another
+// request between reading and writing it.
+var errPurchaseOrderChanged = errors.New("purchase order changed")
+
+type purchaseOrderLineRequest struct {
+	ProductID string  `json:"productId"`
+	Quantity  float64 `json:"quantity"`
+	UnitCost  float64 `json:"unitCost"`
+}
+
+type purchaseOrderRequest struct {
+	SupplierID *string                     `json:"supplierId"`
+	Status     *string                     `json:"status"`
+	Lines      *[]purchaseOrderLineRequest `json:"lines"`
+	Note       *string                     `json:"note"`
+	ExpectedAt *string                     `json:"expectedAt"`
+}
+
+type purchaseReceiptLineRequest struct {
+	ProductID string   `json:"productId"`
+	Quantity  float64  `json:"quantity"`
+	UnitCost  *float64 `json:"unitCost"`
+}
+
+type purchaseReceiptRequest struct {
+	Lines []purchaseReceiptLineRequest `json:"lines"`
+	Note  string                       `json:"note"`
+}
+
+// purchaseOrderTransitions lists the statuses an admin may set by hand;
+// partially_received and received are only reached by receiving goods.
+var purchaseOrderTransitions = map[string][]string{
+	models.PurchaseOrderDraft:             {models.PurchaseOrderOrdered, models.PurchaseOrderCancelled},
+	models.PurchaseOrderOrdered:           {models.PurchaseOrderDraft, models.PurchaseOrderCancelled},
+	models.PurchaseOrderPartiallyReceived: {models.PurchaseOrderCancelled},
+}
+
+/*
+GET /admin/api/purchase-orders
+- ?status=draft|ordered|partially_received|received|cancelled, ?supplierId=
+- ?page, ?limit; en yeniden eskiye
+*/
+func GetPurchaseOrders(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/purchase-orders"
+
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		filter := bson.M{}
+		if status := strings.TrimSpace(c.Query("status")); status != "" {
+			filter["status"] = status
+		}
+		if raw := strings.TrimSpace(c.Query("supplierId")); raw != "" {
+			supplierID, err := primitive.ObjectIDFromHex(raw)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, "invalid supplierId")
+				return
+			}
+			filter["supplierId"] = supplierID
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		total, err := db.Collection("purchase_orders").CountDocuments(ctx, filter)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		cursor, err := db.Collection("purchase_orders").Find(ctx, filter,
+			options.Find().
+				SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+				SetSkip((page-1)*limit).
+				SetLimit(limit),
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer cursor.Close(ctx)
+
+		orders := make([]models.PurchaseOrder, 0)
+		if err := cursor.All(ctx, &orders); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "decode error")
+			return
+		}
+
+		totalPages := int64(0)
+		if total > 0 {
+			totalPages = int64(math.Ceil(float64(total) / float64(limit)))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": orders,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"totalPages": totalPages,
+			},
+		})
+	}
+}
+
+/*
+GET /admin/api/purchase-orders/:id
+*/
+func GetPurchaseOrderByID(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/purchase-orders/:id"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		order, err := loadPurchaseOrder(ctx, db, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "purchase order not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, order)
+	}
+}
+
+/*
+POST /admin/api/purchase-orders
+- {"supplierId": "...", "lines": [{"productId": "...", "quantity": 24, "unitCost": 18.5}], "note": "", "expectedAt": "2025-03-20"}
+- status draft ya da ordered olabilir (varsayılan draft)
+*/
+func CreatePurchaseOrder(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/purchase-orders"
+
+		var req purchaseOrderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+		if req.SupplierID == nil || req.Lines == nil {
+			respondWithError(c, http.StatusBadRequest, route, "supplierId and lines required")
+			return
+		}
+
+		status := models.PurchaseOrderDraft
+		if req.Status != nil {
+			status = strings.TrimSpace(*req.Status)
+			if status != models.PurchaseOrderDraft && status != models.PurchaseOrderOrdered {
+				respondWithError(c, http.StatusBadRequest, route, "status must be draft or ordered")
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		supplier, err := loadActiveSupplier(ctx, db, *req.SupplierID)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		lines, err := buildPurchaseOrderLines(ctx, db, *req.Lines)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		now := time.Now()
+		order := models.PurchaseOrder{
+			ID:           primitive.NewObjectID(),
+			SupplierID:   supplier.ID,
+			SupplierName: supplier.Name,
+			Status:       status,
+			Lines:        lines,
+			TotalCost:    purchaseOrderTotal(lines),
+			Receipts:     []models.PurchaseReceipt{},
+			CreatedAt:    now,
+		}
+		order.Code = "PO-" + buildOrderCode(order.ID)
+		if req.Note != nil {
+			order.Note = strings.TrimSpace(*req.Note)
+		}
+		if req.ExpectedAt != nil {
+			expectedAt, err := parsePurchaseDate(*req.ExpectedAt)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+			order.ExpectedAt = expectedAt
+		}
+
+		if _, err := db.Collection("purchase_orders").InsertOne(ctx, order); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusCreated, order)
+	}
+}
+
+/*
+PUT /admin/api/purchase-orders/:id
+- Kalemler ve tedarikçi yalnızca teslim alma başlamadan değiştirilebilir
+- status: draft ↔ ordered, ya da cancelled
+*/
+func UpdatePurchaseOrder(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "PUT /admin/api/purchase-orders/:id"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		var req purchaseOrderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		order, err := loadPurchaseOrder(ctx, db, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "purchase order not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		set := bson.M{}
+		editable := len(order.Receipts) == 0 &&
+			(order.Status == models.PurchaseOrderDraft || order.Status == models.PurchaseOrderOrdered)
+
+		if req.SupplierID != nil || req.Lines != nil {
+			if !editable {
+				respondWithError(c, http.StatusConflict, route, "purchase order can no longer be edited")
+				return
+			}
+		}
+		if req.SupplierID != nil {
+			supplier, err := loadActiveSupplier(ctx, db, *req.SupplierID)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+			set["supplierId"] = supplier.ID
+			set["supplierName"] = supplier.Name
+		}
+		if req.Lines != nil {
+			lines, err := buildPurchaseOrderLines(ctx, db, *req.Lines)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+			set["lines"] = lines
+			set["totalCost"] = purchaseOrderTotal(lines)
+		}
+		if req.Status != nil {
+			status := strings.TrimSpace(*req.Status)
+			if status != order.Status {
+				if !canTransitionPurchaseOrder(order.Status, status) {
+					respondWithError(c, http.StatusConflict, route, fmt.Sprintf("cannot change status from %s to %s", order.Status, status))
+					return
+				}
+				set["status"] = status
+			}
+		}
+		if req.Note != nil {
+			set["note"] = strings.TrimSpace(*req.Note)
+		}
+		if req.ExpectedAt != nil {
+			expectedAt, err := parsePurchaseDate(*req.ExpectedAt)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, err.Error())
+				return
+			}
+			set["expectedAt"] = expectedAt
+		}
+
+		if len(set) == 0 {
+			respondWithError(c, http.StatusBadRequest, route, "no fields to update")
+			return
+		}
+		set["updatedAt"] = time.Now()
+
+		// Matching on status and receipt count keeps an edit from racing a
+		// receive on the same order.
+		var updated models.PurchaseOrder
+		err = db.Collection("purchase_orders").FindOneAndUpdate(ctx,
+			bson.M{"_id": id, "status": order.Status, "receipts": bson.M{"$size": len(order.Receipts)}},
+			bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusConflict, route, "purchase order changed, retry")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+/*
+DELETE /admin/api/purchase-orders/:id
+- Yalnızca taslaklar silinir; diğerleri iptal edilmelidir
+*/
+func DeletePurchaseOrder(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /admin/api/purchase-orders/:id"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := db.Collection("purchase_orders").DeleteOne(ctx, bson.M{"_id": id, "status": models.PurchaseOrderDraft})
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if result.DeletedCount == 0 {
+			count, err := db.Collection("purchase_orders").CountDocuments(ctx, bson.M{"_id": id})
+			if err == nil && count > 0 {
+				respondWithError(c, http.StatusConflict, route, "only draft purchase orders can be deleted")
+				return
+			}
+			respondWithError(c, http.StatusNotFound, route, "purchase order not found")
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+/*
+POST /admin/api/purchase-orders/:id/receive
+- Gelen malı stoğa ekler: {"lines": [{"productId": "...", "quantity": 10, "unitCost": 18.5}], "note": "İrsaliye 1234"}
+- lines boşsa kalan tüm miktarlar teslim alınır; kısmi teslim desteklenir
+- unitCost verilmezse siparişteki maliyet kullanılır; ürün maliyeti ağırlıklı ortalama ile güncellenir
+*/
+func ReceivePurchaseOrder(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/purchase-orders/:id/receive"
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		var req purchaseReceiptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid body")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		order, err := loadPurchaseOrder(ctx, db, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "purchase order not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if order.Status != models.PurchaseOrderOrdered && order.Status != models.PurchaseOrderPartiallyReceived {
+			respondWithError(c, http.StatusConflict, route, "only ordered purchase orders can be received")
+			return
+		}
+
+		receiptLines, err := parsePurchaseReceiptLines(req.Lines)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		now := time.Now()
+		lines, status, received, err := applyPurchaseReceipt(order.Lines, receiptLines)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		actor := stockActorFromContext(c)
+		receipt := models.PurchaseReceipt{
+			ReceivedAt: now,
+			Lines:      received,
+			Note:       strings.TrimSpace(req.Note),
+			Actor:      actor,
+		}
+
+		set := bson.M{"lines": lines, "status": status, "updatedAt": now}
+		if status == models.PurchaseOrderReceived {
+			set["receivedAt"] = now
+		}
+
+		err = runInTransaction(ctx, db, func(sessCtx mongo.SessionContext) error {
+			res, err := db.Collection("purchase_orders").UpdateOne(sessCtx,
+				bson.M{"_id": id, "status": order.Status, "receipts": bson.M{"$size": len(order.Receipts)}},
+				bson.M{"$set": set, "$push": bson.M{"receipts": receipt}},
+			)
+			if err != nil {
+				return err
+			}
+			if res.MatchedCount == 0 {
+				return errPurchaseOrderChanged
+			}
+
+			for _, line := range received {
+				if err := receiveStock(sessCtx, db, line, models.StockMovement{
+					Type:        models.StockMovementReceipt,
+					Reason:      "satın alma " + order.Code,
+					ReferenceID: &order.ID,
+					Actor:       actor,
+					CreatedAt:   now,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, errPurchaseOrderChanged) {
+			respondWithError(c, http.StatusConflict, route, "purchase order changed, retry")
+			return
+		}
+		if errors.Is(err, errStockUnavailable) {
+			respondWithError(c, http.StatusConflict, route, "product on purchase order no longer exists")
+			return
+		}
+		if err != nil {
+			log.Printf("[%s] receive failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		updated, err := loadPurchaseOrder(ctx, db, id)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+// receiveStock adds the delivered quantity to the product and moves its cost
+// price to the weighted average of the stock on hand and the delivery.
+func receiveStock(ctx context.Context, db *mongo.Database, line models.PurchaseReceiptLine, movement models.StockMovement) error {
+	var current struct {
+		CostPrice float64 `bson:"costPrice"`
+	}
+	err := db.Collection("products").FindOne(ctx,
+		bson.M{"_id": line.ProductID, "isDeleted": bson.M{"$ne": true}},
+		options.FindOne().SetProjection(bson.M{"costPrice": 1}),
+	).Decode(&current)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return errStockUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	recorded, err := adjustStock(ctx, db, line.ProductID, line.Quantity, movement)
+	if err != nil {
+		return err
+	}
+
+	cost := weightedCostPrice(recorded.Before, current.CostPrice, line.Quantity, line.UnitCost)
+	_, err = db.Collection("products").UpdateOne(ctx,
+		bson.M{"_id": line.ProductID},
+		bson.M{"$set": bson.M{"costPrice": cost, "inStock": recorded.After > 0}},
+	)
+	return err
+}
+
+// weightedCostPrice averages the cost of the stock on hand with a delivery.
+// Without usable stock or a known cost the delivery cost is taken as is.
+func weightedCostPrice(stock, cost, quantity, unitCost float64) float64 {
+	if stock <= 0 || cost <= 0 {
+		return roundMoney(unitCost)
+	}
+	total := stock*cost + quantity*unitCost
+	return roundMoney(total / (stock + quantity))
+}
+
+// applyPurchaseReceipt adds received quantities to the order lines. An empty
+// receipt receives everything still outstanding. A line cannot be received
+// beyond its ordered quantity.
+func applyPurchaseReceipt(lines []models.PurchaseOrderLine, receipt []models.PurchaseReceiptLine) ([]models.PurchaseOrderLine, string, []models.PurchaseReceiptLine, error) {
+	updated := make([]models.PurchaseOrderLine, len(lines))
+	copy(updated, lines)
+
+	index := make(map[primitive.ObjectID]int, len(updated))
+	for i, line := range updated {
+		index[line.ProductID] = i
+	}
+
+	if len(receipt) == 0 {
+		for _, line := range updated {
+			if outstanding := roundQuantity(line.Quantity - line.ReceivedQuantity); outstanding > 0 {
+				receipt = append(receipt, models.PurchaseReceiptLine{ProductID: line.ProductID, Quantity: outstanding, UnitCost: -1})
+			}
+		}
+		if len(receipt) == 0 {
+			return nil, "", nil, errors.New("nothing left to receive")
+		}
+	}
+
+	received := make([]models.PurchaseReceiptLine, 0, len(receipt))
+	for _, line := range receipt {
+		i, ok := index[line.ProductID]
+		if !ok {
+			return nil, "", nil, fmt.Errorf("product %s is not on this purchase order", line.ProductID.Hex())
+		}
+		if err := validateQuantity(updated[i].Unit, line.Quantity); err != nil {
+			return nil, "", nil, fmt.Errorf("%s: %w", updated[i].Name, err)
+		}
+		outstanding := roundQuantity(updated[i].Quantity - updated[i].ReceivedQuantity)
+		if line.Quantity > outstanding {
+			return nil, "", nil, fmt.Errorf("%s: %s received but only %s outstanding", updated[i].Name,
+				formatQuantity(line.Quantity, updated[i].Unit), formatQuantity(outstanding, updated[i].Unit))
+		}
+		if line.UnitCost < 0 {
+			line.UnitCost = updated[i].UnitCost
+		}
+		updated[i].ReceivedQuantity = roundQuantity(updated[i].ReceivedQuantity + line.Quantity)
+		received = append(received, line)
+	}
+
+	status := models.PurchaseOrderReceived
+	for _, line := range updated {
+		if line.ReceivedQuantity < line.Quantity {
+			status = models.PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+	return updated, status, received, nil
+}
+
+// parsePurchaseReceiptLines validates the request; a missing unitCost is
+// marked -1 so applyPurchaseReceipt falls back to the ordered cost.
+func parsePurchaseReceiptLines(lines []purchaseReceiptLineRequest) ([]models.PurchaseReceiptLine, error) {
+	seen := map[primitive.ObjectID]struct{}{}
+	parsed := make([]models.PurchaseReceiptLine, 0, len(lines))
+	for _, line := range lines {
+		productID, err := primitive.ObjectIDFromHex(strings.TrimSpace(line.ProductID))
+		if err != nil {
+			return nil, errors.New("invalid productId")
+		}
+		if _, dup := seen[productID]; dup {
+			return nil, fmt.Errorf("duplicate productId %s", productID.Hex())
+		}
+		seen[productID] = struct{}{}
+		if line.Quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
+		}
+		unitCost := -1.0
+		if line.UnitCost != nil {
+			if *line.UnitCost < 0 {
+				return nil, errors.New("unitCost must be zero or greater")
+			}
+			unitCost = roundMoney(*line.UnitCost)
+		}
+		parsed = append(parsed, models.PurchaseReceiptLine{ProductID: productID, Quantity: line.Quantity, UnitCost: unitCost})
+	}
+	return parsed, nil
+}
+
+func buildPurchaseOrderLines(ctx context.Context, db *mongo.Database, requested []purchaseOrderLineRequest) ([]models.PurchaseOrderLine, error) {
+	if len(requested) == 0 {
+		return nil, errors.New("at least one line is required")
+	}
+
+	lines := make([]models.PurchaseOrderLine, 0, len(requested))
+	ids := make([]primitive.ObjectID, 0, len(requested))
+	seen := map[primitive.ObjectID]struct{}{}
+	for _, line := range requested {
+		productID, err := primitive.ObjectIDFromHex(strings.TrimSpace(line.ProductID))
+		if err != nil {
+			return nil, errors.New("invalid productId")
+		}
+		if _, dup := seen[productID]; dup {
+			return nil, fmt.Errorf("duplicate productId %s", productID.Hex())
+		}
+		seen[productID] = struct{}{}
+		if line.Quantity <= 0 {
+			return nil, errors.New("quantity must be greater than zero")
+		}
+		if line.UnitCost < 0 {
+			return nil, errors.New("unitCost must be zero or greater")
+		}
+		ids = append(ids, productID)
+		lines = append(lines, models.PurchaseOrderLine{
+			ProductID: productID,
+			Quantity:  line.Quantity,
+			UnitCost:  roundMoney(line.UnitCost),
+		})
+	}
+
+	cursor, err := db.Collection("products").Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "isDeleted": bson.M{"$ne": true}},
+		options.Find().SetProjection(bson.M{"name": 1, "barcode": 1, "unit": 1, "variantLabel": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]int, len(products))
+	for i, product := range products {
+		byID[product.ID] = i
+	}
+
+	for i := range lines {
+		j, ok := byID[lines[i].ProductID]
+		if !ok {
+			return nil, fmt.Errorf("product not found: %s", lines[i].ProductID.Hex())
+		}
+		if err := validateQuantity(products[j].Unit, lines[i].Quantity); err != nil {
+			return nil, fmt.Errorf("%s: %w", productDisplayName(products[j]), err)
+		}
+		lines[i].Name = productDisplayName(products[j])
+		lines[i].Barcode = products[j].Barcode
+		lines[i].Unit = products[j].Unit
+	}
+	return lines, nil
+}
+
+func purchaseOrderTotal(lines []models.PurchaseOrderLine) float64 {
+	total := 0.0
+	for _, line := range lines {
+		total += line.Quantity * line.UnitCost
+	}
+	return roundMoney(total)
+}
+
+func canTransitionPurchaseOrder(from, to string) bool {
+	for _, allowed := range purchaseOrderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func loadPurchaseOrder(ctx context.Context, db *mongo.Database, id primitive.ObjectID) (models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := db.Collection("purchase_orders").FindOne(ctx, bson.M{"_id": id}).Decode(&order)
+	if order.Receipts == nil {
+		order.Receipts = []models.PurchaseReceipt{}
+	}
+	return order, err
+}
+
+func loadActiveSupplier(ctx context.Context, db *mongo.Database, rawID string) (models.Supplier, error) {
+	supplierID, err := primitive.ObjectIDFromHex(strings.TrimSpace(rawID))
+	if err != nil {
+		return models.Supplier{}, errors.New("invalid supplierId")
+	}
+	var supplier models.Supplier
+	err = db.Collection("suppliers").FindOne(ctx, bson.M{"_id": supplierID, "isActive": true}).Decode(&supplier)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Supplier{}, errors.New("supplier not found")
+	}
+	return supplier, err
+}
+
+// parsePurchaseDate reads YYYY-MM-DD in the store timezone; an empty value
+// clears the date.
+func parsePurchaseDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", value, exportLocation())
+	if err != nil {
+		return nil, errors.New("invalid expectedAt (expected YYYY-MM-DD)")
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestApplyPurchaseReceiptPartialThenFull(t *testing.T) {
+	milk, eggs := primitive.NewObjectID(), primitive.NewObjectID()
+	lines := []models.PurchaseOrderLine{
+		{ProductID: milk, Name: "Süt", Quantity: 24, UnitCost: 18.5},
+		{ProductID: eggs, Name: "Yumurta", Quantity: 10, UnitCost: 42},
+	}
+
+	lines, status, received, err := applyPurchaseReceipt(lines, []models.PurchaseReceiptLine{
+		{ProductID: milk, Quantity: 12, UnitCost: -1},
+		{ProductID: eggs, Quantity: 10, UnitCost: 40},
+	})
+	if err != nil {
+		t.Fatalf("applyPurchaseReceipt returned error: %v", err)
+	}
+	if status != models.PurchaseOrderPartiallyReceived {
+		t.Fatalf("expected partial receipt, got %s", status)
+	}
+	if lines[0].ReceivedQuantity != 12 || lines[1].ReceivedQuantity != 10 {
+		t.Fatalf("unexpected received quantities %+v", lines)
+	}
+	if received[0].UnitCost != 18.5 || received[1].UnitCost != 40 {
+		t.Fatalf("expected ordered cost fallback and explicit cost, got %+v", received)
+	}
+
+	if _, _, _, err := applyPurchaseReceipt(lines, []models.PurchaseReceiptLine{{ProductID: milk, Quantity: 13, UnitCost: -1}}); err == nil {
+		t.Fatal("expected error when receiving more than outstanding")
+	}
+	if _, _, _, err := applyPurchaseReceipt(lines, []models.PurchaseReceiptLine{{ProductID: primitive.NewObjectID(), Quantity: 1}}); err == nil {
+		t.Fatal("expected error for product not on the order")
+	}
+
+	lines, status, received, err = applyPurchaseReceipt(lines, nil)
+	if err != nil {
+		t.Fatalf("applyPurchaseReceipt returned error: %v", err)
+	}
+	if status != models.PurchaseOrderReceived {
+		t.Fatalf("expected received, got %s", status)
+	}
+	if len(received) != 1 || received[0].ProductID != milk || received[0].Quantity != 12 {
+		t.Fatalf("expected only the outstanding milk to be received, got %+v", received)
+	}
+
+	if _, _, _, err := applyPurchaseReceipt(lines, nil); err == nil {
+		t.Fatal("expected error when nothing is left to receive")
+	}
+}
+
+func TestWeightedCostPrice(t *testing.T) {
+	if got := weightedCostPrice(10, 20, 30, 24); got != 23 {
+		t.Fatalf("expected 23, got %v", got)
+	}
+	if got := weightedCostPrice(0, 20, 5, 24); got != 24 {
+		t.Fatalf("expected delivery cost without stock on hand, got %v", got)
+	}
+	if got := weightedCostPrice(10, 0, 5, 24); got != 24 {
+		t.Fatalf("expected delivery cost when current cost is unknown, got %v", got)
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/recommend"
+)
+
+const (
+	defaultRecommendationLimit = 8
+	maxCartRecommendationItems = 100
+)
+
+func parseRecommendationLimit(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultRecommendationLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+	return min(limit, recommend.TopN), nil
+}
+
+// topLevelIDs maps product IDs to the products heading their variant group,
+// dropping unknown IDs, in the given order.
+func topLevelIDs(ctx context.Context, db *mongo.Database, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := db.Collection("products").Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "isDeleted": bson.M{"$ne": true}},
+		options.Find().SetProjection(bson.M{"parentId": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID       primitive.ObjectID  `bson:"_id"`
+		ParentID *primitive.ObjectID `bson:"parentId"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	topOf := make(map[primitive.ObjectID]primitive.ObjectID, len(rows))
+	for _, row := range rows {
+		topOf[row.ID] = row.ID
+		if row.ParentID != nil {
+			topOf[row.ID] = *row.ParentID
+		}
+	}
+
+	out := make([]primitive.ObjectID, 0, len(ids))
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range ids {
+		if top, ok := topOf[id]; ok && !seen[top] {
+			seen[top] = true
+			out = append(out, top)
+		}
+	}
+	return out, nil
+}
+
+// mergeRelated adds up the scores of the related products of several
+// products, best first, leaving out the products in exclude.
+func mergeRelated(relations []models.ProductRelations, exclude map[primitive.ObjectID]bool) []primitive.ObjectID {
+	scores := map[primitive.ObjectID]float64{}
+	for _, relation := range relations {
+		for _, related := range relation.Related {
+			if !exclude[related.ProductID] {
+				scores[related.ProductID] += related.Score
+			}
+		}
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i].Hex() < ids[j].Hex()
+	})
+	return ids
+}
+
+// hasStock reports whether a product, or any active variant of its group,
+// can be ordered.
+func hasStock(product models.Product) bool {
+	if product.Stock > 0 {
+		return true
+	}
+	for _, variant := range product.Variants {
+		if variant.InStock {
+			return true
+		}
+	}
+	return false
+}
+
+// recommendProducts returns up to limit products related to the given
+// top-level products, skipping them and any inactive, deleted or
+// out-of-stock product.
+func recommendProducts(ctx context.Context, db *mongo.Database, productIDs []primitive.ObjectID, limit int) ([]models.Product, error) {
+	products := []models.Product{}
+	if len(productIDs) == 0 {
+		return products, nil
+	}
+
+	cursor, err := db.Collection(recommend.Collection).Find(ctx, bson.M{"_id": bson.M{"$in": productIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var relations []models.ProductRelations
+	err = cursor.All(ctx, &relations)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := make(map[primitive.ObjectID]bool, len(productIDs))
+	for _, id := range productIDs {
+		exclude[id] = true
+	}
+	candidateIDs := mergeRelated(relations, exclude)
+	if len(candidateIDs) == 0 {
+		return products, nil
+	}
+
+	found, err := db.Collection("products").Find(ctx,
+		bson.M{
+			"_id":       bson.M{"$in": candidateIDs},
+			"isActive":  bson.M{"$ne": false},
+			"isDeleted": bson.M{"$ne": true},
+			"parentId":  topLevelProductFilter,
+		},
+		options.Find().SetProjection(publicProductProjection),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer found.Close(ctx)
+
+	candidates, err := decodeProducts(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachVariants(ctx, db, candidates, true); err != nil {
+		return nil, err
+	}
+
+	for _, product := range orderProductsByIDs(candidates, candidateIDs) {
+		if len(products) == limit {
+			break
+		}
+		if hasStock(product) {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+/*
+GET /products/:id/related
+- Sık birlikte alınan ürünler, en güçlü ilişki önce
+- Varyant verilirse ana ürünün ilişkileri döner
+- Pasif, silinmiş ve stokta olmayan ürünler listelenmez
+- ?limit= varsayılan 8, en fazla 20
+*/
+func GetRelatedProducts(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /products/:id/related"
+		defer handlePanic(c, route)
+
+		productID, err := primitive.ObjectIDFromHex(strings.TrimSpace(c.Param("id")))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid product id")
+			return
+		}
+		limit, err := parseRecommendationLimit(c.Query("limit"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		if err := ensureDBConnection(c.Request.Context(), db); err != nil {
+			respondWithError(c, http.StatusServiceUnavailable, route, "database unavailable")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		ids, err := topLevelIDs(ctx, db, []primitive.ObjectID{productID})
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if len(ids) == 0 {
+			respondWithError(c, http.StatusNotFound, route, "product not found")
+			return
+		}
+
+		products, err := recommendProducts(ctx, db, ids, limit)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		log.Printf("[%s] returning %d products for %s", route, len(products), productID.Hex())
+		c.JSON(http.StatusOK, gin.H{"data": products})
+	}
+}
+
+/*
+GET /user/cart/recommendations?productId=a,b
+- Sepet istemcide tutulur; sepetteki ürün id'leri productId ile gönderilir
+- Sepetteki ürünlerle sık birlikte alınan ürünler, skorlar toplanarak sıralanır
+- Sepette olan ürünler (varyantları dahil) önerilmez
+- Pasif, silinmiş ve stokta olmayan ürünler listelenmez
+- ?limit= varsayılan 8, en fazla 20
+*/
+func GetCartRecommendations(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /user/cart/recommendations"
+		defer handlePanic(c, route)
+
+		values := queryList(c.Request.URL.Query(), "productId")
+		if len(values) > maxCartRecommendationItems {
+			respondWithError(c, http.StatusBadRequest, route, "too many products")
+			return
+		}
+		cartIDs := make([]primitive.ObjectID, 0, len(values))
+		for _, value := range values {
+			id, err := primitive.ObjectIDFromHex(value)
+			if err != nil {
+				respondWithError(c, http.StatusBadRequest, route, "invalid productId")
+				return
+			}
+			cartIDs = append(cartIDs, id)
+		}
+		limit, err := parseRecommendationLimit(c.Query("limit"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		if len(cartIDs) == 0 {
+			c.JSON(http.StatusOK, gin.H{"data": []models.Product{}})
+			return
+		}
+
+		if err := ensureDBConnection(c.Request.Context(), db); err != nil {
+			respondWithError(c, http.StatusServiceUnavailable, route, "database unavailable")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		ids, err := topLevelIDs(ctx, db, cartIDs)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		products, err := recommendProducts(ctx, db, ids, limit)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		log.Printf("[%s] returning %d products for %d cart items", route, len(products), len(cartIDs))
+		c.JSON(http.StatusOK, gin.H{"data": products})
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestMergeRelatedSumsScoresAndSkipsCart(t *testing.T) {
+	bread, cheese, olives, tea := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	relations := []models.ProductRelations{
+		{ProductID: bread, Related: []models.RelatedProduct{{ProductID: cheese, Score: 3}, {ProductID: olives, Score: 2}}},
+		{ProductID: cheese, Related: []models.RelatedProduct{{ProductID: bread, Score: 3}, {ProductID: olives, Score: 2}, {ProductID: tea, Score: 1}}},
+	}
+
+	got := mergeRelated(relations, map[primitive.ObjectID]bool{bread: true, cheese: true})
+	if len(got) != 2 || got[0] != olives || got[1] != tea {
+		t.Fatalf("expected [olives tea], got %v", got)
+	}
+}
+
+func TestHasStockLooksAtVariants(t *testing.T) {
+	if hasStock(models.Product{}) {
+		t.Fatalf("product without stock should be skipped")
+	}
+	grouped := models.Product{Variants: []models.ProductVariant{{InStock: false}, {InStock: true}}}
+	if !hasStock(grouped) {
+		t.Fatalf("group with a variant in stock should be recommended")
+	}
+}
+
+func TestParseRecommendationLimit(t *testing.T) {
+	if limit, err := parseRecommendationLimit(""); err != nil || limit != defaultRecommendationLimit {
+		t.Fatalf("expected default limit, got %d %v", limit, err)
+	}
+	if limit, err := parseRecommendationLimit("500"); err != nil || limit != 20 {
+		t.Fatalf("expected limit capped at 20, got %d %v", limit, err)
+	}
+	if _, err := parseRecommendationLimit("0"); err == nil {
+		t.Fatalf("expected error for zero limit")
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/alerts"
+	"backend/internal/models"
+)
+
+// crossedReorderLevel reports whether a decrement took the stock from above
+// the reorder level to at or below it, so each drop alerts only once.
+func crossedReorderLevel(before, after, level float64) bool {
+	return level > 0 && before > level && after <= level
+}
+
+func newLowStockEvent(product models.Product, movement models.StockMovement) alerts.LowStockEvent {
+	event := alerts.LowStockEvent{
+		ProductID:    product.ID.Hex(),
+		Name:         product.Name,
+		Barcode:      product.Barcode,
+		Stock:        movement.After,
+		ReorderLevel: product.ReorderLevel,
+		At:           movement.CreatedAt,
+	}
+	if movement.OrderID != nil {
+		event.OrderID = movement.OrderID.Hex()
+	}
+	return event
+}
+
+// dispatchLowStockAlerts sends the alerts after the response path is done;
+// a slow or failing notifier must not hold up or fail the order.
+func dispatchLowStockAlerts(notifier alerts.Notifier, events []alerts.LowStockEvent) {
+	if notifier == nil || len(events) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, event := range events {
+			if err := notifier.NotifyLowStock(ctx, event); err != nil {
+				log.Printf("[ALERT] [ERROR] low stock notification failed for product=%s: %v", event.ProductID, err)
+			}
+		}
+	}()
+}
+
+/*
+GET /admin/api/products/low-stock
+- reorderLevel > 0 olan ve stoğu bu seviyede ya da altında olan ürünler, en az stoktan başlayarak
+- ?includeInactive=true → pasif ürünler de listelenir
+- ?format=csv
+*/
+func GetLowStockProducts(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/products/low-stock"
+
+		filter := bson.M{
+			"isDeleted":    bson.M{"$ne": true},
+			"reorderLevel": bson.M{"$gt": 0},
+			"$expr":        bson.M{"$lte": bson.A{"$stock", "$reorderLevel"}},
+		}
+		if includeInactive, _ := parseBoolValue(c.Query("includeInactive")); !includeInactive {
+			filter["isActive"] = bson.M{"$ne": false}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		cursor, err := db.Collection("products").Find(ctx, filter,
+			options.Find().SetSort(bson.D{{Key: "stock", Value: 1}, {Key: "name", Value: 1}}),
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer cursor.Close(ctx)
+
+		products, err := decodeProducts(ctx, cursor)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "decode error")
+			return
+		}
+
+		if wantsCSV(c) {
+			records := make([][]string, 0, len(products))
+			for _, product := range products {
+				records = append(records, []string{
+					product.ID.Hex(),
+					product.Barcode,
+					product.Name,
+					product.Brand,
+					strconv.FormatFloat(product.Stock, 'f', -1, 64),
+					strconv.FormatFloat(product.ReorderLevel, 'f', -1, 64),
+				})
+			}
+			writeCSV(c, "dusuk-stok.csv", []string{"id", "barcode", "name", "brand", "stock", "reorderLevel"}, records)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": products, "total": len(products)})
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import "testing"
+
+func TestCrossedReorderLevel(t *testing.T) {
+	cases := []struct {
+		before, after, level float64
+		want                 bool
+	}{
+		{before: 12, after: 10, level: 10, want: true},
+		{before: 12, after: 3, level: 10, want: true},
+		{before: 12, after: 11, level: 10, want: false},
+		{before: 9, after: 7, level: 10, want: false}, // already alerted
+		{before: 5, after: 0, level: 0, want: false},  // alerts off
+	}
+	for _, tc := range cases {
+		if got := crossedReorderLevel(tc.before, tc.after, tc.level); got != tc.want {
+			t.Errorf("crossedReorderLevel(%v, %v, %v) = %v, want %v", tc.before, tc.after, tc.level, got, tc.want)
+		}
+	}
+}
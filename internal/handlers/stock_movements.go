@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// errStockUnavailable means the product is missing, deleted, or has less
+// stock than a decrement needs.
+var errStockUnavailable = errors.New("stock unavailable")
+
+type stockAdjustmentRequest struct {
+	Quantity float64 `json:"quantity" binding:"required"`
+	Reason   string  `json:"reason" binding:"required"`
+}
+
+// runInTransaction runs fn in a MongoDB transaction, like CreateOrder does,
+// so stock changes and their ledger entries commit together.
+func runInTransaction(ctx context.Context, db *mongo.Database, fn func(sessCtx mongo.SessionContext) error) error {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+// adjustStock adds delta to the product's stock and records the movement.
+// A decrement only applies while enough stock is left. The result is rounded
+// to grams so weighed stock does not collect float noise. Call it inside a
+// transaction.
+func adjustStock(ctx context.Context, db *mongo.Database, productID primitive.ObjectID, delta float64, movement models.StockMovement) (models.StockMovement, error) {
+	delta = roundQuantity(delta)
+	filter := bson.M{"_id": productID, "isDeleted": bson.M{"$ne": true}}
+	if delta < 0 {
+		filter["stock"] = bson.M{"$gte": -delta}
+	}
+
+	set := bson.M{
+		"stock": bson.M{"$round": bson.A{bson.M{"$add": bson.A{"$stock", delta}}, 3}},
+	}
+	if sold := soldCountDelta(movement.Type, delta); sold != 0 {
+		set["soldCount"] = bson.M{"$round": bson.A{bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$soldCount", 0}}, sold}}, 3}}
+	}
+
+	var after bson.M
+	err := db.Collection("products").FindOneAndUpdate(ctx,
+		filter,
+		mongo.Pipeline{{{Key: "$set", Value: set}}},
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{"stock": 1}),
+	).Decode(&after)
+	if err == mongo.ErrNoDocuments {
+		return models.StockMovement{}, errStockUnavailable
+	}
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	movement.ProductID = productID
+	movement.Quantity = delta
+	movement.After = parseLooseNumber(after["stock"])
+	movement.Before = roundQuantity(movement.After - delta)
+	if err := recordStockMovement(ctx, db, &movement); err != nil {
+		return models.StockMovement{}, err
+	}
+	return movement, nil
+}
+
+// soldCountDelta is how a stock change moves the product's soldCount, the
+// popularity used for sorting: sales add, cancellations give back.
+func soldCountDelta(movementType string, delta float64) float64 {
+	switch movementType {
+	case models.StockMovementSale, models.StockMovementCancellation:
+		return -delta
+	default:
+		return 0
+	}
+}
+
+// updateProductRecordingStock applies a product update and, when it sets the
+// stock, records the difference as a movement in the same transaction.
+func updateProductRecordingStock(ctx context.Context, db *mongo.Database, productID primitive.ObjectID, update bson.M, newStock *float64, movement models.StockMovement) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": productID, "isDeleted": bson.M{"$ne": true}}
+	if newStock == nil {
+		return db.Collection("products").UpdateOne(ctx, filter, update)
+	}
+
+	result := &mongo.UpdateResult{}
+	err := runInTransaction(ctx, db, func(sessCtx mongo.SessionContext) error {
+		var before bson.M
+		err := db.Collection("products").FindOneAndUpdate(sessCtx,
+			filter,
+			update,
+			options.FindOneAndUpdate().
+				SetReturnDocument(options.Before).
+				SetProjection(bson.M{"stock": 1}),
+		).Decode(&before)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result.MatchedCount = 1
+		result.ModifiedCount = 1
+
+		previous := parseLooseNumber(before["stock"])
+		if previous == *newStock {
+			return nil
+		}
+		movement.ProductID = productID
+		movement.Before = previous
+		movement.After = *newStock
+		movement.Quantity = roundQuantity(*newStock - previous)
+		return recordStockMovement(sessCtx, db, &movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// errOrderStatusChanged means another request changed the order's status
+// between reading and updating it.
+var errOrderStatusChanged = errors.New("order status changed")
+
+// changeOrderStatus updates the status and moves stock when the order enters
+// or leaves "cancelled": cancelling puts the items back and marks the order
+// stockReturned, reopening takes them again only if that mark is set. Both
+// happen in one transaction with the status change.
+func changeOrderStatus(ctx context.Context, db *mongo.Database, order models.Order, status string, now time.Time, actor models.StockActor) error {
+	filter := bson.M{"_id": order.ID, "status": order.Status}
+	set := bson.M{"status": status, "updatedAt": now}
+	update := bson.M{"$set": set}
+
+	restock := status == "cancelled" && order.Status != "cancelled"
+	reopen := order.Status == "cancelled" && status != "cancelled" && order.StockReturned
+	switch {
+	case restock:
+		set["stockReturned"] = true
+	case reopen:
+		filter["stockReturned"] = true
+		update["$unset"] = bson.M{"stockReturned": ""}
+	}
+	if !restock && !reopen {
+		res, err := db.Collection("orders").UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return errOrderStatusChanged
+		}
+		return nil
+	}
+
+	return runInTransaction(ctx, db, func(sessCtx mongo.SessionContext) error {
+		res, err := db.Collection("orders").UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return errOrderStatusChanged
+		}
+
+		for _, item := range order.Items {
+			movement := models.StockMovement{
+				Type:      models.StockMovementCancellation,
+				Reason:    "sipariş iptali",
+				OrderID:   &order.ID,
+				Actor:     actor,
+				CreatedAt: now,
+			}
+			delta := item.Quantity
+			if reopen {
+				movement.Type = models.StockMovementSale
+				movement.Reason = "sipariş yeniden açıldı"
+				delta = -item.Quantity
+			}
+
+			_, err := adjustStock(sessCtx, db, item.ProductID, delta, movement)
+			if errors.Is(err, errStockUnavailable) && restock {
+				// The product was deleted meanwhile; there is no stock to return.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func recordStockMovement(ctx context.Context, db *mongo.Database, movement *models.StockMovement) error {
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = time.Now()
+	}
+	if movement.Actor.Type == "" {
+		movement.Actor.Type = "system"
+	}
+	res, err := db.Collection("stock_movements").InsertOne(ctx, movement)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+		movement.ID = id
+	}
+	return nil
+}
+
+// stockActorFromContext reads who is acting from the auth middleware: admin
+// routes store JWT claims, user routes store the user ID.
+func stockActorFromContext(c *gin.Context) models.StockActor {
+	if value, ok := c.Get("claims"); ok {
+		if claims, ok := value.(jwt.MapClaims); ok {
+			actor := models.StockActor{Type: "admin"}
+			if role, _ := claims["role"].(string); role != "" && role != models.RoleAdmin {
+				actor.Type = role
+			}
+			if sub, _ := claims["sub"].(string); sub != "" {
+				if id, err := primitive.ObjectIDFromHex(sub); err == nil {
+					actor.ID = &id
+				}
+			}
+			actor.Email, _ = claims["email"].(string)
+			return actor
+		}
+	}
+	if value, ok := c.Get("userId"); ok {
+		if id, ok := value.(primitive.ObjectID); ok {
+			return models.StockActor{Type: "user", ID: &id}
+		}
+	}
+	return models.StockActor{Type: "system"}
+}
+
+/*
+GET /admin/api/products/:id/stock-movements
+- Ürünün stok hareketleri, en yeniden eskiye (?page, ?limit)
+*/
+func GetStockMovements(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/products/:id/stock-movements"
+
+		productID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		page, limit, err := parsePaginationParams(c.Query("page"), c.Query("limit"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		filter := bson.M{"productId": productID}
+		total, err := db.Collection("stock_movements").CountDocuments(ctx, filter)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		cursor, err := db.Collection("stock_movements").Find(ctx, filter,
+			options.Find().
+				SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+				SetSkip((page-1)*limit).
+				SetLimit(limit),
+		)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer cursor.Close(ctx)
+
+		movements := make([]models.StockMovement, 0)
+		if err := cursor.All(ctx, &movements); err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "decode error")
+			return
+		}
+
+		totalPages := int64(0)
+		if total > 0 {
+			totalPages = int64(math.Ceil(float64(total) / float64(limit)))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": movements,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"totalPages": totalPages,
+			},
+		})
+	}
+}
+
+/*
+POST /admin/api/products/:id/stock-movements
+- Elle stok düzeltmesi: {"quantity": -3, "reason": "Kırık ürün"}
+- quantity işaretlidir; stok sıfırın altına düşemez
+- kg ile satılan ürünlerde ondalıklı miktar (en fazla 3 hane) kabul edilir
+*/
+func CreateStockAdjustment(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "POST /admin/api/products/:id/stock-movements"
+
+		productID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, route, "invalid id")
+			return
+		}
+
+		var req stockAdjustmentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			respondWithError(c, http.StatusBadRequest, route, "reason required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var product models.Product
+		err = db.Collection("products").FindOne(ctx,
+			bson.M{"_id": productID, "isDeleted": bson.M{"$ne": true}},
+			options.FindOne().SetProjection(bson.M{"unit": 1}),
+		).Decode(&product)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			respondWithError(c, http.StatusNotFound, route, "product not found")
+			return
+		}
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if err := validateQuantity(product.Unit, req.Quantity); err != nil {
+			respondWithError(c, http.StatusBadRequest, route, err.Error())
+			return
+		}
+
+		var movement models.StockMovement
+		err = runInTransaction(ctx, db, func(sessCtx mongo.SessionContext) error {
+			var err error
+			movement, err = adjustStock(sessCtx, db, productID, req.Quantity, models.StockMovement{
+				Type:   models.StockMovementAdjustment,
+				Reason: reason,
+				Actor:  stockActorFromContext(c),
+			})
+			return err
+		})
+		if errors.Is(err, errStockUnavailable) {
+			respondWithError(c, http.StatusConflict, route, "product not found or insufficient stock")
+			return
+		}
+		if err != nil {
+			log.Printf("[%s] adjustment failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusCreated, movement)
+	}
+}
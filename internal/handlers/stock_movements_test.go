@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStockActorFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adminID := primitive.NewObjectID()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("claims", jwt.MapClaims{"sub": adminID.Hex(), "role": "admin", "email": "admin@example.com"})
+	actor := stockActorFromContext(c)
+	if actor.Type != "admin" || actor.ID == nil || *actor.ID != adminID || actor.Email != "admin@example.com" {
+		t.Fatalf("unexpected admin actor %+v", actor)
+	}
+
+	userID := primitive.NewObjectID()
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("userId", userID)
+	actor = stockActorFromContext(c)
+	if actor.Type != "user" || actor.ID == nil || *actor.ID != userID {
+		t.Fatalf("unexpected user actor %+v", actor)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	if actor = stockActorFromContext(c); actor.Type != "system" || actor.ID != nil {
+		t.Fatalf("unexpected fallback actor %+v", actor)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// defaultStoreSettings is used until an admin saves the store header.
+var defaultStoreSettings = models.StoreSettings{
+	ID:            models.StoreSettingsID,
+	Name:          "Hereve Market",
+	ReceiptFooter: "Bizi tercih ettiğiniz için teşekkür ederiz.",
+}
+
+type storeSettingsRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Address       string `json:"address"`
+	Phone         string `json:"phone"`
+	Email         string `json:"email"`
+	Website       string `json:"website"`
+	TaxOffice     string `json:"taxOffice"`
+	TaxNumber     string `json:"taxNumber"`
+	ReceiptFooter string `json:"receiptFooter"`
+}
+
+func loadStoreSettings(ctx context.Context, db *mongo.Database) (models.StoreSettings, error) {
+	var settings models.StoreSettings
+	err := db.Collection("settings").FindOne(ctx, bson.M{"_id": models.StoreSettingsID}).Decode(&settings)
+	if err == mongo.ErrNoDocuments {
+		return defaultStoreSettings, nil
+	}
+	if err != nil {
+		return models.StoreSettings{}, err
+	}
+	return settings, nil
+}
+
+/*
+GET /admin/api/settings/store
+- Fiş ve etiketlerde basılan mağaza bilgileri
+*/
+func GetStoreSettings(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /admin/api/settings/store"
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		settings, err := loadStoreSettings(ctx, db)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+/*
+PUT /admin/api/settings/store
+- Tüm alanları birlikte kaydeder
+*/
+func UpdateStoreSettings(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "PUT /admin/api/settings/store"
+
+		var req storeSettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		settings := models.StoreSettings{
+			ID:            models.StoreSettingsID,
+			Name:          strings.TrimSpace(req.Name),
+			Address:       strings.TrimSpace(req.Address),
+			Phone:         strings.TrimSpace(req.Phone),
+			Email:         strings.TrimSpace(req.Email),
+			Website:       strings.TrimSpace(req.Website),
+			TaxOffice:     strings.TrimSpace(req.TaxOffice),
+			TaxNumber:     strings.TrimSpace(req.TaxNumber),
+			ReceiptFooter: strings.TrimSpace(req.ReceiptFooter),
+			UpdatedAt:     time.Now(),
+		}
+		if settings.Name == "" {
+			respondWithError(c, http.StatusBadRequest, route, "name required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		if _, err := db.Collection("settings").ReplaceOne(ctx,
+			bson.M{"_id": models.StoreSettingsID},
+			settings,
+			options.Replace().SetUpsert(true),
+		); err != nil {
+			log.Printf("[%s] save failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	}
+}
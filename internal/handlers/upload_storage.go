@@ -1,41 +1,47 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path"
-	"path/filepath"
+	"net/http"
 	"strings"
-)
 
-const publicRootDir = "/app/public"
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/storage"
+)
 
-func safeDeleteUpload(relPath string) error {
-	trimmed := strings.TrimSpace(relPath)
-	if trimmed == "" {
+// safeDeleteUpload removes an uploaded file from storage. Empty paths are
+// ignored and anything outside "uploads/" is refused.
+func safeDeleteUpload(ctx context.Context, uploads storage.Storage, relPath string) error {
+	if strings.TrimSpace(relPath) == "" {
 		return nil
 	}
-
-	cleanRel := path.Clean("/" + strings.TrimPrefix(trimmed, "/"))
-	cleanRel = strings.TrimPrefix(cleanRel, "/")
-
-	if !strings.HasPrefix(cleanRel, "uploads/") {
+	key, err := storage.CleanKey(relPath)
+	if err != nil {
 		return fmt.Errorf("refusing to delete non-upload path: %s", relPath)
 	}
+	return uploads.Delete(ctx, key)
+}
 
-	cleanBase := filepath.Clean(publicRootDir)
-	targetPath := filepath.Join(cleanBase, filepath.FromSlash(cleanRel))
-	cleanTarget := filepath.Clean(targetPath)
-	if cleanTarget != cleanBase && !strings.HasPrefix(cleanTarget, cleanBase+string(os.PathSeparator)) {
-		return fmt.Errorf("refusing to delete path outside public root: %s", relPath)
-	}
-
-	if err := os.Remove(cleanTarget); err != nil {
-		if os.IsNotExist(err) {
-			return nil
+/*
+GET /public/*filepath
+- Yerel depoda dosyalar diskten sunulur
+- S3 kullanılırken /public/uploads/... istekleri depo URL'sine yönlendirilir
+- Veritabanındaki "uploads/..." yolları depo değişince de çalışır
+*/
+func ServePublic(root string, uploads storage.Storage) gin.HandlerFunc {
+	files := gin.Dir(root, false)
+	_, local := uploads.(*storage.Local)
+
+	return func(c *gin.Context) {
+		rel := c.Param("filepath")
+		if !local {
+			if key, err := storage.CleanKey(rel); err == nil {
+				c.Redirect(http.StatusFound, uploads.URL(key))
+				return
+			}
 		}
-		return err
+		c.FileFromFS(rel, files)
 	}
-
-	return nil
 }
@@ -13,6 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/models"
 )
@@ -57,6 +58,7 @@ func GetMe(db *mongo.Database) gin.HandlerFunc {
 			"email":     user.Email,
 			"name":      user.Name,
 			"phone":     user.Phone,
+			"role":      user.AccountRole(),
 			"addresses": user.Addresses,
 			"createdAt": user.CreatedAt,
 			"updatedAt": user.UpdatedAt,
@@ -81,7 +83,7 @@ func UpdateMe(db *mongo.Database) gin.HandlerFunc {
 		}
 
 		name := strings.TrimSpace(req.Name)
-		phone := strings.TrimSpace(req.Phone)
+		phone := normalizePhoneOrRaw(req.Phone)
 		if name == "" || phone == "" || len(name) > 100 || len(phone) > 32 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
 			return
@@ -91,12 +93,16 @@ func UpdateMe(db *mongo.Database) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		result, err := db.Collection("users").UpdateByID(ctx, userID, bson.M{
-			"$set": bson.M{
+		// A new number is no longer the one verified by phone login.
+		result, err := db.Collection("users").UpdateByID(ctx, userID, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
 				"name":      name,
 				"phone":     phone,
 				"updatedAt": updatedAt,
-			},
+				"phoneVerified": bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$phone", phone}}, "$phoneVerified", "$$REMOVE",
+				}},
+			}}},
 		})
 		if err != nil {
 			log.Println("[AUTH] [ERROR] update me failed:", err)
@@ -399,7 +405,7 @@ func GetUserFavorites(db *mongo.Database) gin.HandlerFunc {
 		cursor, err := db.Collection("products").Find(ctx, bson.M{
 			"_id":       bson.M{"$in": user.Favorites},
 			"isDeleted": bson.M{"$ne": true},
-		})
+		}, options.Find().SetProjection(publicProductProjection))
 		if err != nil {
 			log.Println("[FAVORITE] [ERROR] list favorites products failed:", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
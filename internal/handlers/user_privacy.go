@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// anonymizedOrderCustomer replaces the contact block of orders that belonged
+// to a deleted account. Items and totals are kept for accounting.
+var anonymizedOrderCustomer = models.OrderCustomer{Title: "Silinmiş kullanıcı"}
+
+type dataExportProfile struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email,omitempty"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type dataExportFavorite struct {
+	ProductID string `json:"productId"`
+	Name      string `json:"name,omitempty"`
+}
+
+type dataExport struct {
+	ExportedAt time.Time            `json:"exportedAt"`
+	Profile    dataExportProfile    `json:"profile"`
+	Addresses  []models.Address     `json:"addresses"`
+	Favorites  []dataExportFavorite `json:"favorites"`
+	Orders     []models.Order       `json:"orders"`
+}
+
+/*
+GET /user/data-export
+- KVKK kapsamında kullanıcının tüm kişisel verisi
+- ?format=zip → her bölüm ayrı JSON dosyası olarak ZIP
+*/
+func ExportMyData(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "GET /user/data-export"
+
+		userIDValue, exists := c.Get("userId")
+		if !exists {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		userID, ok := userIDValue.(primitive.ObjectID)
+		if !ok {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+		if format != "json" && format != "zip" {
+			respondWithError(c, http.StatusBadRequest, route, "format must be json or zip")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+		defer cancel()
+
+		export, err := buildDataExport(ctx, db, userID)
+		if err == mongo.ErrNoDocuments {
+			respondWithError(c, http.StatusNotFound, route, "user not found")
+			return
+		}
+		if err != nil {
+			log.Printf("[%s] export failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		filename := fmt.Sprintf("herevemarket-verilerim-%s", export.ExportedAt.Format("20060102"))
+		if format == "json" {
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+			c.JSON(http.StatusOK, export)
+			return
+		}
+
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+		c.Status(http.StatusOK)
+
+		archive := zip.NewWriter(c.Writer)
+		files := []struct {
+			name string
+			data interface{}
+		}{
+			{"profile.json", export.Profile},
+			{"addresses.json", export.Addresses},
+			{"favorites.json", export.Favorites},
+			{"orders.json", export.Orders},
+		}
+		for _, file := range files {
+			w, err := archive.Create(file.name)
+			if err != nil {
+				log.Printf("[%s] zip entry failed: %v", route, err)
+				return
+			}
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(file.data); err != nil {
+				log.Printf("[%s] zip write failed: %v", route, err)
+				return
+			}
+		}
+		if err := archive.Close(); err != nil {
+			log.Printf("[%s] zip close failed: %v", route, err)
+		}
+	}
+}
+
+// newDataExport fills the export from the user document; favorites and
+// orders are added by buildDataExport.
+func newDataExport(user models.User, now time.Time) dataExport {
+	export := dataExport{
+		ExportedAt: now,
+		Profile: dataExportProfile{
+			ID:        user.ID.Hex(),
+			Email:     user.Email,
+			Name:      user.Name,
+			Phone:     user.Phone,
+			Role:      user.AccountRole(),
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		},
+		Addresses: user.Addresses,
+		Favorites: []dataExportFavorite{},
+		Orders:    []models.Order{},
+	}
+	if export.Addresses == nil {
+		export.Addresses = []models.Address{}
+	}
+	return export
+}
+
+func buildDataExport(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) (dataExport, error) {
+	var user models.User
+	if err := db.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		return dataExport{}, err
+	}
+
+	export := newDataExport(user, time.Now())
+	if len(user.Favorites) > 0 {
+		cursor, err := db.Collection("products").Find(ctx,
+			bson.M{"_id": bson.M{"$in": user.Favorites}},
+			options.Find().SetProjection(bson.M{"name": 1}),
+		)
+		if err != nil {
+			return dataExport{}, err
+		}
+		var products []struct {
+			ID   primitive.ObjectID `bson:"_id"`
+			Name string             `bson:"name"`
+		}
+		if err := cursor.All(ctx, &products); err != nil {
+			return dataExport{}, err
+		}
+		nameByID := make(map[primitive.ObjectID]string, len(products))
+		for _, product := range products {
+			nameByID[product.ID] = product.Name
+		}
+		for _, productID := range user.Favorites {
+			export.Favorites = append(export.Favorites, dataExportFavorite{
+				ProductID: productID.Hex(),
+				Name:      nameByID[productID],
+			})
+		}
+	}
+
+	cursor, err := db.Collection("orders").Find(ctx,
+		bson.M{"userId": userID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	)
+	if err != nil {
+		return dataExport{}, err
+	}
+	if err := cursor.All(ctx, &export.Orders); err != nil {
+		return dataExport{}, err
+	}
+
+	return export, nil
+}
+
+/*
+DELETE /auth/me
+- Hesabı anonimleştirir: kişisel bilgiler ve siparişlerdeki müşteri bilgisi silinir
+- Sipariş tutarları muhasebe için kalır, tüm refresh token'lar iptal edilir
+- Access token'lar UserAuth'taki hesap kontrolüyle hemen geçersiz olur
+*/
+func DeleteMe(db *mongo.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const route = "DELETE /auth/me"
+
+		userIDValue, exists := c.Get("userId")
+		if !exists {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		userID, ok := userIDValue.(primitive.ObjectID)
+		if !ok {
+			respondWithError(c, http.StatusUnauthorized, route, "unauthorized")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var user models.User
+		if err := db.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+			if err == mongo.ErrNoDocuments {
+				respondWithError(c, http.StatusNotFound, route, "user not found")
+				return
+			}
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		if user.DeletedAt != nil {
+			respondWithError(c, http.StatusGone, route, "account already deleted")
+			return
+		}
+
+		session, err := db.Client().StartSession()
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+		defer session.EndSession(ctx)
+
+		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			return nil, anonymizeUser(sessCtx, db, user, time.Now())
+		})
+		if err != nil {
+			log.Printf("[%s] anonymize failed: %v", route, err)
+			respondWithError(c, http.StatusInternalServerError, route, "db error")
+			return
+		}
+
+		clearRefreshCookie(c)
+		log.Println("[AUTH] [INFO] account deleted:", userID.Hex())
+		c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+	}
+}
+
+// anonymizedUserUpdate clears every personal field of a user and marks the
+// account deleted, which also shuts out its remaining access tokens.
+func anonymizedUserUpdate(now time.Time) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"name":      "",
+			"addresses": []models.Address{},
+			"favorites": []primitive.ObjectID{},
+			"isActive":  false,
+			"deletedAt": now,
+			"updatedAt": now,
+		},
+		"$unset": bson.M{
+			"email":         "",
+			"phone":         "",
+			"phoneVerified": "",
+			"passwordHash":  "",
+		},
+	}
+}
+
+func anonymizeUser(ctx context.Context, db *mongo.Database, user models.User, now time.Time) error {
+	if _, err := db.Collection("users").UpdateByID(ctx, user.ID, anonymizedUserUpdate(now)); err != nil {
+		return err
+	}
+
+	if _, err := db.Collection("orders").UpdateMany(ctx,
+		bson.M{"userId": user.ID},
+		bson.M{"$set": bson.M{"customer": anonymizedOrderCustomer}},
+	); err != nil {
+		return err
+	}
+
+	if _, err := db.Collection("refresh_tokens").UpdateMany(ctx,
+		bson.M{"userId": user.ID, "revoked": false},
+		bson.M{"$set": bson.M{"revoked": true}},
+	); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(user.Phone) != "" {
+		if _, err := db.Collection("otp_codes").DeleteMany(ctx,
+			bson.M{"phone": bson.M{"$in": phoneLookupVariants(normalizePhoneOrRaw(user.Phone))}},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
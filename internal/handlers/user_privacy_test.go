@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestAnonymizedUserUpdateClearsPersonalData(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	update := anonymizedUserUpdate(now)
+
+	set, ok := update["$set"].(bson.M)
+	if !ok {
+		t.Fatalf("expected $set, got %v", update)
+	}
+	if set["name"] != "" || set["isActive"] != false || set["deletedAt"] != now {
+		t.Fatalf("account must be emptied and marked deleted: %v", set)
+	}
+	if addresses, ok := set["addresses"].([]models.Address); !ok || len(addresses) != 0 {
+		t.Fatalf("addresses must be cleared: %v", set["addresses"])
+	}
+	if favorites, ok := set["favorites"].([]primitive.ObjectID); !ok || len(favorites) != 0 {
+		t.Fatalf("favorites must be cleared: %v", set["favorites"])
+	}
+
+	unset, ok := update["$unset"].(bson.M)
+	if !ok {
+		t.Fatalf("expected $unset, got %v", update)
+	}
+	for _, field := range []string{"email", "phone", "phoneVerified", "passwordHash"} {
+		if _, ok := unset[field]; !ok {
+			t.Errorf("%s must be removed", field)
+		}
+	}
+}
+
+func TestNewDataExportHasProfileWithoutSecrets(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := models.User{
+		ID:           primitive.NewObjectID(),
+		Email:        "ayse@example.com",
+		PasswordHash: "$2a$10$secret",
+		Name:         "Ayşe",
+		Phone:        "+905551112233",
+		IsActive:     true,
+		CreatedAt:    now.Add(-24 * time.Hour),
+	}
+
+	export := newDataExport(user, now)
+	if export.Profile.ID != user.ID.Hex() || export.Profile.Email != user.Email || export.Profile.Phone != user.Phone {
+		t.Fatalf("unexpected profile %+v", export.Profile)
+	}
+	if export.Profile.Role != models.RoleUser || !export.ExportedAt.Equal(now) {
+		t.Fatalf("unexpected role or export time %+v", export)
+	}
+
+	payload, err := json.Marshal(export)
+	if err != nil {
+		t.Fatalf("marshal export: %v", err)
+	}
+	body := string(payload)
+	if strings.Contains(body, "secret") || strings.Contains(body, "passwordHash") {
+		t.Fatalf("export must not carry the password hash: %s", body)
+	}
+	for _, section := range []string{`"addresses":[]`, `"favorites":[]`, `"orders":[]`} {
+		if !strings.Contains(body, section) {
+			t.Errorf("expected empty %s in %s", section, body)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package imaging turns uploaded product photos into the sizes the apps
+// display. Every upload is decoded and re-encoded, which drops EXIF and any
+// other metadata; the EXIF orientation is applied to the pixels first so
+// phone photos stay upright.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Size is one rendition of an upload, bounded by MaxDimension on both sides.
+type Size struct {
+	Name         string
+	MaxDimension int
+}
+
+// Sizes are produced for every upload, largest first.
+var Sizes = []Size{
+	{Name: "large", MaxDimension: 1200},
+	{Name: "medium", MaxDimension: 600},
+	{Name: "thumb", MaxDimension: 200},
+}
+
+// MaxPixels rejects images that would take too much memory to decode.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image type (jpeg, png or webp)")
+	ErrTooManyPixels     = errors.New("image dimensions too large")
+)
+
+// Rendition is one encoded size of an upload.
+type Rendition struct {
+	Size        Size
+	Data        []byte
+	Ext         string
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Sniff reports the format from the file content; the file name and the
+// client's content type are not trusted.
+func Sniff(data []byte) (string, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "jpeg", nil
+	case "image/png":
+		return "png", nil
+	case "image/webp":
+		return "webp", nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Decode sniffs and decodes an upload and applies its EXIF orientation.
+func Decode(data []byte) (image.Image, error) {
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, nil
+}
+
+// Resize fits img inside a max x max box keeping its aspect ratio. Smaller
+// images are returned as they are; they are never upscaled.
+func Resize(img image.Image, max int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= max && height <= max {
+		return img
+	}
+	if width >= height {
+		height = height * max / width
+		width = max
+	} else {
+		width = width * max / height
+		height = max
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Process decodes an upload and encodes every size in Sizes. Each size is
+// scaled from the previous one, which is much faster than scaling the
+// original three times.
+func Process(data []byte, encoder Encoder) ([]Rendition, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	renditions := make([]Rendition, 0, len(Sizes))
+	for _, size := range Sizes {
+		img = Resize(img, size.MaxDimension)
+		rendition, err := encoder.Encode(img)
+		if err != nil {
+			return nil, err
+		}
+		rendition.Size = size
+		rendition.Width = img.Bounds().Dx()
+		rendition.Height = img.Bounds().Dy()
+		renditions = append(renditions, rendition)
+	}
+	return renditions, nil
+}
+
+// Encoder writes a processed image in the format it serves.
+type Encoder interface {
+	Encode(img image.Image) (Rendition, error)
+}
+
+// StandardEncoder uses the standard library: JPEG for opaque images and PNG
+// when transparency has to be kept.
+type StandardEncoder struct {
+	Quality int
+}
+
+func (e StandardEncoder) Encode(img image.Image) (Rendition, error) {
+	var buf bytes.Buffer
+	if isOpaque(img) {
+		quality := e.Quality
+		if quality <= 0 {
+			quality = 82
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return Rendition{}, err
+		}
+		return Rendition{Data: buf.Bytes(), Ext: ".jpg", ContentType: "image/jpeg"}, nil
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return Rendition{}, err
+	}
+	return Rendition{Data: buf.Bytes(), Ext: ".png", ContentType: "image/png"}, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
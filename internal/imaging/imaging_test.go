@@ -0,0 +1,96 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts a minimal little-endian EXIF block after SOI.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 1, 0}
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestSniffIgnoresExtensionAndRejectsOtherContent(t *testing.T) {
+	if format, err := Sniff(testJPEG(t, 4, 4)); err != nil || format != "jpeg" {
+		t.Fatalf("expected jpeg, got %q %v", format, err)
+	}
+	if _, err := Sniff([]byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>")); err != ErrUnsupportedFormat {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestResizeFitsBoxWithoutUpscaling(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1600, 800))
+	resized := Resize(img, 600)
+	if b := resized.Bounds(); b.Dx() != 600 || b.Dy() != 300 {
+		t.Fatalf("unexpected size %v", b)
+	}
+	small := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	if Resize(small, 600) != image.Image(small) {
+		t.Fatal("small images must not be upscaled")
+	}
+}
+
+func TestDecodeAppliesOrientationAndProcessStripsExif(t *testing.T) {
+	data := withOrientation(testJPEG(t, 40, 20), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("expected orientation 6, got %d", got)
+	}
+
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Fatalf("expected rotated 20x40, got %v", b)
+	}
+
+	renditions, err := Process(data, StandardEncoder{})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if len(renditions) != len(Sizes) {
+		t.Fatalf("expected %d renditions, got %d", len(Sizes), len(renditions))
+	}
+	for _, r := range renditions {
+		if bytes.Contains(r.Data, []byte("Exif")) {
+			t.Fatalf("%s rendition still carries EXIF", r.Size.Name)
+		}
+		if r.Ext != ".jpg" {
+			t.Fatalf("expected opaque image as jpeg, got %s", r.Ext)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// WebPEncoder encodes through the cwebp tool from libwebp, since Go has no
+// pure WebP encoder. When cwebp fails the Fallback encoder is used so an
+// upload never fails just because of the format.
+type WebPEncoder struct {
+	Binary   string
+	Quality  int
+	Fallback Encoder
+}
+
+// NewEncoder returns a WebP encoder when cwebp is installed and the standard
+// JPEG/PNG encoder otherwise.
+func NewEncoder() Encoder {
+	fallback := StandardEncoder{Quality: 82}
+	binary, err := exec.LookPath("cwebp")
+	if err != nil {
+		log.Println("[IMAGING] cwebp not found, product images are stored as JPEG/PNG")
+		return fallback
+	}
+	return WebPEncoder{Binary: binary, Quality: 80, Fallback: fallback}
+}
+
+func (e WebPEncoder) Encode(img image.Image) (Rendition, error) {
+	data, err := e.encode(img)
+	if err != nil {
+		log.Printf("[IMAGING] [WARN] webp encode failed, falling back: %v", err)
+		return e.Fallback.Encode(img)
+	}
+	return Rendition{Data: data, Ext: ".webp", ContentType: "image/webp"}, nil
+}
+
+func (e WebPEncoder) encode(img image.Image) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "webp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "in.png")
+	output := filepath.Join(dir, "out.webp")
+
+	var buf bytes.Buffer
+	if err := (&png.Encoder{CompressionLevel: png.NoCompression}).Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(input, buf.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+
+	quality := e.Quality
+	if quality <= 0 {
+		quality = 80
+	}
+	cmd := exec.Command(e.Binary, "-quiet", "-metadata", "none", "-q", strconv.Itoa(quality), input, "-o", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("cwebp: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return os.ReadFile(output)
+}
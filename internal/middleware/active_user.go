@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrUserInactive is returned by ActiveUser for accounts that were deleted,
+// deactivated or never existed.
+var ErrUserInactive = errors.New("user is inactive")
+
+// ActiveUser checks that a token's user can still use the API. Tokens stay
+// valid until they expire, so a deleted or deactivated account is only shut
+// out by this lookup.
+func ActiveUser(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) error {
+	err := db.Collection("users").FindOne(ctx,
+		bson.M{"_id": userID, "deletedAt": bson.M{"$exists": false}, "isActive": bson.M{"$ne": false}},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrUserInactive
+	}
+	return err
+}
@@ -5,10 +5,11 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+
+	"backend/internal/auth"
 )
 
-func AuthGuard(secret string, allowedRoles ...string) gin.HandlerFunc {
+func AuthGuard(tokens *auth.TokenService, allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		raw := strings.TrimSpace(c.GetHeader("Authorization"))
 		if raw == "" {
@@ -22,16 +23,8 @@ func AuthGuard(secret string, allowedRoles ...string) gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(parts[1], func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		claims, err := tokens.Parse(parts[1])
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
@@ -56,6 +49,6 @@ func AuthGuard(secret string, allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
-func AdminAuth(secret string) gin.HandlerFunc {
-	return AuthGuard(secret, "admin")
+func AdminAuth(tokens *auth.TokenService) gin.HandlerFunc {
+	return AuthGuard(tokens, "admin")
 }
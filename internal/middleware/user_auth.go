@@ -1,17 +1,23 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/auth"
 )
 
-// UserAuth validates user JWT tokens and injects the userId into the context.
-func UserAuth(secret string) gin.HandlerFunc {
+// UserAuth validates user JWT tokens, rejects those of deleted or inactive
+// accounts and injects the userId into the context.
+func UserAuth(tokens *auth.TokenService, db *mongo.Database) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		raw := strings.TrimSpace(c.GetHeader("Authorization"))
 		if raw == "" {
@@ -27,22 +33,13 @@ func UserAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(parts[1], func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
+		claims, err := tokens.Parse(parts[1])
+		if err != nil {
 			log.Println("[AUTH] [ERROR] token validation failed:", err)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			log.Println("[AUTH] [ERROR] token claims invalid")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-			return
-		}
-
 		userIDValue, ok := claims["userId"].(string)
 		if !ok || strings.TrimSpace(userIDValue) == "" {
 			log.Println("[AUTH] [ERROR] userId claim missing")
@@ -57,6 +54,20 @@ func UserAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+		err = ActiveUser(ctx, db, userID)
+		cancel()
+		if errors.Is(err, ErrUserInactive) {
+			log.Println("[AUTH] [ERROR] token of deleted or inactive user:", userID.Hex())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if err != nil {
+			log.Println("[AUTH] [ERROR] user lookup failed:", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "database unavailable"})
+			return
+		}
+
 		log.Println("[AUTH] [INFO] user token validated")
 		c.Set("userId", userID)
 		c.Next()
@@ -0,0 +1,171 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/internal/models"
+)
+
+// AccountReport summarizes a customers → users migration run.
+type AccountReport struct {
+	Customers       int      `json:"customers"`
+	Inserted        int      `json:"inserted"`
+	Merged          int      `json:"merged"`
+	AlreadyMigrated int      `json:"alreadyMigrated"`
+	BackfilledUsers int64    `json:"backfilledUsers"`
+	Merges          []string `json:"merges,omitempty"`
+}
+
+// MigrateCustomersToUsers copies every legacy customer into the users
+// collection, keeping its ObjectID so orders and refresh tokens that reference
+// it keep resolving. When a user with the same email already exists the
+// customer's orders and refresh tokens are re-pointed to that user instead.
+// Finally, users created before roles existed get role=user and isActive=true.
+//
+// The customers collection is left untouched so the run can be repeated and
+// audited; drop it manually once the result has been verified.
+func MigrateCustomersToUsers(ctx context.Context, db *mongo.Database, dryRun bool) (AccountReport, error) {
+	report := AccountReport{}
+
+	cursor, err := db.Collection("customers").Find(ctx, bson.M{})
+	if err != nil {
+		return report, err
+	}
+	defer cursor.Close(ctx)
+
+	var customers []models.Customer
+	if err := cursor.All(ctx, &customers); err != nil {
+		return report, err
+	}
+	report.Customers = len(customers)
+
+	users := db.Collection("users")
+	for _, customer := range customers {
+		email := strings.ToLower(strings.TrimSpace(customer.Email))
+
+		if err := users.FindOne(ctx, bson.M{"_id": customer.ID}).Err(); err == nil {
+			report.AlreadyMigrated++
+			continue
+		} else if err != mongo.ErrNoDocuments {
+			return report, err
+		}
+
+		var existing models.User
+		err := users.FindOne(ctx, bson.M{"email": email}).Decode(&existing)
+		if err == nil {
+			report.Merged++
+			report.Merges = append(report.Merges, fmt.Sprintf("%s: customer %s -> user %s", email, customer.ID.Hex(), existing.ID.Hex()))
+			if dryRun {
+				continue
+			}
+			if err := mergeCustomerIntoUser(ctx, db, customer, existing); err != nil {
+				return report, fmt.Errorf("merge %s: %w", email, err)
+			}
+			continue
+		}
+		if err != mongo.ErrNoDocuments {
+			return report, err
+		}
+
+		report.Inserted++
+		if dryRun {
+			continue
+		}
+		if _, err := users.InsertOne(ctx, UserFromCustomer(customer)); err != nil {
+			return report, fmt.Errorf("insert %s: %w", email, err)
+		}
+		log.Printf("[MIGRATE] customer %s migrated to users", customer.ID.Hex())
+	}
+
+	backfilled, err := BackfillUserDefaults(ctx, db, dryRun)
+	report.BackfilledUsers = backfilled
+	return report, err
+}
+
+// BackfillUserDefaults gives users saved before roles existed role=user and
+// isActive=true, so they are not refused as inactive at login. It only
+// touches documents missing a field, so it runs at every startup as well as
+// from migrate-accounts.
+func BackfillUserDefaults(ctx context.Context, db *mongo.Database, dryRun bool) (int64, error) {
+	users := db.Collection("users")
+	backfill := bson.M{"$or": bson.A{
+		bson.M{"role": bson.M{"$exists": false}},
+		bson.M{"isActive": bson.M{"$exists": false}},
+	}}
+	if dryRun {
+		return users.CountDocuments(ctx, backfill)
+	}
+
+	res, err := users.UpdateMany(ctx, backfill, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"role":     bson.M{"$ifNull": bson.A{"$role", models.RoleUser}},
+			"isActive": bson.M{"$ifNull": bson.A{"$isActive", true}},
+		}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if res.ModifiedCount > 0 {
+		log.Printf("[MIGRATE] user defaults backfilled: %d", res.ModifiedCount)
+	}
+	return res.ModifiedCount, nil
+}
+
+// UserFromCustomer maps a legacy customer onto the unified account model.
+func UserFromCustomer(customer models.Customer) models.User {
+	role := strings.TrimSpace(customer.Role)
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	updatedAt := customer.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	return models.User{
+		ID:           customer.ID,
+		Email:        strings.ToLower(strings.TrimSpace(customer.Email)),
+		PasswordHash: customer.PasswordHash,
+		Name:         strings.TrimSpace(strings.TrimSpace(customer.FirstName) + " " + strings.TrimSpace(customer.LastName)),
+		Phone:        strings.TrimSpace(customer.Phone),
+		Role:         role,
+		IsActive:     customer.IsActive,
+		Addresses:    []models.Address{},
+		CreatedAt:    customer.CreatedAt,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+func mergeCustomerIntoUser(ctx context.Context, db *mongo.Database, customer models.Customer, user models.User) error {
+	if _, err := db.Collection("orders").UpdateMany(ctx,
+		bson.M{"userId": customer.ID},
+		bson.M{"$set": bson.M{"userId": user.ID}},
+	); err != nil {
+		return err
+	}
+
+	if _, err := db.Collection("refresh_tokens").UpdateMany(ctx,
+		bson.M{"userId": customer.ID},
+		bson.M{"$set": bson.M{"userId": user.ID}},
+	); err != nil {
+		return err
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	if customer.Role == models.RoleAdmin {
+		set["role"] = models.RoleAdmin
+	}
+	if strings.TrimSpace(user.Phone) == "" && strings.TrimSpace(customer.Phone) != "" {
+		set["phone"] = strings.TrimSpace(customer.Phone)
+	}
+	_, err := db.Collection("users").UpdateByID(ctx, user.ID, bson.M{"$set": set})
+	return err
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestUserFromCustomerKeepsIDAndMapsFields(t *testing.T) {
+	id := primitive.NewObjectID()
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	user := UserFromCustomer(models.Customer{
+		ID:           id,
+		FirstName:    " Ayşe ",
+		LastName:     "Yılmaz",
+		Email:        " Ayse@Example.com ",
+		Phone:        "05551234567",
+		PasswordHash: "hash",
+		IsActive:     true,
+		Role:         "admin",
+		CreatedAt:    createdAt,
+	})
+
+	if user.ID != id {
+		t.Fatalf("expected ObjectID to be preserved, got %s", user.ID.Hex())
+	}
+	if user.Name != "Ayşe Yılmaz" {
+		t.Fatalf("unexpected name %q", user.Name)
+	}
+	if user.Email != "ayse@example.com" {
+		t.Fatalf("unexpected email %q", user.Email)
+	}
+	if user.Role != models.RoleAdmin || !user.IsActive || user.PasswordHash != "hash" {
+		t.Fatalf("unexpected account fields: %+v", user)
+	}
+	if !user.CreatedAt.Equal(createdAt) || user.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected timestamps: %+v", user)
+	}
+}
+
+func TestUserFromCustomerDefaultsRole(t *testing.T) {
+	user := UserFromCustomer(models.Customer{FirstName: "Ali"})
+	if user.Role != models.RoleUser {
+		t.Fatalf("expected default role user, got %q", user.Role)
+	}
+	if user.Name != "Ali" {
+		t.Fatalf("unexpected name %q", user.Name)
+	}
+}
@@ -0,0 +1,218 @@
+package migrations
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+// CategorySlugReport summarizes a run of BackfillCategorySlugs.
+type CategorySlugReport struct {
+	Categories int               `json:"categories"`
+	Updated    int               `json:"updated"`
+	Slugs      map[string]string `json:"slugs,omitempty"` // name → new slug
+}
+
+// BackfillCategorySlugs gives every category created before slugs existed a
+// unique slug from its name. Categories that already have one are left
+// alone, so it can be re-run.
+func BackfillCategorySlugs(ctx context.Context, db *mongo.Database, dryRun bool) (CategorySlugReport, error) {
+	report := CategorySlugReport{Slugs: map[string]string{}}
+
+	categories := db.Collection("categories")
+	cursor, err := categories.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return report, err
+	}
+	var all []models.Category
+	if err := cursor.All(ctx, &all); err != nil {
+		return report, err
+	}
+	report.Categories = len(all)
+
+	used := map[string]bool{}
+	for _, category := range all {
+		if category.Slug != "" {
+			used[category.Slug] = true
+		}
+	}
+
+	for _, category := range all {
+		if category.Slug != "" {
+			continue
+		}
+		base := search.Slug(category.Name)
+		if base == "" {
+			base = "kategori"
+		}
+		slug := search.UniqueSlug(base, func(s string) bool { return used[s] })
+		used[slug] = true
+		report.Slugs[category.Name] = slug
+		if dryRun {
+			continue
+		}
+		if _, err := categories.UpdateOne(ctx,
+			bson.M{"_id": category.ID},
+			bson.M{"$set": bson.M{"slug": slug}},
+		); err != nil {
+			return report, err
+		}
+		report.Updated++
+	}
+
+	log.Printf("[MIGRATE] category slugs: categories=%d updated=%d dryRun=%v", report.Categories, report.Updated, dryRun)
+	return report, nil
+}
+
+// ProductCategoryReport summarizes a run of LinkProductCategories.
+type ProductCategoryReport struct {
+	Products      int            `json:"products"`
+	Updated       int            `json:"updated"`
+	AlreadyLinked int            `json:"alreadyLinked"`
+	Unlinked      int            `json:"unlinked"`
+	Created       []string       `json:"created,omitempty"`
+	Unresolved    map[string]int `json:"unresolved,omitempty"` // name → products
+}
+
+// linkCategories maps a product's category names to categories, keeping the
+// order and dropping duplicates. Names without a category are returned
+// separately.
+func linkCategories(names []string, byName map[string]models.Category) (ids []primitive.ObjectID, linked []string, unresolved []string) {
+	seen := map[primitive.ObjectID]bool{}
+	for _, name := range names {
+		category, ok := byName[search.Key(name)]
+		if !ok {
+			if strings.TrimSpace(name) != "" {
+				unresolved = append(unresolved, name)
+			}
+			continue
+		}
+		if seen[category.ID] {
+			continue
+		}
+		seen[category.ID] = true
+		ids = append(ids, category.ID)
+		linked = append(linked, category.Name)
+	}
+	return ids, linked, unresolved
+}
+
+// LinkProductCategories sets categoryIds on every product from the category
+// names it stores, and rewrites the names to the categories' exact spelling.
+// With createMissing, names that match no category become new top-level
+// categories; otherwise they are reported and the product is left unlinked,
+// so category and categoryIds always list the same categories in the same
+// order. Products whose categoryIds are already set are skipped, so it can
+// be re-run.
+func LinkProductCategories(ctx context.Context, db *mongo.Database, createMissing, dryRun bool) (ProductCategoryReport, error) {
+	report := ProductCategoryReport{Unresolved: map[string]int{}}
+
+	cursor, err := db.Collection("categories").Find(ctx, bson.M{})
+	if err != nil {
+		return report, err
+	}
+	var categories []models.Category
+	if err := cursor.All(ctx, &categories); err != nil {
+		return report, err
+	}
+	byName := make(map[string]models.Category, len(categories))
+	usedSlugs := map[string]bool{}
+	for _, category := range categories {
+		byName[search.Key(category.Name)] = category
+		usedSlugs[category.Slug] = true
+	}
+
+	products := db.Collection("products")
+	cursor, err = products.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"category": 1, "categoryIds": 1}))
+	if err != nil {
+		return report, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var product struct {
+			ID          primitive.ObjectID   `bson:"_id"`
+			Category    models.StringList    `bson:"category"`
+			CategoryIDs []primitive.ObjectID `bson:"categoryIds"`
+		}
+		if err := cursor.Decode(&product); err != nil {
+			return report, err
+		}
+		report.Products++
+		if len(product.CategoryIDs) > 0 {
+			report.AlreadyLinked++
+			continue
+		}
+
+		ids, names, unresolved := linkCategories(product.Category, byName)
+		if len(unresolved) > 0 && !createMissing {
+			for _, name := range unresolved {
+				report.Unresolved[name]++
+			}
+			report.Unlinked++
+			continue
+		}
+		for _, name := range unresolved {
+			if existing, ok := byName[search.Key(name)]; ok {
+				// Created for an earlier spelling of the same name.
+				if !slices.Contains(ids, existing.ID) {
+					ids = append(ids, existing.ID)
+					names = append(names, existing.Name)
+				}
+				continue
+			}
+			category := models.Category{
+				ID:        primitive.NewObjectID(),
+				Name:      strings.TrimSpace(name),
+				IsActive:  true,
+				CreatedAt: time.Now(),
+			}
+			base := search.Slug(category.Name)
+			if base == "" {
+				base = "kategori"
+			}
+			category.Slug = search.UniqueSlug(base, func(s string) bool { return usedSlugs[s] })
+			if !dryRun {
+				if _, err := db.Collection("categories").InsertOne(ctx, category); err != nil {
+					return report, err
+				}
+			}
+			usedSlugs[category.Slug] = true
+			byName[search.Key(category.Name)] = category
+			report.Created = append(report.Created, category.Name)
+			ids = append(ids, category.ID)
+			names = append(names, category.Name)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		report.Updated++
+		if dryRun {
+			continue
+		}
+		if _, err := products.UpdateOne(ctx,
+			bson.M{"_id": product.ID},
+			bson.M{"$set": bson.M{"categoryIds": ids, "category": names}},
+		); err != nil {
+			return report, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return report, err
+	}
+
+	log.Printf("[MIGRATE] product categories: products=%d updated=%d unlinked=%d created=%d unresolved=%d dryRun=%v",
+		report.Products, report.Updated, report.Unlinked, len(report.Created), len(report.Unresolved), dryRun)
+	return report, nil
+}
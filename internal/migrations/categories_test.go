@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+	"backend/internal/search"
+)
+
+func TestLinkCategoriesMatchesLooselyAndKeepsOrder(t *testing.T) {
+	sut := models.Category{ID: primitive.NewObjectID(), Name: "Süt Ürünleri"}
+	icecek := models.Category{ID: primitive.NewObjectID(), Name: "İçecek"}
+	byName := map[string]models.Category{
+		search.Key(sut.Name):    sut,
+		search.Key(icecek.Name): icecek,
+	}
+
+	ids, names, unresolved := linkCategories([]string{"ICECEK", "süt  ürünleri", "İçecek", "Yok", ""}, byName)
+	if len(ids) != 2 || ids[0] != icecek.ID || ids[1] != sut.ID {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+	if names[0] != "İçecek" || names[1] != "Süt Ürünleri" {
+		t.Fatalf("names must use the category spelling, got %v", names)
+	}
+	if len(unresolved) != 1 || unresolved[0] != "Yok" {
+		t.Fatalf("unexpected unresolved %v", unresolved)
+	}
+}
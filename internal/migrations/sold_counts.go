@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SoldCountReport summarizes a run of BackfillSoldCounts.
+type SoldCountReport struct {
+	Products int `json:"products"`
+	Updated  int `json:"updated"`
+}
+
+// BackfillSoldCounts sets every product's soldCount, the popularity used to
+// sort listings, from the quantities in non-cancelled orders. New sales keep
+// it current; run this once for the orders placed before the field existed.
+// Re-running recomputes the same totals.
+func BackfillSoldCounts(ctx context.Context, db *mongo.Database, dryRun bool) (SoldCountReport, error) {
+	report := SoldCountReport{}
+
+	cursor, err := db.Collection("orders").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$ne": "cancelled"}}}},
+		{{Key: "$unwind", Value: "$items"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":  "$items.productId",
+			"sold": bson.M{"$sum": "$items.quantity"},
+		}}},
+	})
+	if err != nil {
+		return report, err
+	}
+	defer cursor.Close(ctx)
+
+	products := db.Collection("products")
+	for cursor.Next(ctx) {
+		var row struct {
+			ProductID primitive.ObjectID `bson:"_id"`
+			Sold      float64            `bson:"sold"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return report, err
+		}
+		report.Products++
+		if dryRun {
+			continue
+		}
+		res, err := products.UpdateOne(ctx,
+			bson.M{"_id": row.ProductID},
+			bson.M{"$set": bson.M{"soldCount": row.Sold}},
+		)
+		if err != nil {
+			return report, err
+		}
+		report.Updated += int(res.ModifiedCount)
+	}
+	if err := cursor.Err(); err != nil {
+		return report, err
+	}
+
+	log.Printf("[MIGRATE] sold counts: products=%d updated=%d dryRun=%v", report.Products, report.Updated, dryRun)
+	return report, nil
+}
@@ -0,0 +1,174 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"path"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// UploadReport summarizes a run of MigrateUploads.
+type UploadReport struct {
+	Products       int      `json:"products"`
+	Files          int      `json:"files"`
+	Copied         int      `json:"copied"`
+	AlreadyPresent int      `json:"alreadyPresent"`
+	UpdatedPaths   int      `json:"updatedPaths"`
+	Missing        []string `json:"missing,omitempty"`
+	Invalid        []string `json:"invalid,omitempty"`
+}
+
+// MigrateUploads copies every product image referenced in the database from
+// the local upload directory to the configured storage and rewrites stored
+// paths to plain keys ("/public/uploads/x.jpg" -> "uploads/x.jpg").
+//
+// Files already present in the target are not copied again, so the run can
+// be repeated. Local files are never deleted; remove them once the new
+// backend has been verified.
+func MigrateUploads(ctx context.Context, db *mongo.Database, source *storage.Local, target storage.Storage, dryRun bool) (UploadReport, error) {
+	report := UploadReport{}
+	products := db.Collection("products")
+
+	cursor, err := products.Find(ctx,
+		bson.M{"$or": []bson.M{
+			{"imagePath": bson.M{"$nin": []interface{}{nil, ""}}},
+			{"images.0": bson.M{"$exists": true}},
+		}},
+		options.Find().SetProjection(bson.M{"imagePath": 1, "images": 1}),
+	)
+	if err != nil {
+		return report, err
+	}
+	defer cursor.Close(ctx)
+
+	seen := map[string]bool{}
+	for cursor.Next(ctx) {
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			return report, err
+		}
+		report.Products++
+
+		normalized, changed, invalid := NormalizeImageKeys(product)
+		for _, raw := range invalid {
+			report.Invalid = append(report.Invalid, fmt.Sprintf("%s: %s", product.ID.Hex(), raw))
+		}
+
+		for _, key := range imageKeys(normalized) {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			report.Files++
+
+			if err := copyUpload(ctx, source, target, key, dryRun, &report); err != nil {
+				return report, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+
+		if !changed {
+			continue
+		}
+		report.UpdatedPaths++
+		if dryRun {
+			continue
+		}
+		set := bson.M{}
+		if normalized.ImagePath != "" {
+			set["imagePath"] = normalized.ImagePath
+		}
+		if len(normalized.Images) > 0 {
+			set["images"] = normalized.Images
+		}
+		if _, err := products.UpdateOne(ctx, bson.M{"_id": product.ID}, bson.M{"$set": set}); err != nil {
+			return report, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return report, err
+	}
+
+	log.Printf("[MIGRATE] uploads: products=%d files=%d copied=%d present=%d updated=%d missing=%d dryRun=%v",
+		report.Products, report.Files, report.Copied, report.AlreadyPresent, report.UpdatedPaths, len(report.Missing), dryRun)
+	return report, nil
+}
+
+func copyUpload(ctx context.Context, source *storage.Local, target storage.Storage, key string, dryRun bool, report *UploadReport) error {
+	present, err := target.Exists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if present {
+		report.AlreadyPresent++
+		return nil
+	}
+
+	data, err := source.Read(key)
+	if err != nil {
+		report.Missing = append(report.Missing, key)
+		return nil
+	}
+	report.Copied++
+	if dryRun {
+		return nil
+	}
+	return target.Put(ctx, key, data, mime.TypeByExtension(path.Ext(key)))
+}
+
+// NormalizeImageKeys rewrites a product's image paths to storage keys. It
+// reports whether anything changed and which paths could not be turned into
+// a key; those are left as they are.
+func NormalizeImageKeys(product models.Product) (models.Product, bool, []string) {
+	changed := false
+	var invalid []string
+
+	normalize := func(raw string) string {
+		if raw == "" {
+			return raw
+		}
+		key, err := storage.CleanKey(raw)
+		if err != nil {
+			invalid = append(invalid, raw)
+			return raw
+		}
+		if key != raw {
+			changed = true
+		}
+		return key
+	}
+
+	product.ImagePath = normalize(product.ImagePath)
+	images := make([]models.ProductImage, len(product.Images))
+	for i, image := range product.Images {
+		image.Thumbnail = normalize(image.Thumbnail)
+		image.Medium = normalize(image.Medium)
+		image.Large = normalize(image.Large)
+		images[i] = image
+	}
+	product.Images = images
+	return product, changed, invalid
+}
+
+func imageKeys(product models.Product) []string {
+	var keys []string
+	add := func(raw string) {
+		if key, err := storage.CleanKey(raw); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	add(product.ImagePath)
+	for _, image := range product.Images {
+		add(image.Thumbnail)
+		add(image.Medium)
+		add(image.Large)
+	}
+	return keys
+}
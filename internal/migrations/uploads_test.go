@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestNormalizeImageKeysStripsPublicPrefixes(t *testing.T) {
+	product := models.Product{
+		ImagePath: "/public/uploads/products/a-medium.jpg",
+		Images: []models.ProductImage{{
+			ID:        "a",
+			Thumbnail: "uploads/products/a-thumb.jpg",
+			Medium:    "/uploads/products/a-medium.jpg",
+			Large:     "uploads/products/a-large.jpg",
+		}},
+	}
+
+	normalized, changed, invalid := NormalizeImageKeys(product)
+	if !changed || len(invalid) != 0 {
+		t.Fatalf("expected a clean change, got changed=%v invalid=%v", changed, invalid)
+	}
+	if normalized.ImagePath != "uploads/products/a-medium.jpg" || normalized.Images[0].Medium != "uploads/products/a-medium.jpg" {
+		t.Fatalf("unexpected keys: %+v", normalized)
+	}
+	if product.Images[0].Medium != "/uploads/products/a-medium.jpg" {
+		t.Fatal("the input product must not be modified")
+	}
+
+	if _, changed, _ := NormalizeImageKeys(normalized); changed {
+		t.Fatal("normalized keys must be left alone on a second run")
+	}
+}
+
+func TestNormalizeImageKeysKeepsForeignPaths(t *testing.T) {
+	product := models.Product{ImagePath: "https://cdn.example.com/x.jpg"}
+	normalized, changed, invalid := NormalizeImageKeys(product)
+	if changed || len(invalid) != 1 || normalized.ImagePath != product.ImagePath {
+		t.Fatalf("expected the foreign path to be kept and reported, got %+v %v %v", normalized, changed, invalid)
+	}
+}
@@ -6,9 +6,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Category is one node of the category tree. Top-level categories have no
+// ParentID; siblings are shown by Order, then name.
 type Category struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name      string             `bson:"name" json:"name"`
-	IsActive  bool               `bson:"isActive" json:"isActive"`
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name      string              `bson:"name" json:"name"`
+	Slug      string              `bson:"slug,omitempty" json:"slug"`
+	ParentID  *primitive.ObjectID `bson:"parentId,omitempty" json:"parentId"`
+	Order     int                 `bson:"order" json:"order"`
+	Image     *ProductImage       `bson:"image,omitempty" json:"image,omitempty"`
+	IsActive  bool                `bson:"isActive" json:"isActive"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
 }
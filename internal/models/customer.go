@@ -6,6 +6,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Customer is the legacy account document from the "customers" collection.
+// It is only read by the account migration; new accounts are always Users.
 type Customer struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	FirstName    string             `bson:"firstName" json:"firstName"`
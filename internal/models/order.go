@@ -6,12 +6,19 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// OrderItem represents a single product entry within an order.
+// OrderItem represents a single product entry within an order. Quantity is a
+// count for piece products and kilograms for weighed (kg) products; Price is
+// per piece or per kg accordingly.
+// OriginalPrice is set only when the item was bought on sale. CostPrice is
+// the product's cost at the time of sale, kept for margin reports only.
 type OrderItem struct {
-	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
-	Name      string             `bson:"name" json:"name"`
-	Price     float64            `bson:"price" json:"price"`
-	Quantity  int                `bson:"quantity" json:"quantity"`
+	ProductID     primitive.ObjectID `bson:"productId" json:"productId"`
+	Name          string             `bson:"name" json:"name"`
+	Price         float64            `bson:"price" json:"price"`
+	OriginalPrice float64            `bson:"originalPrice,omitempty" json:"originalPrice,omitempty"`
+	Quantity      float64            `bson:"quantity" json:"quantity"`
+	Unit          string             `bson:"unit,omitempty" json:"unit,omitempty"`
+	CostPrice     float64            `bson:"costPrice,omitempty" json:"-"`
 }
 
 // OrderCustomer captures lightweight customer contact details for an order.
@@ -30,6 +37,10 @@ type Order struct {
 	Customer      OrderCustomer       `bson:"customer" json:"customer"`
 	PaymentMethod string              `bson:"paymentMethod" json:"paymentMethod"`
 	Status        string              `bson:"status" json:"status"`
-	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
-	UpdatedAt     *time.Time          `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	// StockReturned is set while a cancelled order's items are back in
+	// stock. Orders cancelled before cancelling restocked never had it, so
+	// reopening them must not take the stock again.
+	StockReturned bool       `bson:"stockReturned,omitempty" json:"-"`
+	CreatedAt     time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     *time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
 }
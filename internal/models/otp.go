@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OTPCode is a one-time login code sent to a phone number. Only the hash of
+// the code is stored.
+type OTPCode struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Phone     string             `bson:"phone" json:"phone"`
+	CodeHash  string             `bson:"codeHash" json:"-"`
+	Attempts  int                `bson:"attempts" json:"attempts"`
+	Consumed  bool               `bson:"consumed" json:"consumed"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
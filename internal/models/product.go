@@ -6,23 +6,79 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Units of measure. Piece products are sold in whole numbers; kg products are
+// priced per kilogram and sold by weight.
+const (
+	UnitPiece = "piece"
+	UnitKg    = "kg"
+)
+
+// Variant attributes a parent product can group its variants by.
+var VariantAttributes = map[string]struct{}{
+	"size":   {},
+	"weight": {},
+	"pack":   {},
+}
+
+// Product is one sellable SKU. Variants (e.g. "500 g" and "1 kg" of the same
+// tomato) are products of their own with ParentID pointing at the product
+// that heads the group; the parent sets VariantAttribute.
 type Product struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name        string             `bson:"name" json:"name"`
 	Price       float64            `bson:"price" json:"price"`
 	SaleEnabled bool               `bson:"saleEnabled" json:"saleEnabled"`
 	SalePrice   float64            `bson:"salePrice" json:"salePrice"`
+	CostPrice   float64            `bson:"costPrice" json:"costPrice,omitempty"`
 	IsOnSale    bool               `bson:"-" json:"isOnSale"`
 	Category    StringList         `bson:"category" json:"category"`
-	Description string             `bson:"description,omitempty" json:"description,omitempty"`
-	Barcode     string             `bson:"barcode,omitempty" json:"barcode,omitempty"`
-	Brand       string             `bson:"brand,omitempty" json:"brand,omitempty"`
-	ImagePath   string             `bson:"imagePath,omitempty" json:"imagePath,omitempty"`
-	Stock       int                `bson:"stock" json:"stock"`
-	InStock     bool               `bson:"-" json:"inStock"`
-	IsActive    bool               `bson:"isActive" json:"isActive"`
-	IsCampaign  bool               `bson:"isCampaign" json:"isCampaign"`
-	IsDeleted   bool               `bson:"isDeleted" json:"isDeleted,omitempty"`
-	DeletedAt   *time.Time         `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
-	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	// CategoryIDs reference the categories; Category holds their names,
+	// kept in sync on rename, for listings, search and exports.
+	CategoryIDs  []primitive.ObjectID `bson:"categoryIds,omitempty" json:"categoryIds,omitempty"`
+	Description  string               `bson:"description,omitempty" json:"description,omitempty"`
+	Barcode      string               `bson:"barcode,omitempty" json:"barcode,omitempty"`
+	Brand        string               `bson:"brand,omitempty" json:"brand,omitempty"`
+	ImagePath    string               `bson:"imagePath,omitempty" json:"imagePath,omitempty"`
+	Images       []ProductImage       `bson:"images,omitempty" json:"images,omitempty"`
+	Unit         string               `bson:"unit,omitempty" json:"unit"`
+	Stock        float64              `bson:"stock" json:"stock"`
+	ReorderLevel float64              `bson:"reorderLevel" json:"reorderLevel,omitempty"`
+	SoldCount    float64              `bson:"soldCount,omitempty" json:"soldCount,omitempty"`
+	InStock      bool                 `bson:"-" json:"inStock"`
+	IsActive     bool                 `bson:"isActive" json:"isActive"`
+	IsCampaign   bool                 `bson:"isCampaign" json:"isCampaign"`
+	IsDeleted    bool                 `bson:"isDeleted" json:"isDeleted,omitempty"`
+	DeletedAt    *time.Time           `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	CreatedAt    time.Time            `bson:"createdAt" json:"createdAt"`
+
+	ParentID         *primitive.ObjectID `bson:"parentId,omitempty" json:"parentId,omitempty"`
+	VariantAttribute string              `bson:"variantAttribute,omitempty" json:"variantAttribute,omitempty"`
+	VariantLabel     string              `bson:"variantLabel,omitempty" json:"variantLabel,omitempty"`
+	Variants         []ProductVariant    `bson:"-" json:"variants,omitempty"`
+}
+
+// ProductImage is one entry of a product's ordered gallery. Every upload is
+// stored in three sizes; ImagePath on the product mirrors the first image's
+// medium size for clients that only know a single image.
+type ProductImage struct {
+	ID        string `bson:"id" json:"id"`
+	Thumbnail string `bson:"thumbnail" json:"thumbnail"`
+	Medium    string `bson:"medium" json:"medium"`
+	Large     string `bson:"large" json:"large"`
+	Width     int    `bson:"width,omitempty" json:"width,omitempty"`
+	Height    int    `bson:"height,omitempty" json:"height,omitempty"`
+}
+
+// ProductVariant is the summary of one variant shown with its group.
+type ProductVariant struct {
+	ID             primitive.ObjectID `json:"id"`
+	Label          string             `json:"label"`
+	Barcode        string             `json:"barcode,omitempty"`
+	Unit           string             `json:"unit"`
+	Price          float64            `json:"price"`
+	SalePrice      float64            `json:"salePrice"`
+	EffectivePrice float64            `json:"effectivePrice"`
+	IsOnSale       bool               `json:"isOnSale"`
+	Stock          float64            `json:"stock"`
+	InStock        bool               `json:"inStock"`
 }
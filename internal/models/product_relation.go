@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RelatedProduct is a product often bought together with another one. Score
+// is the recency-weighted number of orders containing both.
+type RelatedProduct struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Score     float64            `bson:"score" json:"score"`
+}
+
+// ProductRelations holds the top related products of one top-level product,
+// best first. The recommendation job rewrites the whole collection on every
+// run.
+type ProductRelations struct {
+	ProductID primitive.ObjectID `bson:"_id" json:"productId"`
+	Related   []RelatedProduct   `bson:"related" json:"related"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
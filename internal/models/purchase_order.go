@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Purchase order statuses. A draft can still be edited; once ordered, only
+// receipts change the lines.
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderOrdered           = "ordered"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// PurchaseOrderLine is one product on a purchase order. UnitCost is the
+// expected cost agreed with the supplier, per piece or per kg by Unit.
+type PurchaseOrderLine struct {
+	ProductID        primitive.ObjectID `bson:"productId" json:"productId"`
+	Name             string             `bson:"name" json:"name"`
+	Barcode          string             `bson:"barcode,omitempty" json:"barcode,omitempty"`
+	Unit             string             `bson:"unit,omitempty" json:"unit,omitempty"`
+	Quantity         float64            `bson:"quantity" json:"quantity"`
+	ReceivedQuantity float64            `bson:"receivedQuantity" json:"receivedQuantity"`
+	UnitCost         float64            `bson:"unitCost" json:"unitCost"`
+}
+
+// PurchaseReceiptLine is what actually arrived for one product in a delivery.
+type PurchaseReceiptLine struct {
+	ProductID primitive.ObjectID `bson:"productId" json:"productId"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+	UnitCost  float64            `bson:"unitCost" json:"unitCost"`
+}
+
+// PurchaseReceipt records one delivery against a purchase order.
+type PurchaseReceipt struct {
+	ReceivedAt time.Time             `bson:"receivedAt" json:"receivedAt"`
+	Lines      []PurchaseReceiptLine `bson:"lines" json:"lines"`
+	Note       string                `bson:"note,omitempty" json:"note,omitempty"`
+	Actor      StockActor            `bson:"actor" json:"actor"`
+}
+
+type PurchaseOrder struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Code         string              `bson:"code" json:"code"`
+	SupplierID   primitive.ObjectID  `bson:"supplierId" json:"supplierId"`
+	SupplierName string              `bson:"supplierName" json:"supplierName"`
+	Status       string              `bson:"status" json:"status"`
+	Lines        []PurchaseOrderLine `bson:"lines" json:"lines"`
+	TotalCost    float64             `bson:"totalCost" json:"totalCost"`
+	Note         string              `bson:"note,omitempty" json:"note,omitempty"`
+	ExpectedAt   *time.Time          `bson:"expectedAt,omitempty" json:"expectedAt,omitempty"`
+	Receipts     []PurchaseReceipt   `bson:"receipts" json:"receipts"`
+	CreatedAt    time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    *time.Time          `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	ReceivedAt   *time.Time          `bson:"receivedAt,omitempty" json:"receivedAt,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// StoreSettingsID is the _id of the single store settings document.
+const StoreSettingsID = "store"
+
+// StoreSettings is the store identity printed on receipts and slips.
+type StoreSettings struct {
+	ID            string    `bson:"_id" json:"-"`
+	Name          string    `bson:"name" json:"name"`
+	Address       string    `bson:"address" json:"address"`
+	Phone         string    `bson:"phone" json:"phone"`
+	Email         string    `bson:"email,omitempty" json:"email,omitempty"`
+	Website       string    `bson:"website,omitempty" json:"website,omitempty"`
+	TaxOffice     string    `bson:"taxOffice,omitempty" json:"taxOffice,omitempty"`
+	TaxNumber     string    `bson:"taxNumber,omitempty" json:"taxNumber,omitempty"`
+	ReceiptFooter string    `bson:"receiptFooter,omitempty" json:"receiptFooter,omitempty"`
+	UpdatedAt     time.Time `bson:"updatedAt" json:"updatedAt"`
+}
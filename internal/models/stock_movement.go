@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Stock movement types.
+const (
+	StockMovementSale         = "sale"
+	StockMovementCancellation = "cancellation"
+	StockMovementAdjustment   = "adjustment"
+	StockMovementImport       = "import"
+	StockMovementReceipt      = "receipt"
+)
+
+// StockActor identifies who caused a stock change. Type is admin, user,
+// guest or system; guests and system jobs have no ID.
+type StockActor struct {
+	Type  string              `bson:"type" json:"type"`
+	ID    *primitive.ObjectID `bson:"id,omitempty" json:"id,omitempty"`
+	Email string              `bson:"email,omitempty" json:"email,omitempty"`
+}
+
+// StockMovement is one entry in a product's stock ledger. Quantity is the
+// signed change, so After = Before + Quantity.
+type StockMovement struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ProductID   primitive.ObjectID  `bson:"productId" json:"productId"`
+	Type        string              `bson:"type" json:"type"`
+	Quantity    float64             `bson:"quantity" json:"quantity"`
+	Before      float64             `bson:"before" json:"before"`
+	After       float64             `bson:"after" json:"after"`
+	Reason      string              `bson:"reason,omitempty" json:"reason,omitempty"`
+	OrderID     *primitive.ObjectID `bson:"orderId,omitempty" json:"orderId,omitempty"`
+	ReferenceID *primitive.ObjectID `bson:"referenceId,omitempty" json:"referenceId,omitempty"`
+	Actor       StockActor          `bson:"actor" json:"actor"`
+	CreatedAt   time.Time           `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Supplier struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	ContactName string             `bson:"contactName,omitempty" json:"contactName,omitempty"`
+	Phone       string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Email       string             `bson:"email,omitempty" json:"email,omitempty"`
+	Address     string             `bson:"address,omitempty" json:"address,omitempty"`
+	TaxNumber   string             `bson:"taxNumber,omitempty" json:"taxNumber,omitempty"`
+	Note        string             `bson:"note,omitempty" json:"note,omitempty"`
+	IsActive    bool               `bson:"isActive" json:"isActive"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   *time.Time         `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
@@ -15,15 +15,36 @@ type Address struct {
 	IsDefault bool   `bson:"isDefault" json:"isDefault"`
 }
 
-// User represents the application user account.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User represents the application user account. Customers and admins share
+// this model and are told apart by Role.
 type User struct {
 	ID           primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	Email        string               `bson:"email" json:"email"`
+	Email        string               `bson:"email,omitempty" json:"email"`
 	PasswordHash string               `bson:"passwordHash" json:"-"`
 	Name         string               `bson:"name" json:"name"`
 	Phone        string               `bson:"phone,omitempty" json:"phone,omitempty"`
+	Role         string               `bson:"role" json:"role"`
+	IsActive     bool                 `bson:"isActive" json:"isActive"`
 	Addresses    []Address            `bson:"addresses" json:"addresses"`
 	Favorites    []primitive.ObjectID `bson:"favorites,omitempty" json:"favorites,omitempty"`
 	CreatedAt    time.Time            `bson:"createdAt" json:"createdAt"`
 	UpdatedAt    time.Time            `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt    *time.Time           `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+
+	// PhoneVerified is set on the account a phone login signs into; only
+	// that account owns the number for login.
+	PhoneVerified bool `bson:"phoneVerified,omitempty" json:"phoneVerified,omitempty"`
+}
+
+// AccountRole returns the stored role, defaulting to a regular user.
+func (u User) AccountRole() string {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
 }
@@ -0,0 +1,261 @@
+// Package recommend computes "frequently bought together" products from
+// past orders: every pair of products in the same order counts towards both,
+// weighted by how recent the order is.
+package recommend
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// Collection holds one models.ProductRelations per product.
+const Collection = "product_relations"
+
+// TopN is how many related products are kept per product. It is more than
+// a page so that hiding out-of-stock products still leaves enough.
+const TopN = 20
+
+// halfLivesRead bounds the order history read: an order older than this
+// many half-lives weighs less than 0.4% and is skipped.
+const halfLivesRead = 8
+
+type Options struct {
+	// HalfLife is the age at which an order counts half as much as one
+	// placed now.
+	HalfLife time.Duration
+	DryRun   bool
+	Now      time.Time
+}
+
+// Report summarizes a run.
+type Report struct {
+	Orders   int  `json:"orders"`
+	Products int  `json:"products"`
+	Pairs    int  `json:"pairs"`
+	Stored   int  `json:"stored"`
+	Removed  int  `json:"removed"`
+	DryRun   bool `json:"dryRun"`
+}
+
+// basket is the distinct top-level products of one order.
+type basket struct {
+	Products  []primitive.ObjectID
+	CreatedAt time.Time
+}
+
+// Compute rebuilds the related products of every product from the orders of
+// the last halfLivesRead half-lives. Cancelled orders do not count, and
+// variants count as their parent product so a group is recommended once.
+// Products that no longer appear in any pair lose their relations.
+func Compute(ctx context.Context, db *mongo.Database, opts Options) (Report, error) {
+	report := Report{DryRun: opts.DryRun}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	// Mongo keeps milliseconds; the stale check below compares against the
+	// stored value.
+	opts.Now = opts.Now.Truncate(time.Millisecond)
+
+	parentOf, err := loadVariantParents(ctx, db)
+	if err != nil {
+		return report, err
+	}
+	baskets, err := loadBaskets(ctx, db, parentOf, opts.Now.Add(-halfLivesRead*opts.HalfLife))
+	if err != nil {
+		return report, err
+	}
+	report.Orders = len(baskets)
+
+	scores := pairScores(baskets, opts.Now, opts.HalfLife)
+	for _, related := range scores {
+		report.Pairs += len(related)
+	}
+	report.Pairs /= 2
+	top := topRelated(scores, TopN)
+	report.Products = len(top)
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	collection := db.Collection(Collection)
+	writes := make([]mongo.WriteModel, 0, len(top))
+	for productID, related := range top {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": productID}).
+			SetReplacement(models.ProductRelations{ProductID: productID, Related: related, UpdatedAt: opts.Now}).
+			SetUpsert(true))
+	}
+	for start := 0; start < len(writes); start += 500 {
+		end := min(start+500, len(writes))
+		if _, err := collection.BulkWrite(ctx, writes[start:end], options.BulkWrite().SetOrdered(false)); err != nil {
+			return report, err
+		}
+		report.Stored += end - start
+	}
+
+	res, err := collection.DeleteMany(ctx, bson.M{"updatedAt": bson.M{"$lt": opts.Now}})
+	if err != nil {
+		return report, err
+	}
+	report.Removed = int(res.DeletedCount)
+	return report, nil
+}
+
+// loadVariantParents maps every variant to the product heading its group.
+func loadVariantParents(ctx context.Context, db *mongo.Database) (map[primitive.ObjectID]primitive.ObjectID, error) {
+	cursor, err := db.Collection("products").Find(ctx,
+		bson.M{"parentId": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"parentId": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	parentOf := map[primitive.ObjectID]primitive.ObjectID{}
+	for cursor.Next(ctx) {
+		var row struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			ParentID primitive.ObjectID `bson:"parentId"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		parentOf[row.ID] = row.ParentID
+	}
+	return parentOf, cursor.Err()
+}
+
+// loadBaskets reads the orders placed since the given time that hold at
+// least two different products.
+func loadBaskets(ctx context.Context, db *mongo.Database, parentOf map[primitive.ObjectID]primitive.ObjectID, since time.Time) ([]basket, error) {
+	cursor, err := db.Collection("orders").Find(ctx,
+		bson.M{
+			"status":    bson.M{"$ne": "cancelled"},
+			"createdAt": bson.M{"$gte": since},
+			"items.1":   bson.M{"$exists": true},
+		},
+		options.Find().SetProjection(bson.M{"items.productId": 1, "createdAt": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var baskets []basket
+	for cursor.Next(ctx) {
+		var order models.Order
+		if err := cursor.Decode(&order); err != nil {
+			return nil, err
+		}
+		if b := basketOf(order, parentOf); len(b.Products) > 1 {
+			baskets = append(baskets, b)
+		}
+	}
+	return baskets, cursor.Err()
+}
+
+func basketOf(order models.Order, parentOf map[primitive.ObjectID]primitive.ObjectID) basket {
+	b := basket{CreatedAt: order.CreatedAt}
+	seen := map[primitive.ObjectID]bool{}
+	for _, item := range order.Items {
+		id := item.ProductID
+		if parent, ok := parentOf[id]; ok {
+			id = parent
+		}
+		if id.IsZero() || seen[id] {
+			continue
+		}
+		seen[id] = true
+		b.Products = append(b.Products, id)
+	}
+	return b
+}
+
+// recencyWeight halves every halfLife; orders dated in the future count
+// fully.
+func recencyWeight(age, halfLife time.Duration) float64 {
+	if age <= 0 || halfLife <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// pairScores adds each basket's weight to every ordered pair of its
+// products, so scores[a][b] == scores[b][a].
+func pairScores(baskets []basket, now time.Time, halfLife time.Duration) map[primitive.ObjectID]map[primitive.ObjectID]float64 {
+	scores := map[primitive.ObjectID]map[primitive.ObjectID]float64{}
+	for _, b := range baskets {
+		weight := recencyWeight(now.Sub(b.CreatedAt), halfLife)
+		for _, a := range b.Products {
+			for _, other := range b.Products {
+				if a == other {
+					continue
+				}
+				if scores[a] == nil {
+					scores[a] = map[primitive.ObjectID]float64{}
+				}
+				scores[a][other] += weight
+			}
+		}
+	}
+	return scores
+}
+
+// topRelated keeps the n best scored products for each product. Ties go to
+// the older product ID so runs are repeatable.
+func topRelated(scores map[primitive.ObjectID]map[primitive.ObjectID]float64, n int) map[primitive.ObjectID][]models.RelatedProduct {
+	top := make(map[primitive.ObjectID][]models.RelatedProduct, len(scores))
+	for productID, others := range scores {
+		related := make([]models.RelatedProduct, 0, len(others))
+		for id, score := range others {
+			related = append(related, models.RelatedProduct{ProductID: id, Score: math.Round(score*1000) / 1000})
+		}
+		sort.Slice(related, func(i, j int) bool {
+			if related[i].Score != related[j].Score {
+				return related[i].Score > related[j].Score
+			}
+			return related[i].ProductID.Hex() < related[j].ProductID.Hex()
+		})
+		if len(related) > n {
+			related = related[:n]
+		}
+		top[productID] = related
+	}
+	return top
+}
+
+// Run computes right away and then every interval until ctx is done.
+// Errors are logged; the next run tries again.
+func Run(ctx context.Context, db *mongo.Database, interval, halfLife time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+		report, err := Compute(runCtx, db, Options{HalfLife: halfLife})
+		cancel()
+		if err != nil {
+			log.Printf("[RECOMMEND] [ERROR] run failed: %v", err)
+		} else {
+			log.Printf("[RECOMMEND] orders=%d products=%d pairs=%d stored=%d removed=%d",
+				report.Orders, report.Products, report.Pairs, report.Stored, report.Removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
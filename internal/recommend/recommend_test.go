@@ -0,0 +1,57 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/internal/models"
+)
+
+func TestBasketOfCollapsesVariantsAndDuplicates(t *testing.T) {
+	parent, variant, other := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	order := models.Order{Items: []models.OrderItem{
+		{ProductID: parent},
+		{ProductID: variant},
+		{ProductID: other},
+		{ProductID: other},
+	}}
+
+	b := basketOf(order, map[primitive.ObjectID]primitive.ObjectID{variant: parent})
+	if len(b.Products) != 2 || b.Products[0] != parent || b.Products[1] != other {
+		t.Fatalf("expected [parent other], got %v", b.Products)
+	}
+}
+
+func TestPairScoresWeighRecentOrdersMore(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 30 * 24 * time.Hour
+	bread, cheese, olives := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	scores := pairScores([]basket{
+		{Products: []primitive.ObjectID{bread, cheese}, CreatedAt: now},
+		{Products: []primitive.ObjectID{bread, olives}, CreatedAt: now.Add(-halfLife)},
+		{Products: []primitive.ObjectID{bread, olives}, CreatedAt: now.Add(-halfLife)},
+		{Products: []primitive.ObjectID{bread, olives, cheese}, CreatedAt: now.Add(-2 * halfLife)},
+	}, now, halfLife)
+
+	if got := scores[bread][cheese]; math.Abs(got-1.25) > 1e-9 {
+		t.Fatalf("bread+cheese: expected 1.25, got %v", got)
+	}
+	if got := scores[bread][olives]; math.Abs(got-1.25) > 1e-9 {
+		t.Fatalf("bread+olives: expected 1.25, got %v", got)
+	}
+	if scores[cheese][bread] != scores[bread][cheese] {
+		t.Fatalf("scores should be symmetric")
+	}
+
+	top := topRelated(scores, 1)
+	if len(top[bread]) != 1 {
+		t.Fatalf("expected one related product for bread, got %v", top[bread])
+	}
+	if top[olives][0].ProductID != bread {
+		t.Fatalf("olives should relate to bread first, got %v", top[olives])
+	}
+}
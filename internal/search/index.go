@@ -0,0 +1,287 @@
+// Package search is an in-process product search index. It is rebuilt from
+// Mongo in the background and answers ranked, typo-tolerant queries without
+// a database round trip; handlers then load the matching products by ID.
+package search
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Document is the searchable part of a product.
+type Document struct {
+	ID          string
+	Name        string
+	Brand       string
+	Description string
+	Barcode     string
+	Categories  []string
+	// Active is false for inactive products; TopLevel is false for variants.
+	Active   bool
+	TopLevel bool
+}
+
+type field uint8
+
+const (
+	fieldName field = iota
+	fieldBrand
+	fieldDescription
+)
+
+// fieldWeight ranks a name match above brand above description.
+var fieldWeight = [...]float64{fieldName: 3, fieldBrand: 2, fieldDescription: 1}
+
+// Match quality multipliers.
+const (
+	scoreExact       = 1.0
+	scorePrefix      = 0.7
+	scoreTypo        = 0.5
+	scoreTypoPrefix  = 0.35
+	scoreBarcode     = 10
+	minTypoLength    = 4
+	minBarcodePrefix = 4
+)
+
+type posting struct {
+	doc   int
+	field field
+}
+
+type snapshot struct {
+	docs     []Document
+	terms    []string // sorted, for prefix lookups
+	postings map[string][]posting
+	barcodes []int // doc indexes sorted by barcode
+	labels   []label
+	builtAt  time.Time
+}
+
+// Hit is a ranked search result.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Loader returns every product that should be searchable.
+type Loader func(ctx context.Context) ([]Document, error)
+
+// Index is safe for concurrent use. Searches read an immutable snapshot that
+// rebuilds swap atomically.
+type Index struct {
+	load       Loader
+	snap       atomic.Pointer[snapshot]
+	invalidate chan struct{}
+}
+
+func NewIndex(load Loader) *Index {
+	return &Index{load: load, invalidate: make(chan struct{}, 1)}
+}
+
+// Ready reports whether the index has been built at least once.
+func (idx *Index) Ready() bool {
+	return idx != nil && idx.snap.Load() != nil
+}
+
+// Build replaces the index with docs.
+func (idx *Index) Build(docs []Document) {
+	snap := &snapshot{docs: docs, postings: map[string][]posting{}, builtAt: time.Now()}
+	for i, doc := range docs {
+		for f, text := range [...]string{fieldName: doc.Name, fieldBrand: doc.Brand, fieldDescription: doc.Description} {
+			seen := map[string]bool{}
+			for _, token := range Tokens(text) {
+				if seen[token] {
+					continue
+				}
+				seen[token] = true
+				snap.postings[token] = append(snap.postings[token], posting{doc: i, field: field(f)})
+			}
+		}
+		if doc.Barcode != "" {
+			snap.barcodes = append(snap.barcodes, i)
+		}
+	}
+	snap.terms = make([]string, 0, len(snap.postings))
+	for term := range snap.postings {
+		snap.terms = append(snap.terms, term)
+	}
+	sort.Strings(snap.terms)
+	sort.Slice(snap.barcodes, func(a, b int) bool {
+		return docs[snap.barcodes[a]].Barcode < docs[snap.barcodes[b]].Barcode
+	})
+	snap.labels = buildLabels(docs)
+	idx.snap.Store(snap)
+}
+
+// Rebuild reloads every document through the loader.
+func (idx *Index) Rebuild(ctx context.Context) error {
+	docs, err := idx.load(ctx)
+	if err != nil {
+		return err
+	}
+	idx.Build(docs)
+	return nil
+}
+
+// Invalidate asks the background loop to rebuild soon. It never blocks and
+// is a no-op on a nil index.
+func (idx *Index) Invalidate() {
+	if idx == nil {
+		return
+	}
+	select {
+	case idx.invalidate <- struct{}{}:
+	default:
+	}
+}
+
+// Run rebuilds on every interval and after Invalidate until ctx is done.
+func (idx *Index) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-idx.invalidate:
+		}
+		buildCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		started := time.Now()
+		err := idx.Rebuild(buildCtx)
+		cancel()
+		if err != nil {
+			log.Printf("[SEARCH] [ERROR] rebuild failed: %v", err)
+			continue
+		}
+		log.Printf("[SEARCH] index rebuilt docs=%d took=%s", len(idx.snap.Load().docs), time.Since(started))
+	}
+}
+
+// Search returns the documents matching every word of query, best first.
+// Each word may match a whole term, the start of a term, or a term one typo
+// away. Documents are passed through keep (nil keeps all) before ranking.
+func (idx *Index) Search(query string, keep func(Document) bool) []Hit {
+	if !idx.Ready() {
+		return nil
+	}
+	snap := idx.snap.Load()
+	ranked, scores := snap.rank(query, keep)
+	hits := make([]Hit, len(ranked))
+	for n, i := range ranked {
+		hits[n] = Hit{ID: snap.docs[i].ID, Score: scores[i]}
+	}
+	return hits
+}
+
+// rank returns the indexes of the matching documents, best first, and their
+// scores.
+func (s *snapshot) rank(query string, keep func(Document) bool) ([]int, map[int]float64) {
+	scores := map[int]float64{}
+	for _, i := range s.matchBarcode(query) {
+		scores[i] = scoreBarcode
+	}
+
+	tokens := Tokens(query)
+	if len(tokens) > 0 {
+		var textScores map[int]float64
+		for n, token := range tokens {
+			tokenScores := s.matchToken(token)
+			if n == 0 {
+				textScores = tokenScores
+				continue
+			}
+			for doc, score := range textScores {
+				if extra, ok := tokenScores[doc]; ok {
+					textScores[doc] = score + extra
+				} else {
+					delete(textScores, doc)
+				}
+			}
+		}
+		for doc, score := range textScores {
+			if score > scores[doc] {
+				scores[doc] = score
+			}
+		}
+	}
+
+	ranked := make([]int, 0, len(scores))
+	for i := range scores {
+		if keep == nil || keep(s.docs[i]) {
+			ranked = append(ranked, i)
+		}
+	}
+	sort.Slice(ranked, func(a, b int) bool {
+		if scores[ranked[a]] != scores[ranked[b]] {
+			return scores[ranked[a]] > scores[ranked[b]]
+		}
+		return s.docs[ranked[a]].Name < s.docs[ranked[b]].Name
+	})
+	return ranked, scores
+}
+
+// matchToken scores every document containing token, keeping the best
+// field/quality combination per document.
+func (s *snapshot) matchToken(token string) map[int]float64 {
+	scores := map[int]float64{}
+	add := func(term string, quality float64) {
+		for _, p := range s.postings[term] {
+			if score := fieldWeight[p.field] * quality; score > scores[p.doc] {
+				scores[p.doc] = score
+			}
+		}
+	}
+
+	add(token, scoreExact)
+	start := sort.SearchStrings(s.terms, token)
+	for i := start; i < len(s.terms) && strings.HasPrefix(s.terms[i], token); i++ {
+		if s.terms[i] != token {
+			add(s.terms[i], scorePrefix)
+		}
+	}
+
+	if len([]rune(token)) < minTypoLength {
+		return scores
+	}
+	length := len([]rune(token))
+	for _, term := range s.terms {
+		if strings.HasPrefix(term, token) {
+			continue
+		}
+		runes := []rune(term)
+		switch {
+		case len(runes) <= length+1 && withinOneEdit(token, term):
+			add(term, scoreTypo)
+		case len(runes) > length && withinOneEdit(token, string(runes[:length])):
+			add(term, scoreTypoPrefix)
+		}
+	}
+	return scores
+}
+
+// matchBarcode finds documents whose barcode equals the query or, for
+// longer digit strings, starts with it.
+func (s *snapshot) matchBarcode(query string) []int {
+	query = strings.TrimSpace(query)
+	if query == "" || strings.IndexFunc(query, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+		return nil
+	}
+	start := sort.Search(len(s.barcodes), func(i int) bool {
+		return s.docs[s.barcodes[i]].Barcode >= query
+	})
+	var matches []int
+	for i := start; i < len(s.barcodes); i++ {
+		barcode := s.docs[s.barcodes[i]].Barcode
+		if barcode != query && (len(query) < minBarcodePrefix || !strings.HasPrefix(barcode, query)) {
+			break
+		}
+		matches = append(matches, s.barcodes[i])
+	}
+	return matches
+}
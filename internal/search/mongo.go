@@ -0,0 +1,58 @@
+package search
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+)
+
+// MongoLoader reads every product that is not deleted. Inactive products
+// and variants are indexed too and flagged, so callers decide what to show.
+func MongoLoader(db *mongo.Database) Loader {
+	return func(ctx context.Context) ([]Document, error) {
+		cursor, err := db.Collection("products").Find(ctx,
+			bson.M{"isDeleted": bson.M{"$ne": true}},
+			options.Find().SetProjection(bson.M{
+				"name": 1, "brand": 1, "description": 1, "barcode": 1, "category": 1, "isActive": 1, "parentId": 1,
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		var docs []Document
+		for cursor.Next(ctx) {
+			var row struct {
+				ID          primitive.ObjectID  `bson:"_id"`
+				Name        string              `bson:"name"`
+				Brand       string              `bson:"brand"`
+				Description string              `bson:"description"`
+				Barcode     string              `bson:"barcode"`
+				Category    models.StringList   `bson:"category"`
+				IsActive    *bool               `bson:"isActive"`
+				ParentID    *primitive.ObjectID `bson:"parentId"`
+			}
+			if err := cursor.Decode(&row); err != nil {
+				return nil, err
+			}
+			docs = append(docs, Document{
+				ID:          row.ID.Hex(),
+				Name:        row.Name,
+				Brand:       row.Brand,
+				Description: row.Description,
+				Barcode:     row.Barcode,
+				Categories:  row.Category,
+				// GetProducts treats a missing isActive as active.
+				Active:   row.IsActive == nil || *row.IsActive,
+				TopLevel: row.ParentID == nil,
+			})
+		}
+		return docs, cursor.Err()
+	}
+}
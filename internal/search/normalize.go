@@ -0,0 +1,110 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// turkishFold maps Turkish letters to their ASCII base so "SÜT", "süt" and
+// "sut" all become "sut". I and İ are handled here because the generic
+// unicode lower-casing gets them wrong for Turkish ("I" → "i", "İ" → "i̇").
+var turkishFold = map[rune]rune{
+	'I': 'i', 'İ': 'i', 'ı': 'i', 'î': 'i', 'Î': 'i',
+	'Ç': 'c', 'ç': 'c',
+	'Ğ': 'g', 'ğ': 'g',
+	'Ö': 'o', 'ö': 'o',
+	'Ş': 's', 'ş': 's',
+	'Ü': 'u', 'ü': 'u', 'û': 'u', 'Û': 'u',
+	'Â': 'a', 'â': 'a',
+}
+
+// Normalize lower-cases and folds text for matching. Everything that is not
+// a letter or digit becomes a space.
+func Normalize(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if folded, ok := turkishFold[r]; ok {
+			b.WriteRune(folded)
+			continue
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Mn, r):
+			// combining marks, e.g. the dot of a decomposed İ
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// Tokens splits normalized text into words.
+func Tokens(text string) []string {
+	return strings.Fields(Normalize(text))
+}
+
+// Key is a name's matching key, the same regardless of case, Turkish letters
+// and punctuation: "SÜT ÜRÜNLERİ" and "Süt Ürünleri" share one.
+func Key(text string) string {
+	return strings.Join(Tokens(text), " ")
+}
+
+// Slug turns text into a lower-case ASCII slug for URLs with the same
+// folding: "Süt & Kahvaltılık" becomes "sut-kahvaltilik". Letters without
+// an ASCII base are dropped.
+func Slug(text string) string {
+	words := make([]string, 0, 4)
+	for _, token := range Tokens(text) {
+		word := strings.Map(func(r rune) rune {
+			if r < utf8.RuneSelf {
+				return r
+			}
+			return -1
+		}, token)
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, "-")
+}
+
+// UniqueSlug returns base, or base-2, base-3... whichever taken reports as
+// free first.
+func UniqueSlug(base string, taken func(string) bool) string {
+	slug := base
+	for n := 2; taken(slug); n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug
+}
+
+// withinOneEdit reports whether a and b differ by at most one insertion,
+// deletion, substitution or transposition of adjacent characters.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+
+	i := 0
+	for i < len(ra) && ra[i] == rb[i] {
+		i++
+	}
+	if i == len(ra) {
+		return true
+	}
+	if len(ra) == len(rb) {
+		if string(ra[i+1:]) == string(rb[i+1:]) {
+			return true
+		}
+		return i+1 < len(ra) && ra[i] == rb[i+1] && ra[i+1] == rb[i] && string(ra[i+2:]) == string(rb[i+2:])
+	}
+	return string(ra[i:]) == string(rb[i+1:])
+}
@@ -0,0 +1,159 @@
+package search
+
+import (
+	"strconv"
+	"testing"
+)
+
+func testIndex() *Index {
+	idx := NewIndex(nil)
+	idx.Build([]Document{
+		{ID: "milk", Name: "Tam Yağlı Süt 1 L", Brand: "Pınar", Description: "Günlük taze", Barcode: "8690000000011", Active: true, TopLevel: true},
+		{ID: "choc", Name: "Sütlü Çikolata", Brand: "Ülker", Description: "Fındıklı", Barcode: "8690000000028", Active: true, TopLevel: true},
+		{ID: "cheese", Name: "Beyaz Peynir", Brand: "Sütaş", Description: "İnek sütünden", Active: true, TopLevel: true},
+		{ID: "cake", Name: "Kek", Brand: "Eti", Description: "Süt kreması ile", Active: false, TopLevel: true},
+	})
+	return idx
+}
+
+func ids(hits []Hit) []string {
+	out := make([]string, len(hits))
+	for i, hit := range hits {
+		out[i] = hit.ID
+	}
+	return out
+}
+
+func TestNormalizeFoldsTurkishCasing(t *testing.T) {
+	cases := map[string]string{
+		"İSTANBUL":   "istanbul",
+		"IĞDIR":      "igdir",
+		"Şeker-Çay!": "seker cay ",
+		"ÜZÜM ı":     "uzum i",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	cases := map[string]string{
+		"Süt Ürünleri":       "sut-urunleri",
+		"İÇECEK & Gazlı":     "icecek-gazli",
+		"  Meyve / Sebze  ":  "meyve-sebze",
+		"Ağız ve Diş Bakımı": "agiz-ve-dis-bakimi",
+	}
+	for in, want := range cases {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	taken := map[string]bool{"sut": true, "sut-2": true}
+	if got := UniqueSlug("sut", func(s string) bool { return taken[s] }); got != "sut-3" {
+		t.Errorf("UniqueSlug = %q, want sut-3", got)
+	}
+}
+
+func TestSearchRanksNameOverBrandOverDescription(t *testing.T) {
+	got := ids(testIndex().Search("süt", nil))
+	want := []string{"milk", "choc", "cheese", "cake"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSearchPrefixTypoAndFilters(t *testing.T) {
+	idx := testIndex()
+
+	if got := ids(idx.Search("cikol", nil)); len(got) != 1 || got[0] != "choc" {
+		t.Fatalf("prefix search: %v", got)
+	}
+	if got := ids(idx.Search("peynır", nil)); len(got) != 1 || got[0] != "cheese" {
+		t.Fatalf("dotless i should fold: %v", got)
+	}
+	if got := ids(idx.Search("cikolta", nil)); len(got) != 1 || got[0] != "choc" {
+		t.Fatalf("one typo should match: %v", got)
+	}
+	if got := ids(idx.Search("cklta", nil)); len(got) != 0 {
+		t.Fatalf("two typos must not match: %v", got)
+	}
+	if got := ids(idx.Search("tam sut", nil)); len(got) != 1 || got[0] != "milk" {
+		t.Fatalf("every word must match: %v", got)
+	}
+	if got := ids(idx.Search("8690000000028", nil)); len(got) != 1 || got[0] != "choc" {
+		t.Fatalf("barcode: %v", got)
+	}
+	active := func(doc Document) bool { return doc.Active }
+	for _, id := range ids(idx.Search("süt", active)) {
+		if id == "cake" {
+			t.Fatal("inactive product must be filtered out")
+		}
+	}
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	for _, pair := range [][2]string{{"kahve", "kahve"}, {"kahve", "kahv"}, {"kahve", "kahvee"}, {"kahve", "kahfe"}, {"kahve", "khave"}} {
+		if !withinOneEdit(pair[0], pair[1]) {
+			t.Errorf("%q vs %q should be within one edit", pair[0], pair[1])
+		}
+	}
+	for _, pair := range [][2]string{{"kahve", "kah"}, {"kahve", "khaev"}, {"kahve", "cay"}} {
+		if withinOneEdit(pair[0], pair[1]) {
+			t.Errorf("%q vs %q should not be within one edit", pair[0], pair[1])
+		}
+	}
+}
+
+func TestSuggestReturnsListedProductsBrandsAndCategories(t *testing.T) {
+	idx := NewIndex(nil)
+	idx.Build([]Document{
+		{ID: "1", Name: "Süt 1 L", Brand: "Sütaş", Categories: []string{"Süt Ürünleri"}, Active: true, TopLevel: true},
+		{ID: "2", Name: "Ayran", Brand: "SÜTAŞ", Categories: []string{"Süt Ürünleri", "İçecek"}, Active: true, TopLevel: true},
+		{ID: "3", Name: "Sütlaç", Brand: "Eti", Categories: []string{"Tatlı"}, Active: false, TopLevel: true},
+		{ID: "4", Name: "Süt 500 ml", Brand: "Sütaş", Categories: []string{"Süt Ürünleri"}, Active: true, TopLevel: false},
+	})
+
+	got := idx.Suggest("sut", 5)
+	// name match first, then the brand match; inactive and variant excluded
+	if len(got.Products) != 2 || got.Products[0].ID != "1" || got.Products[1].ID != "2" {
+		t.Fatalf("expected the listed products, got %+v", got.Products)
+	}
+	if len(got.Brands) != 1 || got.Brands[0].Count != 2 {
+		t.Fatalf("expected Sütaş once with two products, got %+v", got.Brands)
+	}
+	if len(got.Categories) != 1 || got.Categories[0].Name != "Süt Ürünleri" {
+		t.Fatalf("unexpected categories %+v", got.Categories)
+	}
+	if got := idx.Suggest("ice", 5); len(got.Categories) != 1 || got.Categories[0].Name != "İçecek" {
+		t.Fatalf("Turkish casing in categories: %+v", got.Categories)
+	}
+}
+
+func BenchmarkSuggest(b *testing.B) {
+	words := []string{"süt", "peynir", "çikolata", "ekmek", "zeytin", "yağ", "şeker", "çay", "kahve", "makarna"}
+	docs := make([]Document, 0, 20000)
+	for i := 0; i < 20000; i++ {
+		docs = append(docs, Document{
+			ID:         strconv.Itoa(i),
+			Name:       words[i%len(words)] + " " + words[(i/7)%len(words)] + " " + strconv.Itoa(i),
+			Brand:      "marka" + strconv.Itoa(i%300),
+			Categories: []string{"kategori" + strconv.Itoa(i%80)},
+			Active:     true,
+			TopLevel:   true,
+		})
+	}
+	idx := NewIndex(nil)
+	idx.Build(docs)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Suggest("cikol", 8)
+	}
+}
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+type labelKind uint8
+
+const (
+	labelBrand labelKind = iota
+	labelCategory
+)
+
+// label is a brand or category name with the number of visible products
+// carrying it.
+type label struct {
+	kind   labelKind
+	name   string
+	tokens []string
+	count  int
+}
+
+// Suggestion is a brand or category offered for a prefix.
+type Suggestion struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ProductSuggestion is a product name offered for a prefix.
+type ProductSuggestion struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Brand string `json:"brand,omitempty"`
+}
+
+type Suggestions struct {
+	Products   []ProductSuggestion `json:"products"`
+	Brands     []Suggestion        `json:"brands"`
+	Categories []Suggestion        `json:"categories"`
+}
+
+// Listed is the filter GetProducts applies: active, top-level products.
+func Listed(doc Document) bool {
+	return doc.Active && doc.TopLevel
+}
+
+// buildLabels collects the brands and categories of listed products,
+// merging spellings that normalize to the same text.
+func buildLabels(docs []Document) []label {
+	byKey := map[string]*label{}
+	var order []string
+	add := func(kind labelKind, name string) {
+		name = strings.TrimSpace(name)
+		tokens := Tokens(name)
+		if len(tokens) == 0 {
+			return
+		}
+		key := string(rune('0'+kind)) + strings.Join(tokens, " ")
+		if existing, ok := byKey[key]; ok {
+			existing.count++
+			return
+		}
+		byKey[key] = &label{kind: kind, name: name, tokens: tokens, count: 1}
+		order = append(order, key)
+	}
+	for _, doc := range docs {
+		if !Listed(doc) {
+			continue
+		}
+		add(labelBrand, doc.Brand)
+		for _, category := range doc.Categories {
+			add(labelCategory, category)
+		}
+	}
+
+	labels := make([]label, 0, len(order))
+	for _, key := range order {
+		labels = append(labels, *byKey[key])
+	}
+	return labels
+}
+
+// Suggest returns up to limit product names, brands and categories for the
+// text typed so far. Every word must start a word of the name; products also
+// get the search's typo tolerance.
+func (idx *Index) Suggest(query string, limit int) Suggestions {
+	out := Suggestions{Products: []ProductSuggestion{}, Brands: []Suggestion{}, Categories: []Suggestion{}}
+	tokens := Tokens(query)
+	if !idx.Ready() || len(tokens) == 0 || limit <= 0 {
+		return out
+	}
+	snap := idx.snap.Load()
+
+	ranked, _ := snap.rank(query, Listed)
+	for _, i := range ranked {
+		if len(out.Products) == limit {
+			break
+		}
+		doc := snap.docs[i]
+		out.Products = append(out.Products, ProductSuggestion{ID: doc.ID, Name: doc.Name, Brand: doc.Brand})
+	}
+
+	var brands, categories []Suggestion
+	for _, l := range snap.labels {
+		if !prefixesMatch(tokens, l.tokens) {
+			continue
+		}
+		suggestion := Suggestion{Name: l.name, Count: l.count}
+		if l.kind == labelBrand {
+			brands = append(brands, suggestion)
+		} else {
+			categories = append(categories, suggestion)
+		}
+	}
+	out.Brands = topSuggestions(brands, limit)
+	out.Categories = topSuggestions(categories, limit)
+	return out
+}
+
+// prefixesMatch reports whether every query word starts some word of the
+// label.
+func prefixesMatch(query, words []string) bool {
+	for _, q := range query {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func topSuggestions(items []Suggestion, limit int) []Suggestion {
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].Count != items[b].Count {
+			return items[a].Count > items[b].Count
+		}
+		return items[a].Name < items[b].Name
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	if items == nil {
+		return []Suggestion{}
+	}
+	return items
+}
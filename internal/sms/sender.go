@@ -0,0 +1,51 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Sender delivers a text message to an E.164 phone number.
+type Sender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// LogSender writes messages to the application log instead of sending them,
+// for local development. Digits are masked so login codes never reach the
+// log.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, phone, message string) error {
+	log.Printf("[SMS] [INFO] to=%s message=%q", phone, maskDigits(message))
+	return nil
+}
+
+func maskDigits(message string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '*'
+		}
+		return r
+	}, message)
+}
+
+// NewSender returns the sender configured by SMS_PROVIDER. The log sender
+// must be chosen explicitly, except in development where it is the default,
+// so a deploy that forgets the variable fails to start instead of sending
+// no SMS.
+func NewSender(provider string, development bool) (Sender, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "":
+		if development {
+			return LogSender{}, nil
+		}
+		return nil, errors.New("SMS_PROVIDER is not set")
+	case "log":
+		return LogSender{}, nil
+	default:
+		return nil, fmt.Errorf("unknown sms provider %q", provider)
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local keeps uploads on the filesystem below Root, which the HTTP server
+// serves at URLPrefix.
+type Local struct {
+	Root      string
+	URLPrefix string
+}
+
+func NewLocal(root, urlPrefix string) *Local {
+	if root == "" {
+		root = "/app/public"
+	}
+	if urlPrefix == "" {
+		urlPrefix = "/public"
+	}
+	return &Local{Root: filepath.Clean(root), URLPrefix: strings.TrimRight(urlPrefix, "/")}
+}
+
+// path resolves a key to a file and refuses anything outside Root.
+func (l *Local) path(key string) (string, error) {
+	clean, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Clean(filepath.Join(l.Root, filepath.FromSlash(clean)))
+	if !strings.HasPrefix(target, l.Root+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidKey, key)
+	}
+	return target, nil
+}
+
+func (l *Local) Put(_ context.Context, key string, data []byte, _ string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0o644)
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) Exists(_ context.Context, key string) (bool, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(target)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (l *Local) URL(key string) string {
+	clean, err := CleanKey(key)
+	if err != nil {
+		return ""
+	}
+	return l.URLPrefix + "/" + clean
+}
+
+func (l *Local) List(_ context.Context, prefix string) ([]Object, error) {
+	base := filepath.Join(l.Root, filepath.FromSlash(strings.TrimPrefix(prefix, "/")))
+	// A prefix may end in the middle of a name; walk its directory.
+	dir := base
+	if !strings.HasSuffix(prefix, "/") {
+		dir = filepath.Dir(base)
+	}
+
+	var objects []Object
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && file == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.Root, file)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, strings.TrimPrefix(prefix, "/")) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
+
+// Read returns a stored file; the upload migration uses it to copy local
+// files to another backend.
+func (l *Local) Read(key string) ([]byte, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(target)
+}
@@ -0,0 +1,71 @@
+// Package storage keeps uploaded files. Files are addressed by keys such as
+// "uploads/products/<id>-medium.webp"; the same key is stored in the database
+// whichever backend holds the bytes.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Storage is where uploads live.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes the file; a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// URL is where clients can download the file.
+	URL(key string) string
+	// List returns every file whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Object is a stored file as returned by List.
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// ErrInvalidKey rejects keys outside the uploads area.
+var ErrInvalidKey = errors.New("invalid upload key")
+
+// CleanKey normalizes a stored path to a key under "uploads/". Older records
+// may carry a leading slash or the "public/" prefix of the local root.
+func CleanKey(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", ErrInvalidKey
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(trimmed, "/")), "/")
+	cleaned = strings.TrimPrefix(cleaned, "public/")
+	if !strings.HasPrefix(cleaned, "uploads/") || strings.Contains(cleaned, "..") {
+		return "", fmt.Errorf("%w: %s", ErrInvalidKey, raw)
+	}
+	return cleaned, nil
+}
+
+// Config selects and configures the backend (STORAGE_BACKEND and friends).
+type Config struct {
+	Backend   string
+	LocalRoot string
+	// LocalURLPrefix is where the local root is served, e.g. "/public".
+	LocalURLPrefix string
+	S3             S3Config
+}
+
+// New returns the storage configured by STORAGE_BACKEND.
+func New(cfg Config) (Storage, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case "", "local":
+		return NewLocal(cfg.LocalRoot, cfg.LocalURLPrefix), nil
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
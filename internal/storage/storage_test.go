@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 stands in for MinIO: a path-style bucket that checks every
+// request's SigV4 signature the way the server would, from what arrived.
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	access  string
+	secret  string
+	region  string
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if !f.verify(r, body) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r.URL.Query())
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodHead:
+		if _, ok := f.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list answers ListObjectsV2 two keys per page.
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	start, _ := strconv.Atoi(query.Get("continuation-token"))
+	end := start + 2
+	if end > len(keys) {
+		end = len(keys)
+	}
+	io.WriteString(w, "<ListBucketResult>")
+	for _, key := range keys[start:end] {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>2026-01-02T03:04:05.000Z</LastModified></Contents>", key, len(f.objects[key]))
+	}
+	if end < len(keys) {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", end)
+	}
+	io.WriteString(w, "</ListBucketResult>")
+}
+
+func (f *fakeS3) verify(r *http.Request, body []byte) bool {
+	auth := r.Header.Get("Authorization")
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+		if name, value, ok := strings.Cut(part, "="); ok {
+			fields[name] = value
+		}
+	}
+	credential := strings.SplitN(fields["Credential"], "/", 2)
+	if len(credential) != 2 || credential[0] != f.access {
+		return false
+	}
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		return false
+	}
+
+	signed := strings.Split(fields["SignedHeaders"], ";")
+	sort.Strings(signed)
+	var canonical strings.Builder
+	for _, name := range signed {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonical.WriteString(name + ":" + value + "\n")
+	}
+	request := strings.Join([]string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonical.String(), strings.Join(signed, ";"), hex.EncodeToString(sum[:])}, "\n")
+	requestHash := sha256.Sum256([]byte(request))
+
+	scope := credential[1]
+	day := strings.SplitN(scope, "/", 2)[0]
+	toSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	key := mac(mac(mac(mac([]byte("AWS4"+f.secret), day), f.region), "s3"), "aws4_request")
+	return hmac.Equal([]byte(fields["Signature"]), []byte(hex.EncodeToString(mac(key, toSign))))
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{
+		t: t, bucket: "products", access: "minio", secret: "minio-secret", region: "us-east-1",
+		objects: map[string][]byte{}, types: map[string]string{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func TestS3PutExistsDeleteAgainstFakeServer(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store, err := New(Config{Backend: "s3", S3: S3Config{
+		Endpoint: server.URL, Bucket: "products", AccessKey: "minio", SecretKey: "minio-secret",
+		PublicURL: "https://cdn.example.com/", PathStyle: true,
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	key := "uploads/products/abc-medium.webp"
+
+	if err := store.Put(ctx, "/"+key, []byte("webp-bytes"), "image/webp"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if string(fake.objects[key]) != "webp-bytes" || fake.types[key] != "image/webp" {
+		t.Fatalf("object not stored as expected: %q %q", fake.objects[key], fake.types[key])
+	}
+	if ok, err := store.Exists(ctx, key); err != nil || !ok {
+		t.Fatalf("Exists after put = %v, %v", ok, err)
+	}
+	if got := store.URL(key); got != "https://cdn.example.com/"+key {
+		t.Fatalf("URL = %q", got)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ok, err := store.Exists(ctx, key); err != nil || ok {
+		t.Fatalf("Exists after delete = %v, %v", ok, err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("deleting a missing object must succeed: %v", err)
+	}
+}
+
+func TestS3ListPagesThroughPrefix(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "products", AccessKey: "minio", SecretKey: "minio-secret", PathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	for _, key := range []string{"uploads/products/a.jpg", "uploads/products/b.jpg", "uploads/products/c.jpg", "uploads/other/d.jpg"} {
+		fake.objects[key] = []byte("x")
+	}
+
+	objects, err := store.List(context.Background(), "uploads/products/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 3 || objects[2].Key != "uploads/products/c.jpg" || objects[0].ModTime.IsZero() {
+		t.Fatalf("unexpected listing: %+v", objects)
+	}
+}
+
+func TestS3RejectedSignatureIsAnError(t *testing.T) {
+	_, server := newFakeS3(t)
+	store, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "products", AccessKey: "minio", SecretKey: "wrong", PathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	store.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	if err := store.Put(context.Background(), "uploads/a.jpg", []byte("x"), "image/jpeg"); err == nil {
+		t.Fatal("expected a 403 to surface as an error")
+	}
+}
+
+func TestLocalStaysInsideRoot(t *testing.T) {
+	store := NewLocal(t.TempDir(), "/public")
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "uploads/products/a.jpg", []byte("jpg"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if data, err := store.Read("public/uploads/products/a.jpg"); err != nil || string(data) != "jpg" {
+		t.Fatalf("Read = %q, %v", data, err)
+	}
+	if got := store.URL("/uploads/products/a.jpg"); got != "/public/uploads/products/a.jpg" {
+		t.Fatalf("URL = %q", got)
+	}
+	for _, key := range []string{"../etc/passwd", "uploads/../../secret", "config.json", ""} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err == nil {
+			t.Fatalf("expected %q to be rejected", key)
+		}
+	}
+}
+
+func TestLocalListWalksPrefix(t *testing.T) {
+	store := NewLocal(t.TempDir(), "/public")
+	ctx := context.Background()
+	for _, key := range []string{"uploads/products/a.jpg", "uploads/products/sub/b.jpg", "uploads/other.jpg"} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	objects, err := store.List(ctx, "uploads/products/")
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("List = %+v, %v", objects, err)
+	}
+	if objects, err := store.List(ctx, "uploads/missing/"); err != nil || len(objects) != 0 {
+		t.Fatalf("missing prefix = %+v, %v", objects, err)
+	}
+}
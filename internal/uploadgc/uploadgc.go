@@ -0,0 +1,198 @@
+// Package uploadgc removes uploaded files that no product or category
+// references any more, e.g. renditions left behind by a failed upload or a
+// replaced image.
+package uploadgc
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// Prefix covers every upload: product and category images and files from
+// before uploads were split into folders.
+const Prefix = "uploads/"
+
+type Options struct {
+	// GracePeriod protects files written moments ago whose product has not
+	// been saved yet.
+	GracePeriod time.Duration
+	DryRun      bool
+	Now         time.Time
+}
+
+// Report summarizes a collection run.
+type Report struct {
+	Scanned    int      `json:"scanned"`
+	Referenced int      `json:"referenced"`
+	TooRecent  int      `json:"tooRecent"`
+	Orphans    int      `json:"orphans"`
+	Deleted    int      `json:"deleted"`
+	FreedBytes int64    `json:"freedBytes"`
+	Files      []string `json:"files,omitempty"`
+	Failed     []string `json:"failed,omitempty"`
+	DryRun     bool     `json:"dryRun"`
+}
+
+// Collect lists the upload files and deletes those no product or category
+// refers to and that are older than the grace period. Soft-deleted products count as
+// references since they can still be restored.
+func Collect(ctx context.Context, db *mongo.Database, uploads storage.Storage, opts Options) (Report, error) {
+	report := Report{DryRun: opts.DryRun}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	// Listing before reading references means a file uploaded in between is
+	// either not listed or protected by the grace period.
+	objects, err := uploads.List(ctx, Prefix)
+	if err != nil {
+		return report, err
+	}
+	referenced, err := referencedKeys(ctx, db)
+	if err != nil {
+		return report, err
+	}
+
+	orphans, recent := selectOrphans(objects, referenced, opts.Now.Add(-opts.GracePeriod))
+	report.Scanned = len(objects)
+	report.TooRecent = recent
+	report.Orphans = len(orphans)
+	report.Referenced = report.Scanned - report.Orphans - recent
+
+	for _, object := range orphans {
+		report.Files = append(report.Files, object.Key)
+		if opts.DryRun {
+			report.FreedBytes += object.Size
+			continue
+		}
+		if err := uploads.Delete(ctx, object.Key); err != nil {
+			log.Printf("[UPLOAD-GC] [ERROR] delete %s: %v", object.Key, err)
+			report.Failed = append(report.Failed, object.Key)
+			continue
+		}
+		report.Deleted++
+		report.FreedBytes += object.Size
+	}
+	return report, nil
+}
+
+// selectOrphans returns the unreferenced objects modified before cutoff and
+// how many unreferenced objects were too recent to touch.
+func selectOrphans(objects []storage.Object, referenced map[string]bool, cutoff time.Time) ([]storage.Object, int) {
+	var orphans []storage.Object
+	recent := 0
+	for _, object := range objects {
+		if referenced[object.Key] {
+			continue
+		}
+		if object.ModTime.After(cutoff) {
+			recent++
+			continue
+		}
+		orphans = append(orphans, object)
+	}
+	return orphans, recent
+}
+
+// referencedKeys collects every image key stored on a product, deleted or
+// not, or on a category.
+func referencedKeys(ctx context.Context, db *mongo.Database) (map[string]bool, error) {
+	cursor, err := db.Collection("products").Find(ctx,
+		bson.M{"$or": []bson.M{
+			{"imagePath": bson.M{"$nin": []interface{}{nil, ""}}},
+			{"images.0": bson.M{"$exists": true}},
+		}},
+		options.Find().SetProjection(bson.M{"imagePath": 1, "images": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := map[string]bool{}
+	for cursor.Next(ctx) {
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			return nil, err
+		}
+		addProductKeys(keys, product)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	categories, err := db.Collection("categories").Find(ctx,
+		bson.M{"image": bson.M{"$ne": nil}},
+		options.Find().SetProjection(bson.M{"image": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer categories.Close(ctx)
+
+	for categories.Next(ctx) {
+		var category models.Category
+		if err := categories.Decode(&category); err != nil {
+			return nil, err
+		}
+		addCategoryKeys(keys, category)
+	}
+	return keys, categories.Err()
+}
+
+func addProductKeys(keys map[string]bool, product models.Product) {
+	addKey(keys, product.ImagePath)
+	for _, image := range product.Images {
+		addImageKeys(keys, image)
+	}
+}
+
+func addCategoryKeys(keys map[string]bool, category models.Category) {
+	if category.Image != nil {
+		addImageKeys(keys, *category.Image)
+	}
+}
+
+func addImageKeys(keys map[string]bool, image models.ProductImage) {
+	addKey(keys, image.Thumbnail)
+	addKey(keys, image.Medium)
+	addKey(keys, image.Large)
+}
+
+func addKey(keys map[string]bool, raw string) {
+	if key, err := storage.CleanKey(raw); err == nil {
+		keys[key] = true
+	}
+}
+
+// Run collects every interval until ctx is done. Errors are logged; the next
+// run tries again.
+func Run(ctx context.Context, db *mongo.Database, uploads storage.Storage, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+		report, err := Collect(runCtx, db, uploads, Options{GracePeriod: grace})
+		cancel()
+		if err != nil {
+			log.Printf("[UPLOAD-GC] [ERROR] run failed: %v", err)
+			continue
+		}
+		log.Printf("[UPLOAD-GC] scanned=%d orphans=%d deleted=%d failed=%d tooRecent=%d freed=%dB",
+			report.Scanned, report.Orphans, report.Deleted, len(report.Failed), report.TooRecent, report.FreedBytes)
+	}
+}
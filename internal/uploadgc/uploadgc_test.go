@@ -0,0 +1,45 @@
+package uploadgc
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+func TestSelectOrphansSkipsReferencedAndRecentFiles(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	keys := map[string]bool{}
+	addProductKeys(keys, models.Product{
+		ImagePath: "/public/uploads/products/a-medium.jpg",
+		Images: []models.ProductImage{{
+			Thumbnail: "uploads/products/b-thumb.jpg",
+			Medium:    "uploads/products/b-medium.jpg",
+			Large:     "uploads/products/b-large.jpg",
+		}},
+	})
+	addCategoryKeys(keys, models.Category{Image: &models.ProductImage{
+		Thumbnail: "uploads/categories/e-thumb.jpg",
+		Medium:    "uploads/categories/e-medium.jpg",
+		Large:     "uploads/categories/e-large.jpg",
+	}})
+
+	old := now.Add(-48 * time.Hour)
+	objects := []storage.Object{
+		{Key: "uploads/products/a-medium.jpg", ModTime: old},
+		{Key: "uploads/products/b-large.jpg", ModTime: old},
+		{Key: "uploads/products/c-large.jpg", ModTime: old},
+		{Key: "uploads/products/d-large.jpg", ModTime: now.Add(-time.Hour)},
+		{Key: "uploads/categories/e-large.jpg", ModTime: old},
+		{Key: "uploads/categories/f-large.jpg", ModTime: old},
+	}
+
+	orphans, recent := selectOrphans(objects, keys, now.Add(-24*time.Hour))
+	if len(orphans) != 2 || orphans[0].Key != "uploads/products/c-large.jpg" || orphans[1].Key != "uploads/categories/f-large.jpg" {
+		t.Fatalf("expected only c-large.jpg and f-large.jpg, got %+v", orphans)
+	}
+	if recent != 1 {
+		t.Fatalf("expected one file inside the grace period, got %d", recent)
+	}
+}
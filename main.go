@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	_ "time/tzdata" // report timezones must resolve on minimal images
 
 	"github.com/gin-gonic/gin"
 
+	"backend/internal/alerts"
+	"backend/internal/auth"
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/handlers"
 	"backend/internal/middleware"
+	"backend/internal/migrations"
+	"backend/internal/recommend"
+	"backend/internal/search"
+	"backend/internal/sms"
+	"backend/internal/storage"
+	"backend/internal/uploadgc"
 )
 
 func main() {
@@ -25,15 +35,82 @@ func main() {
 
 	log.Println("MongoDB connected to:", db.Name())
 
+	if len(os.Args) > 1 {
+		if err := runCommand(db, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := database.EnsureProductIndexes(db); err != nil {
 		log.Printf("⚠️ product index warning: %v", err)
 	}
+	// Users saved before isActive existed would otherwise be refused at
+	// login as inactive.
+	if _, err := migrations.BackfillUserDefaults(context.Background(), db, false); err != nil {
+		log.Printf("⚠️ user defaults migration warning: %v", err)
+	}
 	if err := database.EnsureUserIndexes(db); err != nil {
 		log.Printf("⚠️ user index warning: %v", err)
 	}
 	if err := database.EnsureOrderIndexes(db); err != nil {
 		log.Printf("⚠️ order index warning: %v", err)
 	}
+	if err := database.EnsureOTPIndexes(db); err != nil {
+		log.Printf("⚠️ otp index warning: %v", err)
+	}
+	if err := database.EnsureStockMovementIndexes(db); err != nil {
+		log.Printf("⚠️ stock movement index warning: %v", err)
+	}
+	if err := database.EnsurePurchaseOrderIndexes(db); err != nil {
+		log.Printf("⚠️ purchase order index warning: %v", err)
+	}
+	if err := database.EnsureCategoryIndexes(db); err != nil {
+		log.Printf("⚠️ category index warning: %v", err)
+	}
+
+	tokens, err := auth.NewTokenService(auth.Options{
+		KeysDir:      config.AppEnv.JWTKeysDir,
+		ActiveKID:    config.AppEnv.JWTActiveKID,
+		Issuer:       config.AppEnv.JWTIssuer,
+		Audience:     config.AppEnv.JWTAudience,
+		LegacySecret: config.AppEnv.JWTSecret,
+		LegacyCutoff: config.AppEnv.JWTLegacyCutoff,
+		LegacyGrace:  config.AppEnv.RefreshTokenTTL,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("JWT signing key:", tokens.ActiveKeyID())
+
+	smsSender, err := sms.NewSender(config.AppEnv.SMSProvider, config.AppEnv.IsDevelopment())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stockAlerts, err := alerts.NewNotifier(config.AppEnv.AlertNotifier, config.AppEnv.AlertWebhookURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	uploads, err := storage.New(uploadStorageConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Upload storage:", config.AppEnv.StorageBackend)
+	if config.AppEnv.UploadGCEnabled {
+		go uploadgc.Run(context.Background(), db, uploads, config.AppEnv.UploadGCEvery, config.AppEnv.UploadGCGrace)
+	}
+
+	go recommend.Run(context.Background(), db, config.AppEnv.RelatedEvery, config.AppEnv.RelatedHalfLife)
+
+	productSearch := search.NewIndex(search.MongoLoader(db))
+	go func() {
+		if err := productSearch.Rebuild(context.Background()); err != nil {
+			log.Printf("⚠️ search index warning: %v", err)
+		}
+		productSearch.Run(context.Background(), config.AppEnv.SearchRefresh)
+	}()
 
 	r := gin.Default()
 	r.Use(func(c *gin.Context) {
@@ -53,47 +130,64 @@ func main() {
 		c.Next()
 	})
 	r.LoadHTMLGlob("templates/**/*")
-	r.Static("/public", "/app/public")
+	r.GET("/public/*filepath", handlers.ServePublic(config.AppEnv.StorageRoot, uploads))
+	r.HEAD("/public/*filepath", handlers.ServePublic(config.AppEnv.StorageRoot, uploads))
 
 	r.GET("/", handlers.Home())
+	r.GET("/.well-known/jwks.json", handlers.JWKS(tokens))
 	r.GET("/admin/login", handlers.AdminLoginPage)
 	r.GET("/admin/categories", handlers.AdminCategoriesPage)
 	r.GET("/admin/products", handlers.AdminProductsPage)
 	r.GET("/admin/orders", handlers.AdminOrdersPage)
 
-	r.POST("/auth/register", handlers.Register(db, config.AppEnv.JWTSecret, config.AppEnv.AccessTokenTTL))
+	r.POST("/auth/register", handlers.Register(db, tokens, config.AppEnv.AccessTokenTTL))
 	r.POST("/auth/login", handlers.Login(
 		db,
-		config.AppEnv.JWTSecret,
+		tokens,
 		config.AppEnv.AccessTokenTTL,
 		config.AppEnv.RefreshTokenTTL,
 	))
-	r.GET("/auth/me", middleware.UserAuth(config.AppEnv.JWTSecret), handlers.GetMe(db))
-	r.PUT("/auth/me", middleware.UserAuth(config.AppEnv.JWTSecret), handlers.UpdateMe(db))
+	r.GET("/auth/me", middleware.UserAuth(tokens, db), handlers.GetMe(db))
+	r.PUT("/auth/me", middleware.UserAuth(tokens, db), handlers.UpdateMe(db))
+	r.DELETE("/auth/me", middleware.UserAuth(tokens, db), handlers.DeleteMe(db))
 	r.POST("/auth/refresh", handlers.Refresh(
 		db,
-		config.AppEnv.JWTSecret,
+		tokens,
 		config.AppEnv.AccessTokenTTL,
 		config.AppEnv.RefreshTokenTTL,
 	))
 	r.POST("/auth/logout", handlers.Logout(db))
+	r.POST("/auth/otp/request", handlers.RequestOTP(db, smsSender))
+	r.POST("/auth/otp/verify", handlers.VerifyOTP(
+		db,
+		tokens,
+		config.AppEnv.AccessTokenTTL,
+		config.AppEnv.RefreshTokenTTL,
+	))
 
 	r.POST("/admin/login", handlers.AdminLogin(
 		db,
-		config.AppEnv.JWTSecret,
+		tokens,
 		config.AppEnv.AccessTokenTTL,
 		config.AppEnv.RefreshTokenTTL,
 	))
 
-	r.GET("/products", handlers.GetProducts(db))
+	r.GET("/products", handlers.GetProducts(db, productSearch))
+	r.GET("/products/suggest", handlers.SuggestProducts(productSearch))
 	r.GET("/categories", handlers.GetCategories(db))
+	r.GET("/categories/tree", handlers.GetCategoryTree(db))
 	r.GET("/products/campaign", handlers.GetCampaignProducts(db))
-	r.POST("/orders", handlers.CreateOrder(db, config.AppEnv.JWTSecret))
+	r.GET("/products/:id/related", handlers.GetRelatedProducts(db))
+	r.POST("/orders", handlers.CreateOrder(db, tokens, stockAlerts))
 
 	user := r.Group("/user")
-	user.Use(middleware.UserAuth(config.AppEnv.JWTSecret))
+	user.Use(middleware.UserAuth(tokens, db))
 	{
 		user.GET("/orders", handlers.GetMyOrders(db))
+		user.GET("/orders/:id/receipt.pdf", handlers.UserOrderReceipt(db))
+		user.POST("/orders/:id/reorder", handlers.ReorderMyOrder(db))
+		user.GET("/data-export", handlers.ExportMyData(db))
+		user.GET("/cart/recommendations", handlers.GetCartRecommendations(db))
 
 		user.GET("/addresses", handlers.GetUserAddresses(db))
 		user.POST("/addresses", handlers.CreateUserAddress(db))
@@ -106,28 +200,65 @@ func main() {
 	}
 
 	admin := r.Group("/admin/api")
-	admin.Use(middleware.AdminAuth(config.AppEnv.JWTSecret))
+	admin.Use(middleware.AdminAuth(tokens))
 	{
 		admin.GET("/me", func(c *gin.Context) {
 			c.JSON(200, gin.H{"ok": true})
 		})
 
 		admin.GET("/products", handlers.GetAllProducts(db))
+		admin.GET("/products/low-stock", handlers.GetLowStockProducts(db))
 		admin.GET("/products/:id", handlers.GetProductByID(db))
-		admin.POST("/products", handlers.CreateProduct(db))
-		admin.PUT("/products/:id", handlers.UpdateProduct(db))
-		admin.DELETE("/products/:id", handlers.DeleteProduct(db))
+		admin.POST("/products", handlers.CreateProduct(db, uploads, productSearch))
+		admin.POST("/products/import", handlers.ImportProducts(db, productSearch))
+		admin.GET("/products/export", handlers.ExportProducts(db))
+		admin.PUT("/products/:id", handlers.UpdateProduct(db, uploads, productSearch))
+		admin.DELETE("/products/:id", handlers.DeleteProduct(db, uploads, productSearch))
+		admin.GET("/products/:id/stock-movements", handlers.GetStockMovements(db))
+		admin.POST("/products/:id/stock-movements", handlers.CreateStockAdjustment(db))
+		admin.GET("/products/:id/variants", handlers.GetProductVariants(db))
+		admin.POST("/products/:id/variants", handlers.CreateProductVariant(db, productSearch))
+		admin.DELETE("/products/:id/variants/:variantId", handlers.DeleteProductVariant(db, productSearch))
+		admin.POST("/products/:id/images", handlers.AddProductImages(db, uploads))
+		admin.PUT("/products/:id/images/order", handlers.ReorderProductImages(db))
+		admin.DELETE("/products/:id/images/:imageId", handlers.DeleteProductImage(db, uploads))
 
 		admin.GET("/categories", handlers.GetAllCategories(db))
 		admin.POST("/categories", handlers.CreateCategory(db))
-		admin.PUT("/categories/:id", handlers.UpdateCategory(db))
-		admin.DELETE("/categories/:id", handlers.DeleteCategory(db))
+		admin.PUT("/categories/:id", handlers.UpdateCategory(db, productSearch))
+		admin.DELETE("/categories/:id", handlers.DeleteCategory(db, uploads, productSearch))
+		admin.PUT("/categories/:id/image", handlers.SetCategoryImage(db, uploads))
+		admin.DELETE("/categories/:id/image", handlers.DeleteCategoryImage(db, uploads))
+
+		admin.GET("/suppliers", handlers.GetSuppliers(db))
+		admin.GET("/suppliers/:id", handlers.GetSupplierByID(db))
+		admin.POST("/suppliers", handlers.CreateSupplier(db))
+		admin.PUT("/suppliers/:id", handlers.UpdateSupplier(db))
+		admin.DELETE("/suppliers/:id", handlers.DeleteSupplier(db))
+
+		admin.GET("/purchase-orders", handlers.GetPurchaseOrders(db))
+		admin.GET("/purchase-orders/:id", handlers.GetPurchaseOrderByID(db))
+		admin.POST("/purchase-orders", handlers.CreatePurchaseOrder(db))
+		admin.PUT("/purchase-orders/:id", handlers.UpdatePurchaseOrder(db))
+		admin.DELETE("/purchase-orders/:id", handlers.DeletePurchaseOrder(db))
+		admin.POST("/purchase-orders/:id/receive", handlers.ReceivePurchaseOrder(db))
 
 		admin.GET("/orders", handlers.AdminGetOrders(db))
+		admin.GET("/orders/export", handlers.AdminExportOrders(db))
+		admin.GET("/orders/picking-list", handlers.AdminPickingList(db))
 		admin.GET("/orders/:id", handlers.AdminGetOrderByID(db))
+		admin.GET("/orders/:id/receipt.pdf", handlers.AdminOrderReceipt(db))
+		admin.GET("/orders/:id/slip", handlers.AdminOrderSlip(db))
 		admin.PUT("/orders/:id/status", handlers.AdminUpdateOrderStatus(db))
 
 		admin.DELETE("/orders/:id", handlers.DeleteOrder(db))
+
+		admin.GET("/reports/sales", handlers.AdminSalesReport(db))
+		admin.GET("/reports/top-products", handlers.AdminTopProductsReport(db))
+		admin.GET("/reports/breakdown", handlers.AdminBreakdownReport(db))
+
+		admin.GET("/settings/store", handlers.GetStoreSettings(db))
+		admin.PUT("/settings/store", handlers.UpdateStoreSettings(db))
 	}
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -135,3 +266,20 @@ func main() {
 	}
 	r.Run(":" + port)
 }
+
+func uploadStorageConfig() storage.Config {
+	return storage.Config{
+		Backend:        config.AppEnv.StorageBackend,
+		LocalRoot:      config.AppEnv.StorageRoot,
+		LocalURLPrefix: "/public",
+		S3: storage.S3Config{
+			Endpoint:  config.AppEnv.S3Endpoint,
+			Region:    config.AppEnv.S3Region,
+			Bucket:    config.AppEnv.S3Bucket,
+			AccessKey: config.AppEnv.S3AccessKey,
+			SecretKey: config.AppEnv.S3SecretKey,
+			PublicURL: config.AppEnv.S3PublicURL,
+			PathStyle: config.AppEnv.S3PathStyle,
+		},
+	}
+}
@@ -122,13 +122,25 @@ function orderActions(orderId, currentStatus) {
   saveStatusButton.textContent = "Durum Değiştir";
   saveStatusButton.addEventListener("click", () => updateOrderStatus(orderId, statusSelect.value));
 
+  const slipButton = document.createElement("button");
+  slipButton.type = "button";
+  slipButton.className = "small ghost";
+  slipButton.textContent = "Termal Fiş";
+  slipButton.addEventListener("click", () => downloadSlip(orderId));
+
+  const receiptButton = document.createElement("button");
+  receiptButton.type = "button";
+  receiptButton.className = "small ghost";
+  receiptButton.textContent = "PDF";
+  receiptButton.addEventListener("click", () => openReceipt(orderId));
+
   const deleteButton = document.createElement("button");
   deleteButton.type = "button";
   deleteButton.className = "small danger";
   deleteButton.textContent = "Sil";
   deleteButton.addEventListener("click", () => deleteOrder(orderId));
 
-  wrap.append(detailButton, statusSelect, saveStatusButton, deleteButton);
+  wrap.append(detailButton, statusSelect, saveStatusButton, slipButton, receiptButton, deleteButton);
   return wrap;
 }
 
@@ -355,6 +367,95 @@ async function deleteOrder(orderId) {
   await fetchOrders(true);
 }
 
+async function fetchBlob(url) {
+  const res = await fetch(url, { headers: authHeaders() });
+  if (handleUnauthorized(res)) return null;
+  if (!res.ok) {
+    setText("ordersStatus", "Dosya alınamadı. Lütfen tekrar deneyin.");
+    return null;
+  }
+  return res.blob();
+}
+
+// ESC/POS dosyası, yazıcıya ham olarak gönderilmek üzere indirilir
+// (ör. yazdırma ajanı veya `copy /b fis.bin \\pc\yazici`).
+async function downloadSlip(orderId) {
+  if (!orderId) return;
+  const width = document.getElementById("slipWidthFilter")?.value || "80";
+  const blob = await fetchBlob(`${ORDERS_API_URL}/${orderId}/slip?format=escpos&width=${width}`);
+  if (!blob) return;
+
+  const link = document.createElement("a");
+  link.href = URL.createObjectURL(blob);
+  link.download = `fis-${orderId}.bin`;
+  link.click();
+  URL.revokeObjectURL(link.href);
+}
+
+async function openReceipt(orderId) {
+  if (!orderId) return;
+  const blob = await fetchBlob(`${ORDERS_API_URL}/${orderId}/receipt.pdf`);
+  if (!blob) return;
+  window.open(URL.createObjectURL(blob), "_blank");
+}
+
+function renderPickingList(list) {
+  const groups = Array.isArray(list?.groups) ? list.groups : [];
+  if (groups.length === 0) {
+    return '<p class="muted">Onaylı sipariş yok.</p>';
+  }
+
+  const sections = groups.map((group) => {
+    const rows = (group.items || []).map((item) => {
+      const orders = (item.orders || []).map((ref) => `${ref.orderCode} (${ref.quantity})`).join(", ");
+      return `<tr><td>${item.name || "-"}</td><td>${item.barcode || "-"}</td><td>${item.quantity}</td><td>${orders}</td></tr>`;
+    }).join("");
+    return `
+      <h4>${group.category} – ${group.quantity} adet</h4>
+      <table class="hm-table">
+        <thead><tr><th>Ürün</th><th>Barkod</th><th>Adet</th><th>Siparişler</th></tr></thead>
+        <tbody>${rows}</tbody>
+      </table>
+    `;
+  }).join("");
+
+  return `
+    <p><strong>Sipariş:</strong> ${list.orderCount} • <strong>Toplam adet:</strong> ${list.quantity}</p>
+    ${sections}
+  `;
+}
+
+async function openPickingList() {
+  const dialog = document.getElementById("pickingListDialog");
+  const content = document.getElementById("pickingListContent");
+  if (!dialog || !content) return;
+
+  const res = await fetch(`${ORDERS_API_URL}/picking-list`, { headers: authHeaders() });
+  if (handleUnauthorized(res)) return;
+  const payload = await safeJson(res);
+  if (!res.ok) {
+    setText("ordersStatus", "Toplama listesi alınamadı. Lütfen tekrar deneyin.");
+    return;
+  }
+
+  content.innerHTML = renderPickingList(payload);
+  state.detailOpen = true;
+  dialog.showModal();
+}
+
+function printPickingList() {
+  const content = document.getElementById("pickingListContent");
+  if (!content) return;
+  const win = window.open("", "_blank");
+  if (!win) return;
+  win.document.write(`<!doctype html><html lang="tr"><head><meta charset="UTF-8"><title>Toplama Listesi</title>
+    <style>body{font-family:sans-serif;font-size:12px}table{width:100%;border-collapse:collapse;margin-bottom:12px}td,th{border:1px solid #999;padding:4px;text-align:left}</style>
+    </head><body>${content.innerHTML}</body></html>`);
+  win.document.close();
+  win.focus();
+  win.print();
+}
+
 function readFiltersFromUI() {
   state.filters.status = document.getElementById("statusFilter")?.value || "";
   state.filters.paymentMethod = document.getElementById("paymentFilter")?.value || "";
@@ -414,6 +515,14 @@ function bindEvents() {
   });
 
   document.getElementById("closeDetailButton")?.addEventListener("click", closeDetail);
+  document.getElementById("pickingListButton")?.addEventListener("click", openPickingList);
+  document.getElementById("printPickingListButton")?.addEventListener("click", printPickingList);
+  document.getElementById("closePickingListButton")?.addEventListener("click", () => {
+    document.getElementById("pickingListDialog")?.close();
+  });
+  document.getElementById("pickingListDialog")?.addEventListener("close", () => {
+    state.detailOpen = false;
+  });
   document.getElementById("orderDetailDialog")?.addEventListener("close", () => {
     state.detailOpen = false;
   });
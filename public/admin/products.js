@@ -345,7 +345,7 @@ function renderProductsTable(data) {
       <td>${p.name || "-"}</td>
       <td>${p.brand || "-"}</td>
       <td>${p.barcode || "-"}</td>
-      <td>${Number.isFinite(Number(p.stock)) ? Number(p.stock) : "-"}</td>
+      <td>${Number.isFinite(Number(p.stock)) ? Number(p.stock) + (p.unit === "kg" ? " kg" : "") : "-"}</td>
       <td>${parseBooleanValue(p.isCampaign) ? "Evet" : "Hayır"}</td>
       <td><button type="button" class="small" data-product-id="${productId || ""}">Düzenle</button></td>
     `;
@@ -449,7 +449,11 @@ function resetEditFormState() {
   form.elements.salePrice.value = ""; // ✅ 0 değil
   form.elements.brand.value = "";
   form.elements.barcode.value = "";
+  form.elements.unit.value = "piece";
+  form.elements.variantLabel.value = "";
   form.elements.stock.value = "";
+  form.elements.reorderLevel.value = "";
+  form.elements.costPrice.value = "";
   form.elements.description.value = "";
   form.elements.isActive.checked = false;
   form.elements.isCampaign.checked = false;
@@ -484,7 +488,11 @@ function mapProductToEditForm(form, product) {
   form.elements.price.value = Number.isFinite(safePrice) ? String(safePrice) : "";
   form.elements.brand.value = String(product?.brand || "");
   form.elements.barcode.value = String(product?.barcode || "");
+  form.elements.unit.value = product?.unit === "kg" ? "kg" : "piece";
+  form.elements.variantLabel.value = String(product?.variantLabel || "");
   form.elements.stock.value = Number.isFinite(Number(product?.stock)) ? String(Number(product.stock)) : "";
+  form.elements.reorderLevel.value = Number(product?.reorderLevel) > 0 ? String(Number(product.reorderLevel)) : "";
+  form.elements.costPrice.value = Number(product?.costPrice) > 0 ? String(Number(product.costPrice)) : "";
   form.elements.description.value = String(product?.description || "");
   form.elements.isActive.checked = parseBooleanValue(product?.isActive);
   form.elements.isCampaign.checked = parseBooleanValue(product?.isCampaign);
@@ -728,8 +736,13 @@ el("editProduct")?.addEventListener("submit", async function (event) {
   fd.set("brand", formEl.elements.brand.value || "");
   fd.set("barcode", formEl.elements.barcode.value || "");
 
+  fd.set("unit", formEl.elements.unit.value || "piece");
+  fd.set("variantLabel", formEl.elements.variantLabel.value || "");
+
   const stockValue = formEl.elements.stock.value || "";
   fd.set("stock", stockValue);
+  fd.set("reorderLevel", formEl.elements.reorderLevel.value || "");
+  fd.set("costPrice", formEl.elements.costPrice.value || "");
 
   fd.set("description", formEl.elements.description.value || "");
 
@@ -772,6 +785,8 @@ el("editProduct")?.addEventListener("submit", async function (event) {
   if (imageInput && imageInput.files && imageInput.files.length > 0) {
     fd.append("image", imageInput.files[0]);
   }
+  const galleryInput = formEl.querySelector('input[name="images"]');
+  Array.from(galleryInput?.files || []).forEach(file => fd.append("images", file));
 
   const res = await fetch("/admin/api/products/" + id, {
     method: "PUT",